@@ -0,0 +1,56 @@
+package dbkit
+
+import (
+	"fmt"
+)
+
+// BatchInsertChunkedCommit inserts records in batches of batchSize and commits every commitEvery batches
+// in its own transaction, so multi-million-row imports do not build one huge transaction.
+// 牺牲整体原子性换取稳定性：失败时之前的分段已提交，只回滚当前分段。返回已提交的行数，
+// 即已提交的记录数 n，失败后可从 records[n:] 继续导入。batchSize <= 0 时使用 DefaultBatchSize，commitEvery <= 0 时按 1 处理
+func BatchInsertChunkedCommit(table string, records []*Record, batchSize, commitEvery int) (int64, error) {
+	db, err := defaultDB()
+	if err != nil {
+		return 0, err
+	}
+	return db.BatchInsertChunkedCommit(table, records, batchSize, commitEvery)
+}
+
+// BatchInsertChunkedCommit inserts records in batches of batchSize and commits every commitEvery batches
+func (db *DB) BatchInsertChunkedCommit(table string, records []*Record, batchSize, commitEvery int) (int64, error) {
+	if db.lastErr != nil {
+		return 0, db.lastErr
+	}
+	if err := validateIdentifier(table); err != nil {
+		return 0, err
+	}
+	if len(records) == 0 {
+		return 0, fmt.Errorf("no records to insert")
+	}
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	if commitEvery <= 0 {
+		commitEvery = 1
+	}
+
+	chunkSize := batchSize * commitEvery
+	var committed int64
+	for start := 0; start < len(records); start += chunkSize {
+		end := start + chunkSize
+		if end > len(records) {
+			end = len(records)
+		}
+		var affected int64
+		err := db.Transaction(func(tx *Tx) error {
+			var err error
+			affected, err = tx.BatchInsert(table, records[start:end], batchSize)
+			return err
+		})
+		if err != nil {
+			return committed, fmt.Errorf("dbkit: chunked commit of records[%d:%d] failed, %d rows committed before it: %w", start, end, committed, err)
+		}
+		committed += affected
+	}
+	return committed, nil
+}
@@ -0,0 +1,385 @@
+package dbkit
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// decimalPattern 匹配十进制数字字符串
+var decimalPattern = regexp.MustCompile(`^[-+]?[0-9]+\.[0-9]+$`)
+
+// BatchUpsertReturning inserts or updates records by conflictColumns and returns the resulting rows
+func BatchUpsertReturning(table string, records []*Record, conflictColumns, returningColumns []string, opts ...UpsertOptions) ([]*Record, error) {
+	db, err := defaultDB()
+	if err != nil {
+		return nil, err
+	}
+	return db.BatchUpsertReturning(table, records, conflictColumns, returningColumns, opts...)
+}
+
+// BatchUpsertReturning inserts or updates records by conflictColumns and returns the resulting rows,
+// one per input record in the same order, so server-generated IDs and versions can be read back.
+// PostgreSQL/SQLite 3.35+ 使用 INSERT ... ON CONFLICT ... RETURNING，SQL Server 使用 MERGE ... OUTPUT；
+// MySQL 使用 ON DUPLICATE KEY UPDATE（按表上任一唯一键判断冲突），Oracle 使用 MERGE，二者执行后在同一事务中按 conflictColumns 查询。
+// 所有记录的列必须相同且包含非 NULL 的 conflictColumns，conflictColumns 重复时返回错误；
+// 返回的记录总是包含 conflictColumns，returningColumns 为空时返回所有列。每条语句最多 DefaultBatchSize 行，整体在一个事务中执行。
+// 部分唯一索引（如 UNIQUE ... WHERE deleted_at IS NULL）通过 UpsertOptions.ConflictTarget 指定冲突目标
+func (db *DB) BatchUpsertReturning(table string, records []*Record, conflictColumns, returningColumns []string, opts ...UpsertOptions) ([]*Record, error) {
+	if db.lastErr != nil {
+		return nil, db.lastErr
+	}
+	ctx, cancel := db.getContext()
+	defer cancel()
+	sdb, err := db.dbMgr.getDB()
+	if err != nil {
+		return nil, err
+	}
+	return db.dbMgr.batchUpsertReturning(ctx, db.executor(sdb), table, records, conflictColumns, returningColumns, upsertOptions(opts))
+}
+
+// BatchUpsertReturning inserts or updates records within transaction and returns the resulting rows
+func (tx *Tx) BatchUpsertReturning(table string, records []*Record, conflictColumns, returningColumns []string, opts ...UpsertOptions) ([]*Record, error) {
+	ctx, cancel := tx.getContext()
+	defer cancel()
+	return tx.dbMgr.batchUpsertReturning(ctx, tx.executor(), table, records, conflictColumns, returningColumns, upsertOptions(opts))
+}
+
+func (mgr *dbManager) batchUpsertReturning(ctx context.Context, executor sqlExecutor, table string, records []*Record, conflictColumns, returningColumns []string, opts UpsertOptions) (result []*Record, err error) {
+	defer mgr.invalidateNegativeCacheOn(executor, table)
+	if err := validateIdentifier(table); err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("no records to upsert")
+	}
+	if len(conflictColumns) == 0 {
+		return nil, fmt.Errorf("dbkit: BatchUpsertReturning requires conflict columns")
+	}
+	for _, col := range append(append([]string(nil), conflictColumns...), returningColumns...) {
+		if err := validateIdentifier(col); err != nil {
+			return nil, err
+		}
+	}
+	if opts.ConflictTarget != "" && mgr.config.Driver != PostgreSQL && mgr.config.Driver != SQLite3 {
+		return nil, fmt.Errorf("dbkit: UpsertOptions.ConflictTarget is only supported on PostgreSQL and SQLite, not %s", mgr.config.Driver)
+	}
+	conflictTarget := opts.conflictTarget(conflictColumns)
+
+	if err := mgr.applyTenant(executor, table, records...); err != nil {
+		return nil, err
+	}
+	for _, record := range records {
+		if record != nil {
+			mgr.applyVersionInit(table, record)
+		}
+	}
+	records, err = mgr.coerceRecords(table, records)
+	if err != nil {
+		return nil, err
+	}
+
+	// 校验列集合与冲突键
+	var columns []string
+	keys := make([]string, len(records))
+	seen := make(map[string]bool, len(records))
+	for i, record := range records {
+		if record == nil || len(record.columns) == 0 {
+			return nil, fmt.Errorf("dbkit: record at index %d is empty", i)
+		}
+		cols := record.Keys()
+		sort.Strings(cols)
+		if i == 0 {
+			columns = cols
+		} else if strings.Join(cols, "\x00") != strings.Join(columns, "\x00") {
+			return nil, fmt.Errorf("dbkit: record at index %d has different columns, BatchUpsertReturning requires the same columns in every record", i)
+		}
+		key, err := upsertKey(record, conflictColumns)
+		if err != nil {
+			return nil, fmt.Errorf("dbkit: record at index %d: %v", i, err)
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("dbkit: record at index %d duplicates conflict key %v", i, upsertKeyArgs(record, conflictColumns))
+		}
+		seen[key] = true
+		keys[i] = key
+	}
+
+	returnCols := returningColumns
+	if len(returnCols) > 0 {
+		returnCols = append([]string(nil), returningColumns...)
+		for _, col := range conflictColumns {
+			if !containsFold(returnCols, col) {
+				returnCols = append(returnCols, col)
+			}
+		}
+	}
+
+	// 主键不随冲突行更新，MERGE 还需跳过自增列；在开启内部事务前查询，避免连接池只有一个连接时等待
+	pks, err := mgr.getPrimaryKeys(executor, table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get primary keys: %v", err)
+	}
+	var identityCol string
+	if driver := mgr.config.Driver; driver == SQLServer || driver == Oracle {
+		identityCol = mgr.getIdentityColumn(executor, table)
+	}
+
+	if sdb, ok := unwrapExecutor(executor).(*sql.DB); ok {
+		tx, beginErr := sdb.BeginTx(ctx, nil)
+		if beginErr != nil {
+			return nil, beginErr
+		}
+		defer func() {
+			if err != nil {
+				tx.Rollback()
+				return
+			}
+			err = tx.Commit()
+		}()
+		executor = rewrapExecutor(executor, tx)
+	}
+
+	chunkSize := DefaultBatchSize
+	if limit := mgr.paramLimit(); limit > 0 && limit/len(columns) < chunkSize {
+		chunkSize = limit / len(columns)
+		if chunkSize < 1 {
+			chunkSize = 1
+		}
+	}
+
+	// 返回行按规范化后的冲突键匹配；不区分大小写或补齐空格的排序规则下，数据库返回的是已有行的值，再按折叠后的键匹配
+	rowsByKey := make(map[string]*Record, len(records))
+	rowsByFoldedKey := make(map[string]*Record, len(records))
+	for start := 0; start < len(records); start += chunkSize {
+		end := start + chunkSize
+		if end > len(records) {
+			end = len(records)
+		}
+		rows, err := mgr.upsertChunkReturning(ctx, executor, table, records[start:end], columns, conflictColumns, conflictTarget, returnCols, pks, identityCol)
+		if err != nil {
+			return nil, err
+		}
+		for i := range rows {
+			key, err := upsertKey(&rows[i], conflictColumns)
+			if err != nil {
+				return nil, fmt.Errorf("dbkit: returned row: %v", err)
+			}
+			rowsByKey[key] = &rows[i]
+			rowsByFoldedKey[foldUpsertKey(key)] = &rows[i]
+		}
+	}
+
+	result = make([]*Record, len(records))
+	for i, key := range keys {
+		row, ok := rowsByKey[key]
+		if !ok {
+			row, ok = rowsByFoldedKey[foldUpsertKey(key)]
+		}
+		if !ok {
+			return nil, fmt.Errorf("dbkit: no row returned for conflict key %v", upsertKeyArgs(records[i], conflictColumns))
+		}
+		result[i] = row
+	}
+	return result, nil
+}
+
+// upsertChunkReturning 执行一条多行 upsert 并返回受影响的行，conflictTarget 只用于 PostgreSQL / SQLite 的 ON CONFLICT；
+// 冲突列、主键与自增列不出现在更新部分
+func (mgr *dbManager) upsertChunkReturning(ctx context.Context, executor sqlExecutor, table string, chunk []*Record, columns, conflictColumns []string, conflictTarget string, returnCols, pks []string, identityCol string) ([]Record, error) {
+	driver := mgr.config.Driver
+	var values []interface{}
+	for _, record := range chunk {
+		for _, col := range columns {
+			values = append(values, record.Get(col))
+		}
+	}
+
+	var updateCols []string
+	for _, col := range columns {
+		if containsFold(conflictColumns, col) || containsFold(pks, col) || strings.EqualFold(col, identityCol) {
+			continue
+		}
+		updateCols = append(updateCols, col)
+	}
+	if len(updateCols) == 0 {
+		// 没有可更新的列时把一个冲突列赋值为自身，使已存在的行也被返回；SQL Server 不允许更新自增列
+		for _, col := range append(append([]string(nil), conflictColumns...), columns...) {
+			if !strings.EqualFold(col, identityCol) {
+				updateCols = []string{col}
+				break
+			}
+		}
+	}
+
+	rowPlaceholder := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ") + ")"
+	rowPlaceholders := make([]string, len(chunk))
+	for i := range rowPlaceholders {
+		rowPlaceholders[i] = rowPlaceholder
+	}
+
+	switch driver {
+	case PostgreSQL, SQLite3:
+		sets := make([]string, len(updateCols))
+		for i, col := range updateCols {
+			sets[i] = fmt.Sprintf("%s = EXCLUDED.%s", col, col)
+		}
+		querySQL := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s ON CONFLICT %s DO UPDATE SET %s RETURNING %s",
+			table, joinStrings(columns), strings.Join(rowPlaceholders, ", "), conflictTarget,
+			strings.Join(sets, ", "), returningList(returnCols))
+		return mgr.queryWithContext(ctx, executor, querySQL, values...)
+
+	case MySQL:
+		sets := make([]string, len(updateCols))
+		for i, col := range updateCols {
+			sets[i] = fmt.Sprintf("%s = VALUES(%s)", col, col)
+		}
+		querySQL := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s ON DUPLICATE KEY UPDATE %s",
+			table, joinStrings(columns), strings.Join(rowPlaceholders, ", "), strings.Join(sets, ", "))
+		if _, err := mgr.execWithContext(ctx, executor, querySQL, values...); err != nil {
+			return nil, err
+		}
+		return mgr.selectByConflictKeys(ctx, executor, table, chunk, conflictColumns, returnCols)
+	}
+
+	// SQL Server / Oracle: MERGE
+	var usingSQL string
+	if driver == SQLServer {
+		usingSQL = fmt.Sprintf("(VALUES %s) AS s (%s)", strings.Join(rowPlaceholders, ", "), joinStrings(columns))
+	} else {
+		selectCols := make([]string, len(columns))
+		for i, col := range columns {
+			selectCols[i] = "? AS " + col
+		}
+		rowSelect := "SELECT " + strings.Join(selectCols, ", ") + " FROM DUAL"
+		rows := make([]string, len(chunk))
+		for i := range rows {
+			rows[i] = rowSelect
+		}
+		usingSQL = "(" + strings.Join(rows, " UNION ALL ") + ") s"
+	}
+	onClauses := make([]string, len(conflictColumns))
+	for i, col := range conflictColumns {
+		onClauses[i] = fmt.Sprintf("t.%s = s.%s", col, col)
+	}
+	sets := make([]string, len(updateCols))
+	for i, col := range updateCols {
+		sets[i] = fmt.Sprintf("t.%s = s.%s", col, col)
+	}
+	var insertCols, insertVals []string
+	for _, col := range columns {
+		if identityCol != "" && strings.EqualFold(col, identityCol) {
+			continue
+		}
+		insertCols = append(insertCols, col)
+		insertVals = append(insertVals, "s."+col)
+	}
+	querySQL := fmt.Sprintf("MERGE INTO %s t USING %s ON (%s) WHEN MATCHED THEN UPDATE SET %s WHEN NOT MATCHED THEN INSERT (%s) VALUES (%s)",
+		table, usingSQL, strings.Join(onClauses, " AND "), strings.Join(sets, ", "),
+		strings.Join(insertCols, ", "), strings.Join(insertVals, ", "))
+
+	if driver == SQLServer {
+		outputCols := []string{"INSERTED.*"}
+		if len(returnCols) > 0 {
+			outputCols = make([]string, len(returnCols))
+			for i, col := range returnCols {
+				outputCols[i] = "INSERTED." + col
+			}
+		}
+		querySQL += " OUTPUT " + joinStrings(outputCols) + ";"
+		return mgr.queryWithContext(ctx, executor, querySQL, values...)
+	}
+	if _, err := mgr.execWithContext(ctx, executor, querySQL, values...); err != nil {
+		return nil, err
+	}
+	return mgr.selectByConflictKeys(ctx, executor, table, chunk, conflictColumns, returnCols)
+}
+
+// selectByConflictKeys 为不支持 RETURNING 的数据库按冲突键查询 upsert 后的行
+func (mgr *dbManager) selectByConflictKeys(ctx context.Context, executor sqlExecutor, table string, chunk []*Record, conflictColumns, returnCols []string) ([]Record, error) {
+	conditions := make([]string, len(chunk))
+	var args []interface{}
+	for i, record := range chunk {
+		parts := make([]string, len(conflictColumns))
+		for j, col := range conflictColumns {
+			parts[j] = col + " = ?"
+			args = append(args, record.Get(col))
+		}
+		conditions[i] = "(" + strings.Join(parts, " AND ") + ")"
+	}
+	selectSQL := fmt.Sprintf("SELECT %s FROM %s WHERE %s", returningList(returnCols), table, strings.Join(conditions, " OR "))
+	return mgr.queryWithContext(ctx, executor, selectSQL, args...)
+}
+
+// upsertKey 把冲突列的值拼接为匹配返回行用的字符串，各值先按 upsertKeyValue 规范化
+func upsertKey(record *Record, conflictColumns []string) (string, error) {
+	parts := make([]string, len(conflictColumns))
+	for i, col := range conflictColumns {
+		v := normalizeArg(record.Get(col))
+		if v == nil {
+			return "", fmt.Errorf("conflict column %s is missing or NULL", col)
+		}
+		parts[i] = upsertKeyValue(v)
+	}
+	return strings.Join(parts, "\x00"), nil
+}
+
+// upsertKeyValue 规范化冲突键的值，使记录中的值与驱动返回的值可以比较：
+// 整数与整数值的浮点数统一为十进制整数，十进制字符串（DECIMAL 常以 []byte 返回）去掉小数部分末尾的 0，
+// 布尔值为 1 / 0，时间统一为 UTC
+func upsertKeyValue(v interface{}) string {
+	switch val := v.(type) {
+	case []byte:
+		return trimDecimalZeros(string(val))
+	case string:
+		return trimDecimalZeros(val)
+	case bool:
+		if val {
+			return "1"
+		}
+		return "0"
+	case time.Time:
+		return val.UTC().Format(time.RFC3339Nano)
+	case float32, float64:
+		f, _ := toFloat64(val)
+		if f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+			return strconv.FormatInt(int64(f), 10)
+		}
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(val)
+	}
+	return relationKey(v)
+}
+
+// trimDecimalZeros 去掉十进制数字字符串小数部分末尾的 0（"1.50" -> "1.5"，"2.00" -> "2"），其他字符串原样返回
+func trimDecimalZeros(s string) string {
+	if !decimalPattern.MatchString(s) {
+		return s
+	}
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// foldUpsertKey 为不区分大小写、忽略尾部空格的排序规则折叠冲突键
+func foldUpsertKey(key string) string {
+	parts := strings.Split(key, "\x00")
+	for i, part := range parts {
+		parts[i] = strings.ToLower(strings.TrimRight(part, " "))
+	}
+	return strings.Join(parts, "\x00")
+}
+
+func upsertKeyArgs(record *Record, conflictColumns []string) []interface{} {
+	args := make([]interface{}, len(conflictColumns))
+	for i, col := range conflictColumns {
+		args[i] = record.Get(col)
+	}
+	return args
+}
@@ -0,0 +1,155 @@
+package dbkit
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrInserterClosed is returned by BufferedInserter.Add after Close has been called
+var ErrInserterClosed = errors.New("dbkit: buffered inserter is closed")
+
+// BufferedInserter accumulates single-row inserts and writes them with BatchInsert
+// once flushCount records are buffered or flushInterval has elapsed.
+// 适用于日志、指标等高吞吐写入场景，可被多个 goroutine 并发调用 Add
+type BufferedInserter struct {
+	db            *DB
+	table         string
+	flushCount    int
+	flushInterval time.Duration
+
+	mu      sync.Mutex
+	buf     []*Record
+	closed  bool
+	onError func(records []*Record, err error)
+
+	flushMu sync.Mutex // 串行化批量写入，保证按 Add 的顺序落库
+	done    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// NewBufferedInserter creates a buffered inserter on the default database.
+// flushCount <= 0 时使用 DefaultBatchSize；flushInterval <= 0 表示只按数量触发
+func NewBufferedInserter(table string, flushCount int, flushInterval time.Duration) *BufferedInserter {
+	db, err := defaultDB()
+	if err != nil {
+		db = &DB{lastErr: err}
+	}
+	return db.NewBufferedInserter(table, flushCount, flushInterval)
+}
+
+// NewBufferedInserter creates a buffered inserter on this database
+func (db *DB) NewBufferedInserter(table string, flushCount int, flushInterval time.Duration) *BufferedInserter {
+	if flushCount <= 0 {
+		flushCount = DefaultBatchSize
+	}
+	b := &BufferedInserter{
+		db:            db,
+		table:         table,
+		flushCount:    flushCount,
+		flushInterval: flushInterval,
+		buf:           make([]*Record, 0, flushCount),
+		done:          make(chan struct{}),
+	}
+	if flushInterval > 0 {
+		b.wg.Add(1)
+		go b.loop()
+	}
+	return b
+}
+
+// OnError sets the callback invoked when a flush fails; records is the batch that was not written.
+// 未设置时失败会记录到日志
+func (b *BufferedInserter) OnError(fn func(records []*Record, err error)) *BufferedInserter {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.onError = fn
+	return b
+}
+
+// Add buffers a record; when the buffer reaches flushCount it is flushed in the calling goroutine
+func (b *BufferedInserter) Add(record *Record) error {
+	if record == nil {
+		return nil
+	}
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return ErrInserterClosed
+	}
+	b.buf = append(b.buf, record)
+	if len(b.buf) < b.flushCount {
+		b.mu.Unlock()
+		return nil
+	}
+	b.mu.Unlock()
+	// 写入失败通过 OnError 回调上报，不影响 Add 的返回值
+	b.Flush()
+	return nil
+}
+
+// Flush writes all buffered records immediately
+func (b *BufferedInserter) Flush() error {
+	b.flushMu.Lock()
+	defer b.flushMu.Unlock()
+
+	b.mu.Lock()
+	if len(b.buf) == 0 {
+		b.mu.Unlock()
+		return nil
+	}
+	batch := b.buf
+	b.buf = make([]*Record, 0, b.flushCount)
+	onError := b.onError
+	b.mu.Unlock()
+
+	if _, err := b.db.BatchInsert(b.table, batch, b.flushCount); err != nil {
+		if onError != nil {
+			onError(batch, err)
+		} else {
+			LogError("缓冲批量插入失败", map[string]interface{}{
+				"table":   b.table,
+				"records": len(batch),
+				"error":   err.Error(),
+			})
+		}
+		return err
+	}
+	return nil
+}
+
+// Close stops the flush timer and writes any remaining records; Add fails after Close
+func (b *BufferedInserter) Close() error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	b.mu.Unlock()
+
+	close(b.done)
+	b.wg.Wait()
+	return b.Flush()
+}
+
+// Len returns the number of records waiting to be flushed
+func (b *BufferedInserter) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.buf)
+}
+
+func (b *BufferedInserter) loop() {
+	defer b.wg.Done()
+	ticker := time.NewTicker(b.flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			b.Flush()
+		case <-b.done:
+			return
+		}
+	}
+}
@@ -1,7 +1,10 @@
 package dbkit
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 )
@@ -26,6 +29,7 @@ type QueryBuilder struct {
 	tx                  *Tx
 	table               string
 	selectSql           string
+	selectArgs          []interface{} // SelectExpr 表达式中的参数
 	whereSql            []string
 	whereArgs           []interface{}
 	orWhereSql          []string      // OR conditions
@@ -44,13 +48,33 @@ type QueryBuilder struct {
 	lastErr             error
 	withTrashed         bool             // Include soft-deleted records
 	onlyTrashed         bool             // Only query soft-deleted records
+	ignoreSoftDelete    bool             // 本次查询完全忽略软删除配置（不过滤，Delete 执行物理删除）
 	skipTimestamps      bool             // Skip auto timestamps for insert/update
 	joins               []JoinClause     // JOIN clauses
 	subqueryTable       *Subquery        // FROM subquery
 	subqueryAlias       string           // FROM subquery alias
 	selectSubqueries    []SelectSubquery // SELECT subqueries
+	rawSQL              string           // RawBuilder 的内层 SQL
+	rawArgs             []interface{}    // RawBuilder 内层 SQL 的参数
+	lock                bool             // SELECT ... FOR UPDATE
+	lockWait            string           // 行锁等待策略：lockWaitSkipLocked / lockWaitNoWait，为空时阻塞等待
+	snapshotColumn      string           // Snapshot 分页的快照列
+	snapshotToken       string           // Snapshot 分页传入的 token，为空时重新截取
+	ignoreTenantScope   bool             // 本次查询不追加租户条件
+	tenantApplied       bool             // 租户条件已追加到 whereSql
+	indexHint           string           // IndexHint 指定的索引名，逗号分隔
+	with                []eagerRelation  // With 指定的预加载关联
 }
 
+// 行锁等待策略
+const (
+	lockWaitSkipLocked = "SKIP LOCKED"
+	lockWaitNoWait     = "NOWAIT"
+)
+
+// rawQueryAlias RawBuilder 内层查询的别名，Where/OrderBy/Select 中可用它限定列名
+const rawQueryAlias = "raw_query"
+
 // Table starts a new query builder for the default database
 func Table(name string) *QueryBuilder {
 
@@ -102,9 +126,56 @@ func (tx *Tx) Table(name string) *QueryBuilder {
 	}
 }
 
+// RawBuilder starts a query builder on the default database that uses querySQL as its source.
+// querySQL 作为子查询放在 FROM 中（SELECT * FROM (querySQL) AS raw_query），
+// 之后可以继续链式调用 Where、OrderBy、Paginate、Count、Cache 等
+func RawBuilder(querySQL string, args ...interface{}) *QueryBuilder {
+	db, err := defaultDB()
+	if err != nil {
+		return &QueryBuilder{lastErr: err}
+	}
+	return db.RawBuilder(querySQL, args...)
+}
+
+// RawBuilder starts a query builder on this database that uses querySQL as its source
+func (db *DB) RawBuilder(querySQL string, args ...interface{}) *QueryBuilder {
+	qb := &QueryBuilder{
+		db:                  db,
+		selectSql:           "*",
+		cacheRepositoryName: db.cacheRepositoryName,
+		cacheTTL:            db.cacheTTL,
+		cacheProvider:       db.cacheProvider,
+		lastErr:             db.lastErr,
+	}
+	return qb.setRawSQL(querySQL, args)
+}
+
+// RawBuilder starts a query builder within transaction that uses querySQL as its source
+func (tx *Tx) RawBuilder(querySQL string, args ...interface{}) *QueryBuilder {
+	qb := &QueryBuilder{
+		tx:                  tx,
+		selectSql:           "*",
+		cacheRepositoryName: tx.cacheRepositoryName,
+		cacheTTL:            tx.cacheTTL,
+		cacheProvider:       tx.cacheProvider,
+	}
+	return qb.setRawSQL(querySQL, args)
+}
+
+func (qb *QueryBuilder) setRawSQL(querySQL string, args []interface{}) *QueryBuilder {
+	querySQL = strings.TrimRight(strings.TrimSpace(querySQL), "; \t\n")
+	if querySQL == "" && qb.lastErr == nil {
+		qb.lastErr = fmt.Errorf("dbkit: RawBuilder SQL cannot be empty")
+	}
+	qb.rawSQL = querySQL
+	qb.rawArgs = args
+	return qb
+}
+
 // Select specifies the columns to select
 func (qb *QueryBuilder) Select(columns string) *QueryBuilder {
 	qb.selectSql = columns
+	qb.selectArgs = nil
 	return qb
 }
 
@@ -197,12 +268,129 @@ func buildGroupedCondition(tempQb *QueryBuilder) string {
 	return parts[0]
 }
 
+// When applies fn to the builder only when condition is true
+// 用于在一条链式调用中书写可选条件：.When(minPrice > 0, func(q *QueryBuilder) *QueryBuilder { return q.Where("price >= ?", minPrice) })
+func (qb *QueryBuilder) When(condition bool, fn func(qb *QueryBuilder) *QueryBuilder) *QueryBuilder {
+	if qb.lastErr != nil || !condition || fn == nil {
+		return qb
+	}
+	if result := fn(qb); result != nil {
+		return result
+	}
+	return qb
+}
+
+// WhenNotEmpty applies fn only when value is not empty
+// nil、空字符串、数值 0、false、空切片/map 均视为空值而跳过
+func (qb *QueryBuilder) WhenNotEmpty(value interface{}, fn func(qb *QueryBuilder) *QueryBuilder) *QueryBuilder {
+	return qb.When(!isEmptyValue(value), fn)
+}
+
 // OrderBy adds an order by clause to the query
 func (qb *QueryBuilder) OrderBy(orderBy string) *QueryBuilder {
 	qb.orderBy = orderBy
 	return qb
 }
 
+// OrderDir 排序方向
+type OrderDir string
+
+const (
+	// Asc 升序
+	Asc OrderDir = "ASC"
+	// Desc 降序
+	Desc OrderDir = "DESC"
+)
+
+// OrderByColumn appends a validated "column DIR" item to the ORDER BY clause
+// 可多次调用累加排序列；列名必须是合法标识符（支持 table.column），用于排序列来自用户输入的场景
+func (qb *QueryBuilder) OrderByColumn(column string, dir OrderDir) *QueryBuilder {
+	return qb.addOrderColumn(column, dir, "", nil)
+}
+
+// OrderByNullsFirst appends "column DIR NULLS FIRST" to the ORDER BY clause
+// PostgreSQL/Oracle 使用原生语法，其他数据库使用 CASE 表达式模拟
+func (qb *QueryBuilder) OrderByNullsFirst(column string, dir OrderDir) *QueryBuilder {
+	return qb.addOrderColumn(column, dir, "FIRST", nil)
+}
+
+// OrderByNullsLast appends "column DIR NULLS LAST" to the ORDER BY clause
+// PostgreSQL/Oracle 使用原生语法，其他数据库使用 CASE 表达式模拟
+func (qb *QueryBuilder) OrderByNullsLast(column string, dir OrderDir) *QueryBuilder {
+	return qb.addOrderColumn(column, dir, "LAST", nil)
+}
+
+// OrderByCollate appends "column COLLATE collation DIR" to the ORDER BY clause, overriding the column's collation for this query.
+// PostgreSQL 自动为排序规则加双引号（如 "zh-x-icu"），Oracle 使用 NLSSORT(column, 'NLS_SORT=collation')，
+// MySQL、SQL Server、SQLite 使用 COLLATE collation。排序规则名只能包含字母、数字、_、-、.、@
+func (qb *QueryBuilder) OrderByCollate(column, collation string, dir OrderDir) *QueryBuilder {
+	if qb.lastErr != nil {
+		return qb
+	}
+	collation = strings.TrimSpace(collation)
+	if !collationPattern.MatchString(collation) {
+		qb.lastErr = fmt.Errorf("dbkit: invalid collation '%s'", collation)
+		return qb
+	}
+	return qb.addOrderColumn(column, dir, "", func(driver DriverType, column string) string {
+		switch driver {
+		case PostgreSQL:
+			return fmt.Sprintf(`%s COLLATE "%s"`, column, collation)
+		case Oracle:
+			return fmt.Sprintf("NLSSORT(%s, 'NLS_SORT=%s')", column, collation)
+		default:
+			return fmt.Sprintf("%s COLLATE %s", column, collation)
+		}
+	})
+}
+
+// addOrderColumn 校验列名与排序方向后追加到 orderBy；expr 不为 nil 时按 expr(driver, column) 生成的表达式排序
+func (qb *QueryBuilder) addOrderColumn(column string, dir OrderDir, nulls string, expr func(driver DriverType, column string) string) *QueryBuilder {
+	if qb.lastErr != nil {
+		return qb
+	}
+	column = strings.TrimSpace(column)
+	if err := validateIdentifier(column); err != nil {
+		qb.lastErr = fmt.Errorf("dbkit: invalid order by column: %v", err)
+		return qb
+	}
+	d := OrderDir(strings.ToUpper(strings.TrimSpace(string(dir))))
+	if d == "" {
+		d = Asc
+	}
+	if d != Asc && d != Desc {
+		qb.lastErr = fmt.Errorf("dbkit: invalid order direction '%s', must be ASC or DESC", dir)
+		return qb
+	}
+
+	var driver DriverType
+	if mgr := qb.getDbManager(); mgr != nil {
+		driver = mgr.config.Driver
+	}
+	if expr != nil {
+		column = expr(driver, column)
+	}
+	item := column + " " + string(d)
+	if nulls != "" {
+		if driver == PostgreSQL || driver == Oracle {
+			item += " NULLS " + nulls
+		} else {
+			nullRank, notNullRank := 1, 0
+			if nulls == "FIRST" {
+				nullRank, notNullRank = 0, 1
+			}
+			item = fmt.Sprintf("CASE WHEN %s IS NULL THEN %d ELSE %d END, %s", column, nullRank, notNullRank, item)
+		}
+	}
+
+	if qb.orderBy == "" {
+		qb.orderBy = item
+	} else {
+		qb.orderBy += ", " + item
+	}
+	return qb
+}
+
 // Limit adds a limit clause to the query
 func (qb *QueryBuilder) Limit(limit int) *QueryBuilder {
 	qb.limit = limit
@@ -287,6 +475,68 @@ func (qb *QueryBuilder) WhereNotIn(column string, sub *Subquery) *QueryBuilder {
 	return qb
 }
 
+// WhereInBuilder adds a WHERE column IN (...) clause using a complete QueryBuilder as the source.
+// 内层查询的 Select/Join/Where/GroupBy/软删除过滤及其参数原样嵌入，适用于 Subquery 无法表达的带 JOIN 的子查询；
+// 内层查询应只选择一列
+func (qb *QueryBuilder) WhereInBuilder(column string, sub *QueryBuilder) *QueryBuilder {
+	return qb.whereInBuilder("IN", column, sub)
+}
+
+// WhereNotInBuilder adds a WHERE column NOT IN (...) clause using a complete QueryBuilder as the source
+func (qb *QueryBuilder) WhereNotInBuilder(column string, sub *QueryBuilder) *QueryBuilder {
+	return qb.whereInBuilder("NOT IN", column, sub)
+}
+
+func (qb *QueryBuilder) whereInBuilder(keyword, column string, sub *QueryBuilder) *QueryBuilder {
+	if qb.lastErr != nil {
+		return qb
+	}
+	if sub == nil {
+		return qb
+	}
+	if sub.lastErr != nil {
+		qb.lastErr = sub.lastErr
+		return qb
+	}
+	sub, err := sub.tenantScoped()
+	if err != nil {
+		qb.lastErr = err
+		return qb
+	}
+	subSQL, subArgs := sub.buildSelectSql()
+	qb.whereSql = append(qb.whereSql, fmt.Sprintf("%s %s (%s)", column, keyword, subSQL))
+	qb.whereArgs = append(qb.whereArgs, subArgs...)
+	return qb
+}
+
+// WhereExists adds a WHERE EXISTS (subquery) clause
+// 子查询的 Where 条件可直接引用外层表的列（相关子查询），如 Where("orders.user_id = users.id")
+func (qb *QueryBuilder) WhereExists(sub *Subquery) *QueryBuilder {
+	return qb.whereExists("EXISTS", sub)
+}
+
+// WhereNotExists adds a WHERE NOT EXISTS (subquery) clause
+func (qb *QueryBuilder) WhereNotExists(sub *Subquery) *QueryBuilder {
+	return qb.whereExists("NOT EXISTS", sub)
+}
+
+// whereExists 构建 EXISTS / NOT EXISTS 条件，子查询参数按调用顺序合并到 whereArgs
+func (qb *QueryBuilder) whereExists(keyword string, sub *Subquery) *QueryBuilder {
+	if qb.lastErr != nil {
+		return qb
+	}
+	if sub == nil {
+		return qb
+	}
+	subSQL, subArgs := sub.ToSQL()
+	if subSQL == "" {
+		return qb
+	}
+	qb.whereSql = append(qb.whereSql, fmt.Sprintf("%s (%s)", keyword, subSQL))
+	qb.whereArgs = append(qb.whereArgs, subArgs...)
+	return qb
+}
+
 // WhereInValues adds a WHERE column IN (?, ?, ...) clause with a list of values
 func (qb *QueryBuilder) WhereInValues(column string, values []interface{}) *QueryBuilder {
 	if qb.lastErr != nil {
@@ -304,6 +554,32 @@ func (qb *QueryBuilder) WhereInValues(column string, values []interface{}) *Quer
 	return qb
 }
 
+// WhereMap adds one "column = ?" condition per map entry, joined by AND.
+// 列名按字母顺序排序，保证生成的 SQL（以及缓存键）稳定；值为 nil 或 typed nil 指针时生成 column IS NULL
+func (qb *QueryBuilder) WhereMap(conditions map[string]interface{}) *QueryBuilder {
+	if qb.lastErr != nil {
+		return qb
+	}
+	columns := make([]string, 0, len(conditions))
+	for col := range conditions {
+		if err := validateIdentifier(col); err != nil {
+			qb.lastErr = fmt.Errorf("dbkit: invalid column in WhereMap: %v", err)
+			return qb
+		}
+		columns = append(columns, col)
+	}
+	sort.Strings(columns)
+	for _, col := range columns {
+		if val := conditions[col]; normalizeArg(val) == nil {
+			qb.whereSql = append(qb.whereSql, col+" IS NULL")
+		} else {
+			qb.whereSql = append(qb.whereSql, col+" = ?")
+			qb.whereArgs = append(qb.whereArgs, val)
+		}
+	}
+	return qb
+}
+
 // WhereNotInValues adds a WHERE column NOT IN (?, ?, ...) clause with a list of values
 func (qb *QueryBuilder) WhereNotInValues(column string, values []interface{}) *QueryBuilder {
 	if qb.lastErr != nil {
@@ -321,6 +597,68 @@ func (qb *QueryBuilder) WhereNotInValues(column string, values []interface{}) *Q
 	return qb
 }
 
+// WhereInTuple adds a WHERE (col1, col2, ...) IN ((?, ?), ...) clause for composite keys.
+// PostgreSQL、MySQL 使用行值 IN 语法；SQL Server、Oracle、SQLite 展开为 ((col1 = ? AND col2 = ?) OR ...)。
+// 每行的值个数必须与列数相同；rows 为空时与 WhereInValues 一样不添加条件
+func (qb *QueryBuilder) WhereInTuple(columns []string, rows [][]interface{}) *QueryBuilder {
+	if qb.lastErr != nil {
+		return qb
+	}
+	if len(columns) == 0 {
+		qb.lastErr = fmt.Errorf("dbkit: WhereInTuple requires at least one column")
+		return qb
+	}
+	for _, col := range columns {
+		if err := validateIdentifier(col); err != nil {
+			qb.lastErr = fmt.Errorf("dbkit: invalid column in WhereInTuple: %v", err)
+			return qb
+		}
+	}
+	for i, row := range rows {
+		if len(row) != len(columns) {
+			qb.lastErr = fmt.Errorf("dbkit: WhereInTuple row %d has %d values, expected %d", i, len(row), len(columns))
+			return qb
+		}
+	}
+	if len(rows) == 0 {
+		return qb
+	}
+	if len(columns) == 1 {
+		values := make([]interface{}, len(rows))
+		for i, row := range rows {
+			values[i] = row[0]
+		}
+		return qb.WhereInValues(columns[0], values)
+	}
+
+	var driver DriverType
+	if mgr := qb.getDbManager(); mgr != nil {
+		driver = mgr.config.Driver
+	}
+	items := make([]string, len(rows))
+	if driver == PostgreSQL || driver == MySQL {
+		tuple := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ") + ")"
+		for i := range rows {
+			items[i] = tuple
+		}
+		qb.whereSql = append(qb.whereSql, fmt.Sprintf("(%s) IN (%s)", strings.Join(columns, ", "), strings.Join(items, ", ")))
+	} else {
+		conds := make([]string, len(columns))
+		for i, col := range columns {
+			conds[i] = col + " = ?"
+		}
+		tuple := "(" + strings.Join(conds, " AND ") + ")"
+		for i := range rows {
+			items[i] = tuple
+		}
+		qb.whereSql = append(qb.whereSql, "("+strings.Join(items, " OR ")+")")
+	}
+	for _, row := range rows {
+		qb.whereArgs = append(qb.whereArgs, row...)
+	}
+	return qb
+}
+
 // WhereBetween adds a WHERE column BETWEEN ? AND ? clause
 func (qb *QueryBuilder) WhereBetween(column string, min, max interface{}) *QueryBuilder {
 	if qb.lastErr != nil {
@@ -378,6 +716,25 @@ func (qb *QueryBuilder) Having(condition string, args ...interface{}) *QueryBuil
 	return qb
 }
 
+// comparisonOperators HavingCount 等聚合条件允许的比较运算符
+var comparisonOperators = map[string]bool{
+	"=": true, "!=": true, "<>": true, ">": true, ">=": true, "<": true, "<=": true,
+}
+
+// HavingCount adds HAVING COUNT(*) <op> ? with n bound as a parameter.
+// 直接使用聚合表达式而不是 SELECT 别名，PostgreSQL、SQL Server、Oracle 的 HAVING 不能引用别名
+func (qb *QueryBuilder) HavingCount(op string, n int) *QueryBuilder {
+	if qb.lastErr != nil {
+		return qb
+	}
+	op = strings.TrimSpace(op)
+	if !comparisonOperators[op] {
+		qb.lastErr = fmt.Errorf("dbkit: invalid HAVING operator '%s'", op)
+		return qb
+	}
+	return qb.Having("COUNT(*) "+op+" ?", n)
+}
+
 // TableSubquery sets a subquery as the FROM source
 func (qb *QueryBuilder) TableSubquery(sub *Subquery, alias string) *QueryBuilder {
 	if qb.lastErr != nil {
@@ -407,6 +764,122 @@ func (qb *QueryBuilder) SelectSubquery(sub *Subquery, alias string) *QueryBuilde
 	return qb
 }
 
+// SelectCount appends "COUNT(col) AS alias" to the select list; empty col or "*" counts all rows
+func (qb *QueryBuilder) SelectCount(col, alias string) *QueryBuilder {
+	if col == "" {
+		col = "*"
+	}
+	return qb.selectAggregate("COUNT", col, alias)
+}
+
+// SelectSum appends "SUM(expr) AS alias" to the select list
+func (qb *QueryBuilder) SelectSum(expr, alias string) *QueryBuilder {
+	return qb.selectAggregate("SUM", expr, alias)
+}
+
+// SelectAvg appends "AVG(expr) AS alias" to the select list
+func (qb *QueryBuilder) SelectAvg(expr, alias string) *QueryBuilder {
+	return qb.selectAggregate("AVG", expr, alias)
+}
+
+// SelectMax appends "MAX(expr) AS alias" to the select list
+func (qb *QueryBuilder) SelectMax(expr, alias string) *QueryBuilder {
+	return qb.selectAggregate("MAX", expr, alias)
+}
+
+// SelectMin appends "MIN(expr) AS alias" to the select list
+func (qb *QueryBuilder) SelectMin(expr, alias string) *QueryBuilder {
+	return qb.selectAggregate("MIN", expr, alias)
+}
+
+// selectAggregate 将聚合表达式追加到 SELECT 列表，便于与 Select("status") + GroupBy 组合使用
+func (qb *QueryBuilder) selectAggregate(fn, expr, alias string) *QueryBuilder {
+	if qb.lastErr != nil {
+		return qb
+	}
+	if strings.TrimSpace(expr) == "" {
+		qb.lastErr = fmt.Errorf("dbkit: %s expression cannot be empty", fn)
+		return qb
+	}
+	item := fmt.Sprintf("%s(%s)", fn, expr)
+	if alias != "" {
+		if err := validateIdentifier(alias); err != nil {
+			qb.lastErr = fmt.Errorf("dbkit: invalid alias for %s: %v", fn, err)
+			return qb
+		}
+		item += " AS " + alias
+	}
+	qb.appendSelect(item)
+	return qb
+}
+
+// SelectAs appends "expr AS alias" to the select list, e.g. SelectAs("d.name", "dept_name") to avoid
+// same-named columns of joined tables overwriting each other in the Record.
+// alias 可以是 "departments.name" 这种带点的形式，会按方言加引号
+func (qb *QueryBuilder) SelectAs(expr, alias string) *QueryBuilder {
+	if qb.lastErr != nil {
+		return qb
+	}
+	if strings.TrimSpace(expr) == "" {
+		qb.lastErr = fmt.Errorf("dbkit: SelectAs expression cannot be empty")
+		return qb
+	}
+	if err := validateIdentifier(alias); err != nil {
+		qb.lastErr = fmt.Errorf("dbkit: invalid alias for SelectAs: %v", err)
+		return qb
+	}
+	var driver DriverType
+	if mgr := qb.getDbManager(); mgr != nil && mgr.config != nil {
+		driver = mgr.config.Driver
+	}
+	qb.appendSelect(expr + " AS " + quoteAlias(driver, alias))
+	return qb
+}
+
+// SelectTable appends "table.*" to the select list, e.g. for selecting every column
+// of one side of a JOIN: Select("b.name").SelectTable("a")
+func (qb *QueryBuilder) SelectTable(table string) *QueryBuilder {
+	if qb.lastErr != nil {
+		return qb
+	}
+	if err := validateIdentifier(table); err != nil {
+		qb.lastErr = err
+		return qb
+	}
+	qb.appendSelect(table + ".*")
+	return qb
+}
+
+// SelectExpr appends a parameterized expression such as dbkit.Case() to the select list;
+// 表达式中的参数按出现顺序绑定在 WHERE 参数之前
+func (qb *QueryBuilder) SelectExpr(expr SQLExpr) *QueryBuilder {
+	if qb.lastErr != nil {
+		return qb
+	}
+	if c, ok := expr.(*CaseExpr); ok && c.err != nil {
+		qb.lastErr = c.err
+		return qb
+	}
+	exprSQL, args := expr.ToSQL()
+	if exprSQL == "" {
+		qb.lastErr = fmt.Errorf("dbkit: select expression is empty")
+		return qb
+	}
+	qb.appendSelect(exprSQL)
+	qb.selectArgs = append(qb.selectArgs, args...)
+	return qb
+}
+
+// appendSelect 将一项追加到 SELECT 列表；若 SELECT 仍为默认的 "*"，则直接替换
+func (qb *QueryBuilder) appendSelect(item string) {
+	current := strings.TrimSpace(qb.selectSql)
+	if current == "" || current == "*" {
+		qb.selectSql = item
+	} else {
+		qb.selectSql = current + ", " + item
+	}
+}
+
 // Cache enables caching for the query
 func (qb *QueryBuilder) Cache(cacheRepositoryName string, ttl ...time.Duration) *QueryBuilder {
 	qb.cacheRepositoryName = cacheRepositoryName
@@ -459,6 +932,32 @@ func (qb *QueryBuilder) Timeout(d time.Duration) *QueryBuilder {
 	return qb
 }
 
+// Debug logs every statement executed by this query chain (final SQL, args and elapsed time) at info level,
+// regardless of SetDebugMode. 只影响当前 builder，适合在大型应用中排查单条查询；
+// 全局调试模式已开启时不会重复输出
+func (qb *QueryBuilder) Debug() *QueryBuilder {
+	if qb.db != nil && !qb.db.debug {
+		db := *qb.db
+		db.debug = true
+		qb.db = &db
+	}
+	if qb.tx != nil && !qb.tx.debug {
+		qb.tx = &Tx{
+			tx:                  qb.tx.tx,
+			dbMgr:               qb.tx.dbMgr,
+			cacheRepositoryName: qb.tx.cacheRepositoryName,
+			cacheTTL:            qb.tx.cacheTTL,
+			timeout:             qb.tx.timeout,
+			cacheProvider:       qb.tx.cacheProvider,
+			countCacheTTL:       qb.tx.countCacheTTL,
+			ctx:                 qb.tx.ctx,
+			debug:               true,
+			skipTenant:          qb.tx.skipTenant,
+		}
+	}
+	return qb
+}
+
 // WithCountCache 启用分页计数缓存
 // 用于在分页查询时缓存 COUNT 查询结果，避免重复执行 COUNT 语句
 // ttl: 缓存时间，如果为 0 则不缓存，如果大于 0 则缓存指定时间
@@ -483,13 +982,107 @@ func (qb *QueryBuilder) getEffectiveCache() CacheProvider {
 	return GetCache()
 }
 
+// Lock adds an exclusive row lock to the query (SELECT ... FOR UPDATE; SQL Server uses WITH (UPDLOCK, ROWLOCK)).
+// 锁在事务结束时释放，因此应在事务中使用；加锁的查询不使用缓存，SQLite 没有行锁，忽略该设置
+func (qb *QueryBuilder) Lock() *QueryBuilder {
+	qb.lock = true
+	return qb
+}
+
+// SkipLocked locks the matching rows and skips rows already locked by other transactions (FOR UPDATE SKIP LOCKED,
+// SQL Server READPAST)，多个消费者可以并发地从同一张表中领取任务；隐含 Lock()
+func (qb *QueryBuilder) SkipLocked() *QueryBuilder {
+	qb.lock = true
+	qb.lockWait = lockWaitSkipLocked
+	return qb
+}
+
+// NoWait locks the matching rows and fails immediately instead of waiting when a row is already locked
+// (FOR UPDATE NOWAIT，SQL Server WITH (..., NOWAIT))；隐含 Lock()
+func (qb *QueryBuilder) NoWait() *QueryBuilder {
+	qb.lock = true
+	qb.lockWait = lockWaitNoWait
+	return qb
+}
+
+// IndexHint forces the query to use the given index (comma-separated for several), a targeted escape hatch
+// when the optimizer picks the wrong index. 按方言生成：MySQL FORCE INDEX (idx)，SQL Server WITH (INDEX(idx))，
+// Oracle /*+ INDEX(table idx) */；PostgreSQL、SQLite 不支持索引提示，记录警告后忽略。只作用于普通表查询
+func (qb *QueryBuilder) IndexHint(hint string) *QueryBuilder {
+	if qb.lastErr != nil {
+		return qb
+	}
+	var names []string
+	for _, name := range strings.Split(hint, ",") {
+		name = strings.TrimSpace(name)
+		if err := validateIdentifier(name); err != nil {
+			qb.lastErr = err
+			return qb
+		}
+		names = append(names, name)
+	}
+
+	if mgr := qb.getDbManager(); mgr != nil && mgr.config != nil {
+		switch mgr.config.Driver {
+		case MySQL, SQLServer, Oracle:
+		default:
+			LogWarn("当前数据库不支持索引提示，已忽略", map[string]interface{}{
+				"db":     mgr.name,
+				"driver": mgr.config.Driver,
+				"table":  qb.table,
+				"index":  hint,
+			})
+			return qb
+		}
+	}
+	qb.indexHint = strings.Join(names, ", ")
+	return qb
+}
+
+// andCondition 返回追加了 AND 条件的副本，不修改 qb；存在 OR 条件时先整体加括号，
+// 保证追加的条件对所有分支生效。condition 为空时只做分组
+func (qb *QueryBuilder) andCondition(condition string, args ...interface{}) *QueryBuilder {
+	base := *qb
+	base.whereSql = append([]string(nil), qb.whereSql...)
+	base.whereArgs = append([]interface{}(nil), qb.whereArgs...)
+	if len(qb.orWhereSql) > 0 {
+		group := strings.Join(qb.orWhereSql, " OR ")
+		if len(qb.whereSql) > 0 {
+			group = "(" + strings.Join(qb.whereSql, " AND ") + ") OR " + group
+		}
+		base.whereSql = []string{"(" + group + ")"}
+		base.whereArgs = append(base.whereArgs, qb.orWhereArgs...)
+		base.orWhereSql = nil
+		base.orWhereArgs = nil
+	}
+	if condition != "" {
+		base.whereSql = append(base.whereSql, condition)
+		base.whereArgs = append(base.whereArgs, args...)
+	}
+	return &base
+}
+
 // buildSelectSql constructs the final SELECT SQL string
 func (qb *QueryBuilder) buildSelectSql() (string, []interface{}) {
+	var driver DriverType
+	if mgr := qb.getDbManager(); mgr != nil && mgr.config != nil {
+		driver = mgr.config.Driver
+	}
+	// Oracle 不允许 FOR UPDATE 与 ROWNUM 子查询或 FETCH 同时使用，加锁时把 Limit 改写为同层的 ROWNUM 条件
+	rownumLimit := 0
+	if qb.lock && driver == Oracle && qb.limit > 0 && qb.offset <= 0 {
+		rownumLimit = qb.limit
+	}
+
 	var sb strings.Builder
 	var allArgs []interface{}
+	allArgs = append(allArgs, qb.selectArgs...)
 
 	// Build SELECT clause with optional subqueries
 	selectPart := qb.selectSql
+	if joinColumnPrefix.Load() && len(qb.joins) > 0 && qb.rawSQL == "" && qb.subqueryTable == nil {
+		selectPart = qb.prefixJoinColumns(selectPart, driver)
+	}
 	if len(qb.selectSubqueries) > 0 {
 		for _, ss := range qb.selectSubqueries {
 			subSQL, subArgs := ss.subquery.ToSQL()
@@ -507,12 +1100,40 @@ func (qb *QueryBuilder) buildSelectSql() (string, []interface{}) {
 
 	// Build FROM clause (table or subquery)
 	var fromPart string
-	if qb.subqueryTable != nil && qb.subqueryAlias != "" {
+	if qb.rawSQL != "" {
+		// Oracle 的表别名前不能使用 AS
+		if driver == Oracle {
+			fromPart = fmt.Sprintf("(%s) %s", qb.rawSQL, rawQueryAlias)
+		} else {
+			fromPart = fmt.Sprintf("(%s) AS %s", qb.rawSQL, rawQueryAlias)
+		}
+		allArgs = append(allArgs, qb.rawArgs...)
+	} else if qb.subqueryTable != nil && qb.subqueryAlias != "" {
 		subSQL, subArgs := qb.subqueryTable.ToSQL()
 		fromPart = fmt.Sprintf("(%s) AS %s", subSQL, qb.subqueryAlias)
 		allArgs = append(allArgs, subArgs...)
 	} else {
 		fromPart = qb.table
+		if qb.indexHint != "" {
+			switch driver {
+			case MySQL:
+				fromPart += fmt.Sprintf(" FORCE INDEX (%s)", qb.indexHint)
+			case Oracle:
+				selectPart = fmt.Sprintf("/*+ INDEX(%s %s) */ %s", qb.table, strings.ReplaceAll(qb.indexHint, ",", ""), selectPart)
+			}
+		}
+		if driver == SQLServer {
+			var hints []string
+			if qb.indexHint != "" {
+				hints = append(hints, fmt.Sprintf("INDEX(%s)", qb.indexHint))
+			}
+			if qb.lock {
+				hints = append(hints, qb.sqlServerLockHint())
+			}
+			if len(hints) > 0 {
+				fromPart += fmt.Sprintf(" WITH (%s)", strings.Join(hints, ", "))
+			}
+		}
 	}
 
 	sb.WriteString(fmt.Sprintf("SELECT %s FROM %s", selectPart, fromPart))
@@ -536,6 +1157,9 @@ func (qb *QueryBuilder) buildSelectSql() (string, []interface{}) {
 	// Build WHERE clause with AND and OR conditions
 	if len(whereClauses) > 0 || len(qb.orWhereSql) > 0 {
 		sb.WriteString(" WHERE ")
+		if rownumLimit > 0 && len(qb.orWhereSql) > 0 {
+			sb.WriteString("(")
+		}
 
 		var wherePartBuilt bool
 		if len(whereClauses) > 0 {
@@ -557,6 +1181,14 @@ func (qb *QueryBuilder) buildSelectSql() (string, []interface{}) {
 			}
 			sb.WriteString(strings.Join(qb.orWhereSql, " OR "))
 		}
+		if rownumLimit > 0 {
+			if len(qb.orWhereSql) > 0 {
+				sb.WriteString(")")
+			}
+			sb.WriteString(fmt.Sprintf(" AND ROWNUM <= %d", rownumLimit))
+		}
+	} else if rownumLimit > 0 {
+		sb.WriteString(fmt.Sprintf(" WHERE ROWNUM <= %d", rownumLimit))
 	}
 
 	// Append WHERE args after JOIN args (AND args first, then OR args)
@@ -581,83 +1213,245 @@ func (qb *QueryBuilder) buildSelectSql() (string, []interface{}) {
 		sb.WriteString(qb.orderBy)
 	}
 
-	if qb.limit > 0 {
-		sb.WriteString(fmt.Sprintf(" LIMIT %d", qb.limit))
+	querySQL := sb.String()
+	if rownumLimit == 0 {
+		querySQL = qb.applyLimitOffset(querySQL)
+	}
+	if qb.lock && (driver == MySQL || driver == PostgreSQL || driver == Oracle) {
+		querySQL += " FOR UPDATE"
+		if qb.lockWait != "" {
+			querySQL += " " + qb.lockWait
+		}
 	}
+	return querySQL, allArgs
+}
 
+// errPaginateLock 分页查询由计数与取数两条语句组成，无法保证锁定的正是返回的行
+var errPaginateLock = errors.New("dbkit: Lock, SkipLocked and NoWait cannot be used with Paginate")
+
+// lockLimitError 检查 Oracle 上无法正确执行的加锁组合：FOR UPDATE 不能与 OFFSET / FETCH 同时使用，
+// Limit 改写成的同层 ROWNUM 条件先于 ORDER BY 与 SKIP LOCKED 生效，会锁定并返回错误的行
+func (qb *QueryBuilder) lockLimitError() error {
+	if !qb.lock || (qb.limit <= 0 && qb.offset <= 0) {
+		return nil
+	}
+	mgr := qb.getDbManager()
+	if mgr == nil || mgr.config == nil || mgr.config.Driver != Oracle {
+		return nil
+	}
 	if qb.offset > 0 {
-		sb.WriteString(fmt.Sprintf(" OFFSET %d", qb.offset))
+		return fmt.Errorf("dbkit: Oracle cannot combine Lock with Offset")
+	}
+	if qb.orderBy != "" || qb.lockWait == lockWaitSkipLocked {
+		return fmt.Errorf("dbkit: Oracle cannot combine Lock with Limit/QueryFirst when OrderBy or SkipLocked is used, ROWNUM is applied before ORDER BY and SKIP LOCKED")
 	}
+	return nil
+}
 
-	return sb.String(), allArgs
+// sqlServerLockHint 返回 SQL Server 中与 FOR UPDATE 等价的表提示
+func (qb *QueryBuilder) sqlServerLockHint() string {
+	switch qb.lockWait {
+	case lockWaitSkipLocked:
+		return "UPDLOCK, ROWLOCK, READPAST"
+	case lockWaitNoWait:
+		return "UPDLOCK, ROWLOCK, NOWAIT"
+	}
+	return "UPDLOCK, ROWLOCK"
 }
 
-// removeLimitOffset 移除SQL语句中的LIMIT和OFFSET子句
-// 因为Paginate会自动处理分页逻辑
-func removeLimitOffset(sql string) string {
-	// 使用正则表达式移除LIMIT和OFFSET子句
-	// 这里使用简单的字符串处理，因为buildSelectSql生成的SQL格式是可预测的
+// applyLimitOffset 按数据库方言追加 LIMIT / OFFSET
+//   - MySQL / PostgreSQL / SQLite: LIMIT n OFFSET m
+//   - SQL Server 2012+ / Oracle 12c+: OFFSET m ROWS FETCH NEXT n ROWS ONLY（SQL Server 无 ORDER BY 时补 ORDER BY (SELECT NULL)）
+//   - Oracle 11g、SQL Server 2008 及更早（检测到旧版本或 Config.OracleLegacyPaging）: ROWNUM / ROW_NUMBER() 包装，扫描结果时去掉辅助的 dbkit_rn 列
+//
+// 只有 LIMIT 时保持 " LIMIT n"，由 prepareQuerySQL 转换为 TOP / ROWNUM
+func (qb *QueryBuilder) applyLimitOffset(querySQL string) string {
+	if qb.offset <= 0 {
+		if qb.limit > 0 {
+			return querySQL + fmt.Sprintf(" LIMIT %d", qb.limit)
+		}
+		return querySQL
+	}
 
-	// 移除 LIMIT 子句
-	if idx := strings.LastIndex(strings.ToUpper(sql), " LIMIT "); idx != -1 {
-		// 找到LIMIT后面的数字结束位置
-		remaining := sql[idx+7:] // 跳过" LIMIT "
-		var endIdx int
-		for i, r := range remaining {
-			if r < '0' || r > '9' {
-				endIdx = i
-				break
-			}
+	var driver DriverType
+	mgr := qb.getDbManager()
+	if mgr != nil && mgr.config != nil {
+		driver = mgr.config.Driver
+	}
+
+	switch {
+	case (driver == SQLServer || driver == Oracle) && mgr.legacyPaging():
+		return mgr.legacyPageSQL(querySQL, qb.offset, qb.limit)
+	case driver == SQLServer || driver == Oracle:
+		if driver == SQLServer && qb.orderBy == "" {
+			// SQL Server 的 OFFSET 必须配合 ORDER BY
+			querySQL += " ORDER BY (SELECT NULL)"
+		}
+		querySQL += fmt.Sprintf(" OFFSET %d ROWS", qb.offset)
+		if qb.limit > 0 {
+			querySQL += fmt.Sprintf(" FETCH NEXT %d ROWS ONLY", qb.limit)
+		}
+		return querySQL
+	default:
+		if qb.limit > 0 {
+			return querySQL + fmt.Sprintf(" LIMIT %d OFFSET %d", qb.limit, qb.offset)
+		}
+		if driver == MySQL {
+			// MySQL 不支持单独的 OFFSET，使用最大行数作为 LIMIT
+			return querySQL + fmt.Sprintf(" LIMIT 18446744073709551615 OFFSET %d", qb.offset)
 		}
-		if endIdx == 0 {
-			endIdx = len(remaining)
+		if driver == SQLite3 {
+			return querySQL + fmt.Sprintf(" LIMIT -1 OFFSET %d", qb.offset)
 		}
-		sql = sql[:idx] + remaining[endIdx:]
+		return querySQL + fmt.Sprintf(" OFFSET %d", qb.offset)
 	}
+}
 
-	// 移除 OFFSET 子句
-	if idx := strings.LastIndex(strings.ToUpper(sql), " OFFSET "); idx != -1 {
-		// 找到OFFSET后面的数字结束位置
-		remaining := sql[idx+8:] // 跳过" OFFSET "
-		var endIdx int
-		for i, r := range remaining {
-			if r < '0' || r > '9' {
-				endIdx = i
-				break
-			}
-		}
-		if endIdx == 0 {
-			endIdx = len(remaining)
+// Chunk processes all matching rows in batches of size using keyset pagination on the
+// table's primary key (WHERE pk > last ORDER BY pk LIMIT size), calling fn for each batch
+// until the rows are exhausted or fn returns an error.
+// 与 OFFSET 分页不同，深度翻页不会变慢；要求表具有单列主键，已设置的 OrderBy/Limit/Offset 会被忽略
+func (qb *QueryBuilder) Chunk(size int, fn func(records []*Record) error) error {
+	if qb.lastErr != nil {
+		return qb.lastErr
+	}
+	if size <= 0 {
+		return fmt.Errorf("dbkit: chunk size must be greater than 0")
+	}
+	if fn == nil {
+		return fmt.Errorf("dbkit: chunk callback is nil")
+	}
+	if qb.subqueryTable != nil || qb.rawSQL != "" {
+		return fmt.Errorf("dbkit: Chunk does not support FROM subqueries")
+	}
+	qb, err := qb.tenantScoped()
+	if err != nil {
+		return err
+	}
+
+	mgr := qb.getDbManager()
+	if mgr == nil {
+		return fmt.Errorf("dbkit: database not initialized")
+	}
+	var executor sqlExecutor
+	if qb.tx != nil {
+		executor = qb.tx.executor()
+	} else {
+		sdb, err := mgr.getDB()
+		if err != nil {
+			return err
 		}
-		sql = sql[:idx] + remaining[endIdx:]
+		executor = qb.db.executor(sdb)
+	}
+	pks, err := mgr.getPrimaryKeys(executor, qb.table)
+	if err != nil {
+		return err
 	}
+	if len(pks) != 1 {
+		return fmt.Errorf("dbkit: Chunk requires table '%s' to have a single-column primary key", qb.table)
+	}
+	pk := pks[0]
+	pkRef := pk
+	if len(qb.joins) > 0 {
+		pkRef = qb.table + "." + pk
+	}
+
+	// 基于副本构造查询，不修改调用方的 QueryBuilder
+	base := *qb.andCondition("")
+	base.cacheRepositoryName = ""
+	base.orderBy = pkRef + " ASC"
+	base.limit = size
+	base.offset = 0
+
+	var lastKey interface{}
+	for {
+		page := base
+		page.whereSql = append([]string(nil), base.whereSql...)
+		page.whereArgs = append([]interface{}(nil), base.whereArgs...)
+		if lastKey != nil {
+			page.whereSql = append(page.whereSql, pkRef+" > ?")
+			page.whereArgs = append(page.whereArgs, lastKey)
+		}
+
+		records, err := page.Query()
+		if err != nil {
+			return err
+		}
+		if len(records) == 0 {
+			return nil
+		}
 
-	return strings.TrimSpace(sql)
+		batch := make([]*Record, len(records))
+		for i := range records {
+			batch[i] = &records[i]
+		}
+		lastKey = batch[len(batch)-1].Get(pk)
+		if err := fn(batch); err != nil {
+			return err
+		}
+		if len(records) < size {
+			return nil
+		}
+		if lastKey == nil {
+			return fmt.Errorf("dbkit: Chunk requires primary key column '%s' in the select list", pk)
+		}
+	}
 }
 
 // getSoftDeleteCondition returns the soft delete filter condition
 func (qb *QueryBuilder) getSoftDeleteCondition() string {
-	var mgr *dbManager
-	if qb.db != nil && qb.db.dbMgr != nil {
-		mgr = qb.db.dbMgr
-	} else if qb.tx != nil && qb.tx.dbMgr != nil {
-		mgr = qb.tx.dbMgr
-	}
+	mgr := qb.getDbManager()
 	if mgr == nil {
 		return ""
 	}
+	if qb.ignoreSoftDelete {
+		return ""
+	}
 	return mgr.buildSoftDeleteCondition(qb.table, qb.withTrashed, qb.onlyTrashed)
 }
 
+// getDbManager returns the dbManager bound to this builder (via DB or Tx), or nil
+func (qb *QueryBuilder) getDbManager() *dbManager {
+	if qb.db != nil && qb.db.dbMgr != nil {
+		return qb.db.dbMgr
+	}
+	if qb.tx != nil && qb.tx.dbMgr != nil {
+		return qb.tx.dbMgr
+	}
+	return nil
+}
+
 // Query executes the query and returns a slice of Records
 func (qb *QueryBuilder) Query() ([]Record, error) {
+	records, err := qb.queryRecords()
+	if err != nil || len(qb.with) == 0 {
+		return records, err
+	}
+	if qb.cacheRepositoryName != "" {
+		records = cloneRecordSlice(records)
+	}
+	if err := qb.loadRelations(records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// queryRecords 执行查询，不加载 With 指定的关联
+func (qb *QueryBuilder) queryRecords() ([]Record, error) {
 	if qb.lastErr != nil {
 		return nil, qb.lastErr
 	}
+	if err := qb.lockLimitError(); err != nil {
+		return nil, err
+	}
+	qb, err := qb.tenantScoped()
+	if err != nil {
+		return nil, err
+	}
 	sql, args := qb.buildSelectSql()
 
 	// Handle caching
-	if qb.cacheRepositoryName != "" && qb.tx == nil {
+	if qb.cacheRepositoryName != "" && qb.tx == nil && !qb.lock {
 		cache := qb.getEffectiveCache()
 		cacheKey := qb.generateCacheKey(sql, args)
 		if val, ok := cache.CacheGet(qb.cacheRepositoryName, cacheKey); ok {
@@ -668,25 +1462,27 @@ func (qb *QueryBuilder) Query() ([]Record, error) {
 		// If not in cache, query and store
 		db := qb.db
 		if qb.timeout > 0 {
-			db = &DB{dbMgr: qb.db.dbMgr, timeout: qb.timeout}
-		}
-		records, err := db.Query(sql, args...)
-		if err == nil {
-			cache.CacheSet(qb.cacheRepositoryName, cacheKey, records, qb.cacheTTL)
+			db = &DB{dbMgr: qb.db.dbMgr, timeout: qb.timeout, debug: qb.db.debug, ctx: qb.db.ctx}
 		}
-		return records, err
+		return loadOnce(qb.cacheRepositoryName, cacheKey, func() ([]Record, error) {
+			records, err := db.Query(sql, args...)
+			if err == nil {
+				cache.CacheSet(qb.cacheRepositoryName, cacheKey, records, qb.cacheTTL)
+			}
+			return records, err
+		})
 	}
 
 	if qb.tx != nil {
 		if qb.timeout > 0 {
-			tx := &Tx{tx: qb.tx.tx, dbMgr: qb.tx.dbMgr, timeout: qb.timeout}
+			tx := &Tx{tx: qb.tx.tx, dbMgr: qb.tx.dbMgr, timeout: qb.timeout, ctx: qb.tx.ctx, debug: qb.tx.debug}
 			return tx.Query(sql, args...)
 		}
 		return qb.tx.Query(sql, args...)
 	}
 
 	if qb.timeout > 0 {
-		db := &DB{dbMgr: qb.db.dbMgr, timeout: qb.timeout}
+		db := &DB{dbMgr: qb.db.dbMgr, timeout: qb.timeout, debug: qb.db.debug, ctx: qb.db.ctx}
 		return db.Query(sql, args...)
 	}
 	return qb.db.Query(sql, args...)
@@ -717,6 +1513,74 @@ func (qb *QueryBuilder) FindToDbModel(dest interface{}) error {
 	return ToStructs(records, dest)
 }
 
+// FindMap executes the query and indexes the results by keyColumn; a later row replaces an earlier one with the same key.
+// 键的类型取决于驱动返回的值（如 SQLite 整数为 int64），[]byte 会转换为 string
+func (qb *QueryBuilder) FindMap(keyColumn string) (map[interface{}]*Record, error) {
+	records, err := qb.Find()
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[interface{}]*Record, len(records))
+	for i := range records {
+		key, err := recordMapKey(&records[i], keyColumn)
+		if err != nil {
+			return nil, err
+		}
+		result[key] = &records[i]
+	}
+	return result, nil
+}
+
+// FindMapBy is like FindMap but returns an error when two rows share the same key
+func (qb *QueryBuilder) FindMapBy(keyColumn string) (map[interface{}]*Record, error) {
+	records, err := qb.Find()
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[interface{}]*Record, len(records))
+	for i := range records {
+		key, err := recordMapKey(&records[i], keyColumn)
+		if err != nil {
+			return nil, err
+		}
+		if _, exists := result[key]; exists {
+			return nil, fmt.Errorf("dbkit: duplicate value %v for key column '%s'", key, keyColumn)
+		}
+		result[key] = &records[i]
+	}
+	return result, nil
+}
+
+// FindGroupBy executes the query and groups the results by keyColumn, keeping the query order within each group
+func (qb *QueryBuilder) FindGroupBy(keyColumn string) (map[interface{}][]*Record, error) {
+	records, err := qb.Find()
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[interface{}][]*Record)
+	for i := range records {
+		key, err := recordMapKey(&records[i], keyColumn)
+		if err != nil {
+			return nil, err
+		}
+		result[key] = append(result[key], &records[i])
+	}
+	return result, nil
+}
+
+// recordMapKey 取出用作 map 键的列值，[]byte 不可作为 map 键，转换为 string
+func recordMapKey(record *Record, keyColumn string) (interface{}, error) {
+	if !record.Has(keyColumn) {
+		return nil, fmt.Errorf("dbkit: key column '%s' not found in query results", keyColumn)
+	}
+	switch v := record.Get(keyColumn).(type) {
+	case []byte:
+		return string(v), nil
+	default:
+		return v, nil
+	}
+}
+
 func (qb *QueryBuilder) QueryToDbModel(dest interface{}) error {
 	records, err := qb.Find()
 	if err != nil {
@@ -726,21 +1590,49 @@ func (qb *QueryBuilder) QueryToDbModel(dest interface{}) error {
 }
 
 // QueryFirst executes the query and returns the first Record
+// 没有匹配的记录时默认返回 (nil, nil)，调用 SetQueryFirstNotFoundError(true) 后返回 (nil, ErrNoRows)
 func (qb *QueryBuilder) QueryFirst() (*Record, error) {
+	record, err := qb.queryFirstRecord()
+	if err != nil || record == nil || len(qb.with) == 0 {
+		return queryFirstResult(record, err)
+	}
+	if qb.cacheRepositoryName != "" {
+		record = record.Clone()
+	}
+	records := []*Record{record}
+	if err := qb.loadRelationsFor(records); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// queryFirstRecord 查询第一条记录，不加载 With 指定的关联
+func (qb *QueryBuilder) queryFirstRecord() (*Record, error) {
 	if qb.lastErr != nil {
 		return nil, qb.lastErr
 	}
+	qb, err := qb.tenantScoped()
+	if err != nil {
+		return nil, err
+	}
 	// Temporarily set limit to 1 if not set or set to something else
 	oldLimit := qb.limit
 	qb.limit = 1
+	if err := qb.lockLimitError(); err != nil {
+		qb.limit = oldLimit
+		return nil, err
+	}
 	sql, args := qb.buildSelectSql()
 	qb.limit = oldLimit
 
 	// Handle caching
-	if qb.cacheRepositoryName != "" && qb.tx == nil {
+	if qb.cacheRepositoryName != "" && qb.tx == nil && !qb.lock {
 		cache := qb.getEffectiveCache()
 		cacheKey := qb.generateCacheKey(sql, args) + "_first"
 		if val, ok := cache.CacheGet(qb.cacheRepositoryName, cacheKey); ok {
+			if isNegativeCacheValue(val) {
+				return nil, nil
+			}
 			if record, ok := val.(*Record); ok {
 				return record, nil
 			}
@@ -748,28 +1640,32 @@ func (qb *QueryBuilder) QueryFirst() (*Record, error) {
 		// If not in cache, query and store
 		db := qb.db
 		if qb.timeout > 0 {
-			db = &DB{dbMgr: qb.db.dbMgr, timeout: qb.timeout}
-		}
-		record, err := db.QueryFirst(sql, args...)
-		if err == nil && record != nil {
-			cache.CacheSet(qb.cacheRepositoryName, cacheKey, record, qb.cacheTTL)
+			db = &DB{dbMgr: qb.db.dbMgr, timeout: qb.timeout, debug: qb.db.debug, ctx: qb.db.ctx}
 		}
-		return record, err
+		return loadOnce(qb.cacheRepositoryName, cacheKey, func() (*Record, error) {
+			record, err := db.queryFirst(sql, args...)
+			if err == nil && record != nil {
+				cache.CacheSet(qb.cacheRepositoryName, cacheKey, record, qb.cacheTTL)
+			} else if err == nil {
+				db.dbMgr.cacheNotFound(cache, qb.cacheRepositoryName, cacheKey, sql, qb.cacheTTL)
+			}
+			return record, err
+		})
 	}
 
 	if qb.tx != nil {
 		if qb.timeout > 0 {
-			tx := &Tx{tx: qb.tx.tx, dbMgr: qb.tx.dbMgr, timeout: qb.timeout}
-			return tx.QueryFirst(sql, args...)
+			tx := &Tx{tx: qb.tx.tx, dbMgr: qb.tx.dbMgr, timeout: qb.timeout, ctx: qb.tx.ctx, debug: qb.tx.debug}
+			return tx.queryFirst(sql, args...)
 		}
-		return qb.tx.QueryFirst(sql, args...)
+		return qb.tx.queryFirst(sql, args...)
 	}
 
 	if qb.timeout > 0 {
-		db := &DB{dbMgr: qb.db.dbMgr, timeout: qb.timeout}
-		return db.QueryFirst(sql, args...)
+		db := &DB{dbMgr: qb.db.dbMgr, timeout: qb.timeout, debug: qb.db.debug, ctx: qb.db.ctx}
+		return db.queryFirst(sql, args...)
 	}
-	return qb.db.QueryFirst(sql, args...)
+	return qb.db.queryFirst(sql, args...)
 }
 
 // FindFirst is an alias for QueryFirst
@@ -778,28 +1674,118 @@ func (qb *QueryBuilder) FindFirst() (*Record, error) {
 }
 
 // FindFirstToDbModel executes the query and converts the first result to the provided struct pointer
+// 列与字段的映射规则与 ToStruct 相同；没有匹配的记录时返回 ErrRecordNotFound
 func (qb *QueryBuilder) FindFirstToDbModel(dest interface{}) error {
 	record, err := qb.FindFirst()
 	if err != nil {
 		return err
 	}
 	if record == nil {
-		return fmt.Errorf("dbkit: no record found")
+		return ErrRecordNotFound
 	}
 	return ToStruct(record, dest)
 }
 
+// Value selects a single column (or expression) of the first matching row and returns its value.
+// 没有匹配的记录时返回 ErrRecordNotFound，值为 NULL 时返回 nil；本次查询以 column 代替 Select 指定的列
+func (qb *QueryBuilder) Value(column string) (interface{}, error) {
+	record, key, err := qb.valueRecord(column)
+	if err != nil {
+		return nil, err
+	}
+	return record.Get(key), nil
+}
+
+// ValueInt64 is like Value but converts the value to int64, NULL yields 0
+func (qb *QueryBuilder) ValueInt64(column string) (int64, error) {
+	record, key, err := qb.valueRecord(column)
+	if err != nil {
+		return 0, err
+	}
+	return record.GetInt64(key), nil
+}
+
+// ValueString is like Value but converts the value to string, NULL yields ""
+func (qb *QueryBuilder) ValueString(column string) (string, error) {
+	record, key, err := qb.valueRecord(column)
+	if err != nil {
+		return "", err
+	}
+	return record.GetString(key), nil
+}
+
+// ValueFloat is like Value but converts the value to float64, NULL yields 0
+func (qb *QueryBuilder) ValueFloat(column string) (float64, error) {
+	record, key, err := qb.valueRecord(column)
+	if err != nil {
+		return 0, err
+	}
+	return record.GetFloat(key), nil
+}
+
+// valueRecord 只查询 column 一列的第一行，返回记录和结果集中的列名
+func (qb *QueryBuilder) valueRecord(column string) (*Record, string, error) {
+	if qb.lastErr != nil {
+		return nil, "", qb.lastErr
+	}
+	if strings.TrimSpace(column) == "" {
+		return nil, "", fmt.Errorf("dbkit: Value requires a column")
+	}
+	cp := *qb
+	cp.selectSql = column
+	cp.selectArgs = nil
+	record, err := cp.QueryFirst()
+	if err != nil {
+		return nil, "", err
+	}
+	if record == nil {
+		return nil, "", ErrRecordNotFound
+	}
+	// 表达式或 table.column 在结果集中的列名由数据库决定，取第一列
+	keys := record.Keys()
+	if len(keys) == 0 {
+		return nil, "", ErrRecordNotFound
+	}
+	return record, keys[0], nil
+}
+
 // Paginate executes the query with pagination and returns a Page object
 func (qb *QueryBuilder) Paginate(pageNumber, pageSize int) (*Page[Record], error) {
+	page, err := qb.paginateRecords(pageNumber, pageSize)
+	if err != nil || page == nil || len(qb.with) == 0 {
+		return page, err
+	}
+	result := *page
+	if qb.cacheRepositoryName != "" {
+		result.List = cloneRecordSlice(page.List)
+	}
+	if err := qb.loadRelations(result.List); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// paginateRecords 分页查询，不加载 With 指定的关联
+func (qb *QueryBuilder) paginateRecords(pageNumber, pageSize int) (*Page[Record], error) {
 	if qb.lastErr != nil {
 		return nil, qb.lastErr
 	}
+	if qb.lock {
+		return nil, errPaginateLock
+	}
+	qb, err := qb.tenantScoped()
+	if err != nil {
+		return nil, err
+	}
+	if qb.snapshotColumn != "" {
+		return qb.paginateSnapshot(pageNumber, pageSize)
+	}
+	pageNumber, pageSize = normalizePageParams(pageNumber, pageSize)
 
-	// 构建完整的SQL语句（不包含LIMIT和OFFSET，因为分页逻辑会处理）
-	sql, args := qb.buildSelectSql()
-
-	// 移除LIMIT和OFFSET子句，因为Paginate会处理分页
-	sql = removeLimitOffset(sql)
+	// 构建不包含 LIMIT 和 OFFSET 的 SQL 语句，分页逻辑由 Paginate 处理
+	base := *qb
+	base.limit, base.offset = 0, 0
+	sql, args := base.buildSelectSql()
 
 	// 处理缓存
 	if qb.cacheRepositoryName != "" && qb.tx == nil {
@@ -868,6 +1854,10 @@ func (qb *QueryBuilder) Update(record *Record) (int64, error) {
 	if qb.lastErr != nil {
 		return 0, qb.lastErr
 	}
+	qb, err := qb.tenantScoped()
+	if err != nil {
+		return 0, err
+	}
 
 	whereSql := ""
 	if len(qb.whereSql) > 0 {
@@ -880,13 +1870,95 @@ func (qb *QueryBuilder) Update(record *Record) (int64, error) {
 	return qb.db.updateWithOptions(qb.table, record, whereSql, qb.skipTimestamps, qb.whereArgs...)
 }
 
+// Increment atomically adds by to column (UPDATE ... SET column = column + ?) for the rows matching the builder's Where.
+// 避免先查询再更新的竞态，适用于余额、库存、浏览数等计数器；配置了自动时间戳时同时更新 updated_at
+func (qb *QueryBuilder) Increment(column string, by interface{}) (int64, error) {
+	return qb.incrementColumns(map[string]interface{}{column: by}, "+")
+}
+
+// Decrement atomically subtracts by from column (UPDATE ... SET column = column - ?)
+func (qb *QueryBuilder) Decrement(column string, by interface{}) (int64, error) {
+	return qb.incrementColumns(map[string]interface{}{column: by}, "-")
+}
+
+// IncrementMap atomically adds several columns in one statement, deltas maps column -> amount (negative to decrease)
+func (qb *QueryBuilder) IncrementMap(deltas map[string]interface{}) (int64, error) {
+	return qb.incrementColumns(deltas, "+")
+}
+
+func (qb *QueryBuilder) incrementColumns(deltas map[string]interface{}, op string) (int64, error) {
+	if qb.lastErr != nil {
+		return 0, qb.lastErr
+	}
+	if qb.table == "" {
+		return 0, fmt.Errorf("dbkit: table name is required for Increment")
+	}
+	qb, err := qb.tenantScoped()
+	if err != nil {
+		return 0, err
+	}
+
+	whereSql := ""
+	if len(qb.whereSql) > 0 {
+		whereSql = strings.Join(qb.whereSql, " AND ")
+	}
+
+	if qb.tx != nil {
+		return qb.tx.increment(qb.table, deltas, op, whereSql, qb.skipTimestamps, qb.whereArgs...)
+	}
+	return qb.db.increment(qb.table, deltas, op, whereSql, qb.skipTimestamps, qb.whereArgs...)
+}
+
+// InsertFrom copies rows server-side with INSERT INTO <table> (columns) SELECT ..., using source as the SELECT.
+// source 的 Select/Where/Join/GroupBy/Limit 及其参数原样拼接到生成的语句中，columns 为空时省略列清单；
+// 语句在当前 builder 所属的数据库（或事务）上执行，source 应指向同一个数据库
+func (qb *QueryBuilder) InsertFrom(columns []string, source *QueryBuilder) (int64, error) {
+	if qb.lastErr != nil {
+		return 0, qb.lastErr
+	}
+	if source == nil {
+		return 0, fmt.Errorf("dbkit: InsertFrom requires a source query")
+	}
+	if source.lastErr != nil {
+		return 0, source.lastErr
+	}
+	source, err := source.tenantScoped()
+	if err != nil {
+		return 0, err
+	}
+	for _, col := range columns {
+		if err := validateIdentifier(col); err != nil {
+			return 0, err
+		}
+	}
+
+	selectSQL, args := source.buildSelectSql()
+	insertSQL := "INSERT INTO " + qb.table
+	if len(columns) > 0 {
+		insertSQL += " (" + joinStrings(columns) + ")"
+	}
+	insertSQL += " " + selectSQL
+
+	var result sql.Result
+	if qb.tx != nil {
+		result, err = qb.tx.Exec(insertSQL, args...)
+	} else {
+		result, err = qb.db.Exec(insertSQL, args...)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 // WithoutTimestamps disables auto timestamps for insert/update operations
 func (qb *QueryBuilder) WithoutTimestamps() *QueryBuilder {
 	qb.skipTimestamps = true
 	return qb
 }
 
-// Delete executes a delete query with the criteria in the builder
+// Delete executes a delete query with the criteria in the builder.
+// 设置了 Limit 时每次最多删除 Limit 行（按 OrderBy 的顺序），便于分批清理大表
 func (qb *QueryBuilder) Delete() (int64, error) {
 	if qb.lastErr != nil {
 		return 0, qb.lastErr
@@ -897,9 +1969,22 @@ func (qb *QueryBuilder) Delete() (int64, error) {
 	if len(qb.whereSql) == 0 {
 		return 0, fmt.Errorf("dbkit: Delete operation requires at least one Where condition for safety")
 	}
+	qb, err := qb.tenantScoped()
+	if err != nil {
+		return 0, err
+	}
 
 	whereSql := strings.Join(qb.whereSql, " AND ")
+	if qb.limit > 0 {
+		return qb.deleteLimited(whereSql)
+	}
 
+	if qb.ignoreSoftDelete {
+		if qb.tx != nil {
+			return qb.tx.ForceDelete(qb.table, whereSql, qb.whereArgs...)
+		}
+		return qb.db.ForceDelete(qb.table, whereSql, qb.whereArgs...)
+	}
 	if qb.tx != nil {
 		return qb.tx.Delete(qb.table, whereSql, qb.whereArgs...)
 	}
@@ -911,6 +1996,13 @@ func (qb *QueryBuilder) Count() (int64, error) {
 	if qb.lastErr != nil {
 		return 0, qb.lastErr
 	}
+	qb, err := qb.tenantScoped()
+	if err != nil {
+		return 0, err
+	}
+	if qb.rawSQL != "" {
+		return qb.countRaw()
+	}
 
 	// Collect all where conditions including soft delete filter
 	whereClauses := make([]string, 0, len(qb.whereSql)+1)
@@ -939,11 +2031,13 @@ func (qb *QueryBuilder) Count() (int64, error) {
 		}
 
 		// If not in cache, query and store
-		count, err := qb.db.Count(qb.table, whereSql, qb.whereArgs...)
-		if err == nil {
-			cache.CacheSet(qb.cacheRepositoryName, cacheKey, count, qb.cacheTTL)
-		}
-		return count, err
+		return loadOnce(qb.cacheRepositoryName, cacheKey, func() (int64, error) {
+			count, err := qb.db.Count(qb.table, whereSql, qb.whereArgs...)
+			if err == nil {
+				cache.CacheSet(qb.cacheRepositoryName, cacheKey, count, qb.cacheTTL)
+			}
+			return count, err
+		})
 	}
 
 	if qb.tx != nil {
@@ -952,6 +2046,55 @@ func (qb *QueryBuilder) Count() (int64, error) {
 	return qb.db.Count(qb.table, whereSql, qb.whereArgs...)
 }
 
+// countRaw 统计 RawBuilder 的结果行数：SELECT COUNT(*) FROM (querySQL) AS raw_query [WHERE ...]
+func (qb *QueryBuilder) countRaw() (int64, error) {
+	base := *qb
+	base.selectSql = "COUNT(*) AS total_count"
+	base.selectArgs = nil
+	base.selectSubqueries = nil
+	base.orderBy = ""
+	base.limit, base.offset = 0, 0
+	base.lock = false
+	sql, args := base.buildSelectSql()
+
+	query := func() (int64, error) {
+		var record *Record
+		var err error
+		if qb.tx != nil {
+			tx := qb.tx
+			if qb.timeout > 0 {
+				tx = tx.Timeout(qb.timeout)
+			}
+			record, err = tx.queryFirst(sql, args...)
+		} else {
+			db := &DB{dbMgr: qb.db.dbMgr, timeout: qb.timeout, debug: qb.db.debug, ctx: qb.db.ctx}
+			record, err = db.queryFirst(sql, args...)
+		}
+		if err != nil || record == nil {
+			return 0, err
+		}
+		return record.GetInt64("total_count"), nil
+	}
+
+	if qb.cacheRepositoryName != "" && qb.tx == nil {
+		cache := qb.getEffectiveCache()
+		cacheKey := qb.generateCacheKey(sql, args) + "_count"
+		if val, ok := cache.CacheGet(qb.cacheRepositoryName, cacheKey); ok {
+			if count, ok := val.(int64); ok {
+				return count, nil
+			}
+		}
+		return loadOnce(qb.cacheRepositoryName, cacheKey, func() (int64, error) {
+			count, err := query()
+			if err == nil {
+				cache.CacheSet(qb.cacheRepositoryName, cacheKey, count, qb.cacheTTL)
+			}
+			return count, err
+		})
+	}
+	return query()
+}
+
 // WithTrashed includes soft-deleted records in the query results
 func (qb *QueryBuilder) WithTrashed() *QueryBuilder {
 	qb.withTrashed = true
@@ -966,6 +2109,14 @@ func (qb *QueryBuilder) OnlyTrashed() *QueryBuilder {
 	return qb
 }
 
+// IgnoreSoftDelete ignores the table's soft delete configuration for this chain only:
+// queries are not filtered by the soft delete field and Delete performs a physical delete.
+// 与 WithTrashed 不同，WithTrashed 只影响查询，Delete 仍是软删除
+func (qb *QueryBuilder) IgnoreSoftDelete() *QueryBuilder {
+	qb.ignoreSoftDelete = true
+	return qb
+}
+
 // ForceDelete performs a physical delete, bypassing soft delete
 func (qb *QueryBuilder) ForceDelete() (int64, error) {
 	if qb.lastErr != nil {
@@ -977,8 +2128,17 @@ func (qb *QueryBuilder) ForceDelete() (int64, error) {
 	if len(qb.whereSql) == 0 {
 		return 0, fmt.Errorf("dbkit: ForceDelete operation requires at least one Where condition for safety")
 	}
+	qb, err := qb.tenantScoped()
+	if err != nil {
+		return 0, err
+	}
 
 	whereSql := strings.Join(qb.whereSql, " AND ")
+	if qb.limit > 0 {
+		base := *qb
+		base.ignoreSoftDelete = true
+		return base.deleteLimited(whereSql)
+	}
 
 	if qb.tx != nil {
 		return qb.tx.ForceDelete(qb.table, whereSql, qb.whereArgs...)
@@ -994,6 +2154,10 @@ func (qb *QueryBuilder) Restore() (int64, error) {
 	if qb.table == "" {
 		return 0, fmt.Errorf("dbkit: table name is required for Restore")
 	}
+	qb, err := qb.tenantScoped()
+	if err != nil {
+		return 0, err
+	}
 
 	whereSql := ""
 	if len(qb.whereSql) > 0 {
@@ -279,6 +279,7 @@ func InitRedisCache(provider CacheProvider) {
 	cacheMu.Lock()
 	defer cacheMu.Unlock()
 	redisCacheInstance = provider
+	applyRedisCodec(provider)
 }
 
 // GetLocalCacheInstance 获取本地缓存实例
@@ -0,0 +1,88 @@
+package dbkit
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SQLExpr is a SQL fragment with its bound arguments, e.g. *CaseExpr or *Subquery
+type SQLExpr interface {
+	ToSQL() (string, []interface{})
+}
+
+// CaseExpr builds a searched CASE expression:
+// (CASE WHEN cond THEN ? ... ELSE ? END) AS alias
+type CaseExpr struct {
+	whens   []caseWhen
+	elseVal interface{}
+	hasElse bool
+	alias   string
+	err     error
+}
+
+type caseWhen struct {
+	condition string
+	args      []interface{}
+	then      interface{}
+}
+
+// Case starts a CASE expression for use with QueryBuilder.SelectExpr
+func Case() *CaseExpr {
+	return &CaseExpr{}
+}
+
+// When adds a WHEN branch; condition may contain ? placeholders bound to args,
+// then 作为参数绑定（而不是拼接到 SQL 中）
+func (c *CaseExpr) When(condition string, then interface{}, args ...interface{}) *CaseExpr {
+	if strings.TrimSpace(condition) == "" {
+		c.err = fmt.Errorf("dbkit: CASE WHEN condition cannot be empty")
+		return c
+	}
+	c.whens = append(c.whens, caseWhen{condition: condition, args: args, then: then})
+	return c
+}
+
+// Else sets the ELSE value; without it the expression yields NULL when no branch matches
+func (c *CaseExpr) Else(value interface{}) *CaseExpr {
+	c.elseVal = value
+	c.hasElse = true
+	return c
+}
+
+// As sets the column alias
+func (c *CaseExpr) As(alias string) *CaseExpr {
+	if err := validateIdentifier(alias); err != nil {
+		c.err = fmt.Errorf("dbkit: invalid alias for CASE: %v", err)
+		return c
+	}
+	c.alias = alias
+	return c
+}
+
+// ToSQL returns the CASE expression and its arguments in placeholder order.
+// 整个表达式加括号，避免分页 COUNT 优化时丢弃 SELECT 列表导致参数错位
+func (c *CaseExpr) ToSQL() (string, []interface{}) {
+	if c.err != nil || len(c.whens) == 0 {
+		return "", nil
+	}
+	var sb strings.Builder
+	var args []interface{}
+	sb.WriteString("(CASE")
+	for _, w := range c.whens {
+		sb.WriteString(" WHEN ")
+		sb.WriteString(w.condition)
+		sb.WriteString(" THEN ?")
+		args = append(args, w.args...)
+		args = append(args, w.then)
+	}
+	if c.hasElse {
+		sb.WriteString(" ELSE ?")
+		args = append(args, c.elseVal)
+	}
+	sb.WriteString(" END)")
+	if c.alias != "" {
+		sb.WriteString(" AS ")
+		sb.WriteString(c.alias)
+	}
+	return sb.String(), args
+}
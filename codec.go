@@ -0,0 +1,90 @@
+package dbkit
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// Codec serializes cache values for remote caches such as Redis.
+// 本地缓存直接保存对象，不经过 Codec
+type Codec interface {
+	Name() string
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Name() string                               { return "json" }
+func (jsonCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+
+type gobCodec struct{}
+
+func (gobCodec) Name() string { return "gob" }
+
+func (gobCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gobCodec) Unmarshal(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+var (
+	// JSONCodec 默认编码，便于在 redis-cli 中直接查看，也方便其他语言共享缓存
+	JSONCodec Codec = jsonCodec{}
+	// GobCodec 保留 Go 类型信息（如 time.Time、int64），体积通常更小，但只能被 Go 程序读取
+	GobCodec Codec = gobCodec{}
+	// MsgPackCodec MessagePack 二进制编码，体积小且可被其他语言读取，解码后保留 int64、time.Time 等类型
+	MsgPackCodec Codec = msgpackCodec{}
+)
+
+var (
+	redisCodec   Codec = JSONCodec
+	redisCodecMu sync.RWMutex
+)
+
+func init() {
+	// Record 的字段值以 interface{} 保存，gob 需要预先注册其中可能出现的具体类型
+	gob.Register(time.Time{})
+	gob.Register([]interface{}{})
+	gob.Register(map[string]interface{}{})
+}
+
+// SetRedisCodec sets the serialization format used for values stored in the Redis cache.
+// 切换编码后线上已缓存的数据无法再被解析（读取时按未命中处理），建议切换前清空 Redis 缓存
+func SetRedisCodec(codec Codec) {
+	if codec == nil {
+		codec = JSONCodec
+	}
+	redisCodecMu.Lock()
+	redisCodec = codec
+	redisCodecMu.Unlock()
+
+	applyRedisCodec(GetRedisCacheInstance())
+}
+
+// GetRedisCodec returns the codec used by the Redis cache
+func GetRedisCodec() Codec {
+	redisCodecMu.RLock()
+	defer redisCodecMu.RUnlock()
+	return redisCodec
+}
+
+// applyRedisCodec 将当前编码的序列化函数下发给 Redis 缓存实现
+// Redis 实现位于独立模块，通过 SetMarshaler 方法约定对接，避免相互依赖
+func applyRedisCodec(provider CacheProvider) {
+	if m, ok := provider.(interface {
+		SetMarshaler(marshal func(v interface{}) ([]byte, error))
+	}); ok {
+		m.SetMarshaler(GetRedisCodec().Marshal)
+	}
+}
@@ -0,0 +1,648 @@
+package dbkit
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// msgpackCodec 按 MessagePack 规范编解码，不依赖第三方库。
+// 支持 nil、bool、整数、浮点数、string、[]byte、time.Time（时间戳扩展类型 -1）、slice、map、struct 以及 Record，
+// Record 编码为按字段顺序排列的 map，解码后保留字段顺序与 int64、time.Time 等原始类型
+type msgpackCodec struct{}
+
+func (msgpackCodec) Name() string { return "msgpack" }
+
+func (msgpackCodec) Marshal(v interface{}) ([]byte, error) {
+	e := &msgpackEncoder{}
+	if err := e.encode(reflect.ValueOf(v)); err != nil {
+		return nil, err
+	}
+	return e.buf, nil
+}
+
+func (msgpackCodec) Unmarshal(data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("dbkit: msgpack unmarshal requires a non-nil pointer, got %T", v)
+	}
+	d := &msgpackDecoder{data: data}
+	if err := d.decode(rv.Elem()); err != nil {
+		return err
+	}
+	if d.pos != len(d.data) {
+		return fmt.Errorf("dbkit: msgpack: %d trailing bytes", len(d.data)-d.pos)
+	}
+	return nil
+}
+
+var (
+	recordType = reflect.TypeOf(Record{})
+	timeType   = reflect.TypeOf(time.Time{})
+)
+
+// msgpackExtTimestamp 时间戳扩展类型
+const msgpackExtTimestamp = -1
+
+type msgpackEncoder struct {
+	buf []byte
+}
+
+func (e *msgpackEncoder) writeByte(b byte) { e.buf = append(e.buf, b) }
+
+func (e *msgpackEncoder) writeUint(prefix byte, v uint64, size int) {
+	e.buf = append(e.buf, prefix)
+	switch size {
+	case 1:
+		e.buf = append(e.buf, byte(v))
+	case 2:
+		e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(v))
+	case 4:
+		e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(v))
+	default:
+		e.buf = binary.BigEndian.AppendUint64(e.buf, v)
+	}
+}
+
+// writeHeader 写入 str / bin / array / map 的长度头，fix 为 fix 类型的前缀（0 表示无 fix 形式）
+func (e *msgpackEncoder) writeHeader(n int, fix byte, fixMax int, p8, p16, p32 byte) {
+	switch {
+	case fix != 0 && n <= fixMax:
+		e.writeByte(fix | byte(n))
+	case p8 != 0 && n <= math.MaxUint8:
+		e.writeUint(p8, uint64(n), 1)
+	case n <= math.MaxUint16:
+		e.writeUint(p16, uint64(n), 2)
+	default:
+		e.writeUint(p32, uint64(n), 4)
+	}
+}
+
+func (e *msgpackEncoder) encodeInt(v int64) {
+	switch {
+	case v >= 0:
+		e.encodeUint(uint64(v))
+	case v >= -32:
+		e.writeByte(byte(v))
+	case v >= math.MinInt8:
+		e.writeUint(0xd0, uint64(v), 1)
+	case v >= math.MinInt16:
+		e.writeUint(0xd1, uint64(v), 2)
+	case v >= math.MinInt32:
+		e.writeUint(0xd2, uint64(v), 4)
+	default:
+		e.writeUint(0xd3, uint64(v), 8)
+	}
+}
+
+func (e *msgpackEncoder) encodeUint(v uint64) {
+	switch {
+	case v <= 0x7f:
+		e.writeByte(byte(v))
+	case v <= math.MaxUint8:
+		e.writeUint(0xcc, v, 1)
+	case v <= math.MaxUint16:
+		e.writeUint(0xcd, v, 2)
+	case v <= math.MaxUint32:
+		e.writeUint(0xce, v, 4)
+	default:
+		e.writeUint(0xcf, v, 8)
+	}
+}
+
+func (e *msgpackEncoder) encodeString(s string) {
+	e.writeHeader(len(s), 0xa0, 31, 0xd9, 0xda, 0xdb)
+	e.buf = append(e.buf, s...)
+}
+
+func (e *msgpackEncoder) encodeTime(t time.Time) {
+	// 统一使用 12 字节形式：ext8、长度 12、类型 -1、纳秒 uint32、秒 int64
+	e.buf = append(e.buf, 0xc7, 12, byte(0xff))
+	e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(t.Nanosecond()))
+	e.buf = binary.BigEndian.AppendUint64(e.buf, uint64(t.Unix()))
+}
+
+func (e *msgpackEncoder) encodeRecord(r *Record) error {
+	keys := r.Keys()
+	values := r.ToMap()
+	e.writeHeader(len(keys), 0x80, 15, 0, 0xde, 0xdf)
+	for _, k := range keys {
+		e.encodeString(k)
+		if err := e.encode(reflect.ValueOf(values[k])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *msgpackEncoder) encode(v reflect.Value) error {
+	if !v.IsValid() {
+		e.writeByte(0xc0)
+		return nil
+	}
+	switch v.Type() {
+	case recordType:
+		if !v.CanAddr() {
+			// 非寻址的 Record 值先复制到新变量再取指针
+			cp := reflect.New(recordType)
+			cp.Elem().Set(v)
+			v = cp.Elem()
+		}
+		return e.encodeRecord(v.Addr().Interface().(*Record))
+	case timeType:
+		e.encodeTime(v.Interface().(time.Time))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			e.writeByte(0xc0)
+			return nil
+		}
+		return e.encode(v.Elem())
+	case reflect.Bool:
+		if v.Bool() {
+			e.writeByte(0xc3)
+		} else {
+			e.writeByte(0xc2)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		e.encodeInt(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		e.encodeUint(v.Uint())
+	case reflect.Float32:
+		e.writeUint(0xca, uint64(math.Float32bits(float32(v.Float()))), 4)
+	case reflect.Float64:
+		e.writeUint(0xcb, math.Float64bits(v.Float()), 8)
+	case reflect.String:
+		e.encodeString(v.String())
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			e.writeByte(0xc0)
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(b), v)
+			e.writeHeader(len(b), 0, 0, 0xc4, 0xc5, 0xc6)
+			e.buf = append(e.buf, b...)
+			return nil
+		}
+		e.writeHeader(v.Len(), 0x90, 15, 0, 0xdc, 0xdd)
+		for i := 0; i < v.Len(); i++ {
+			if err := e.encode(v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if v.IsNil() {
+			e.writeByte(0xc0)
+			return nil
+		}
+		keys := v.MapKeys()
+		// 键排序使编码结果稳定
+		sort.Slice(keys, func(i, j int) bool { return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface()) })
+		e.writeHeader(len(keys), 0x80, 15, 0, 0xde, 0xdf)
+		for _, k := range keys {
+			if err := e.encode(k); err != nil {
+				return err
+			}
+			if err := e.encode(v.MapIndex(k)); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		fields := msgpackFields(v.Type())
+		e.writeHeader(len(fields), 0x80, 15, 0, 0xde, 0xdf)
+		for _, f := range fields {
+			e.encodeString(f.name)
+			if err := e.encode(v.Field(f.index)); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("dbkit: msgpack: unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+type msgpackField struct {
+	name  string
+	index int
+}
+
+// msgpackFields 返回结构体的导出字段，字段名与 encoding/json 一致（json 标签优先，"-" 跳过）
+func msgpackFields(t reflect.Type) []msgpackField {
+	var fields []msgpackField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		name := f.Name
+		if tag := f.Tag.Get("json"); tag != "" {
+			tagName := strings.Split(tag, ",")[0]
+			if tagName == "-" {
+				continue
+			}
+			if tagName != "" {
+				name = tagName
+			}
+		}
+		fields = append(fields, msgpackField{name: name, index: i})
+	}
+	return fields
+}
+
+type msgpackDecoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *msgpackDecoder) read(n int) ([]byte, error) {
+	if n < 0 || d.pos+n > len(d.data) {
+		return nil, fmt.Errorf("dbkit: msgpack: unexpected end of data")
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+func (d *msgpackDecoder) peek() (byte, error) {
+	if d.pos >= len(d.data) {
+		return 0, fmt.Errorf("dbkit: msgpack: unexpected end of data")
+	}
+	return d.data[d.pos], nil
+}
+
+func (d *msgpackDecoder) readUint(size int) (uint64, error) {
+	b, err := d.read(size)
+	if err != nil {
+		return 0, err
+	}
+	switch size {
+	case 1:
+		return uint64(b[0]), nil
+	case 2:
+		return uint64(binary.BigEndian.Uint16(b)), nil
+	case 4:
+		return uint64(binary.BigEndian.Uint32(b)), nil
+	default:
+		return binary.BigEndian.Uint64(b), nil
+	}
+}
+
+// readLen 读取 map / array 的长度头
+func (d *msgpackDecoder) readLen(fixBase byte, p16, p32 byte) (int, error) {
+	c, err := d.peek()
+	if err != nil {
+		return 0, err
+	}
+	d.pos++
+	switch {
+	case c&0xf0 == fixBase:
+		return int(c & 0x0f), nil
+	case c == p16:
+		n, err := d.readUint(2)
+		return int(n), err
+	case c == p32:
+		n, err := d.readUint(4)
+		return int(n), err
+	}
+	d.pos--
+	return 0, fmt.Errorf("dbkit: msgpack: unexpected type byte 0x%02x", c)
+}
+
+// decodeInterface 解码为通用类型：map[string]interface{}、[]interface{}、int64、uint64（超出 int64 时）、
+// float32、float64、string、[]byte、bool、time.Time 或 nil
+func (d *msgpackDecoder) decodeInterface() (interface{}, error) {
+	c, err := d.peek()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case c <= 0x7f:
+		d.pos++
+		return int64(c), nil
+	case c >= 0xe0:
+		d.pos++
+		return int64(int8(c)), nil
+	case c >= 0xa0 && c <= 0xbf, c == 0xd9, c == 0xda, c == 0xdb:
+		b, err := d.readBytes()
+		return string(b), err
+	case c >= 0x90 && c <= 0x9f, c == 0xdc, c == 0xdd:
+		n, err := d.readLen(0x90, 0xdc, 0xdd)
+		if err != nil {
+			return nil, err
+		}
+		list := make([]interface{}, n)
+		for i := range list {
+			if list[i], err = d.decodeInterface(); err != nil {
+				return nil, err
+			}
+		}
+		return list, nil
+	case c >= 0x80 && c <= 0x8f, c == 0xde, c == 0xdf:
+		n, err := d.readLen(0x80, 0xde, 0xdf)
+		if err != nil {
+			return nil, err
+		}
+		m := make(map[string]interface{}, n)
+		for i := 0; i < n; i++ {
+			k, err := d.decodeInterface()
+			if err != nil {
+				return nil, err
+			}
+			v, err := d.decodeInterface()
+			if err != nil {
+				return nil, err
+			}
+			m[fmt.Sprint(k)] = v
+		}
+		return m, nil
+	}
+
+	d.pos++
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		d.pos--
+		b, err := d.readBytes()
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte(nil), b...), nil
+	case 0xca:
+		u, err := d.readUint(4)
+		return math.Float32frombits(uint32(u)), err
+	case 0xcb:
+		u, err := d.readUint(8)
+		return math.Float64frombits(u), err
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		u, err := d.readUint(1 << (c - 0xcc))
+		if u > math.MaxInt64 {
+			return u, err
+		}
+		return int64(u), err
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << (c - 0xd0)
+		u, err := d.readUint(size)
+		shift := uint(64 - 8*size)
+		return int64(u<<shift) >> shift, err
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8, 0xc7, 0xc8, 0xc9:
+		d.pos--
+		return d.readExt()
+	}
+	return nil, fmt.Errorf("dbkit: msgpack: unknown type byte 0x%02x", c)
+}
+
+// readBytes 读取 str 或 bin 的内容
+func (d *msgpackDecoder) readBytes() ([]byte, error) {
+	c, err := d.peek()
+	if err != nil {
+		return nil, err
+	}
+	d.pos++
+	var n uint64
+	switch {
+	case c >= 0xa0 && c <= 0xbf:
+		n = uint64(c & 0x1f)
+	case c == 0xd9 || c == 0xc4:
+		n, err = d.readUint(1)
+	case c == 0xda || c == 0xc5:
+		n, err = d.readUint(2)
+	case c == 0xdb || c == 0xc6:
+		n, err = d.readUint(4)
+	default:
+		d.pos--
+		return nil, fmt.Errorf("dbkit: msgpack: expected string or binary, got type byte 0x%02x", c)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return d.read(int(n))
+}
+
+// readExt 读取扩展类型，只支持时间戳
+func (d *msgpackDecoder) readExt() (interface{}, error) {
+	c, _ := d.peek()
+	d.pos++
+	var n uint64
+	var err error
+	switch c {
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		n = 1 << (c - 0xd4)
+	case 0xc7:
+		n, err = d.readUint(1)
+	case 0xc8:
+		n, err = d.readUint(2)
+	default:
+		n, err = d.readUint(4)
+	}
+	if err != nil {
+		return nil, err
+	}
+	head, err := d.read(1)
+	if err != nil {
+		return nil, err
+	}
+	body, err := d.read(int(n))
+	if err != nil {
+		return nil, err
+	}
+	if int8(head[0]) != msgpackExtTimestamp {
+		return nil, fmt.Errorf("dbkit: msgpack: unsupported extension type %d", int8(head[0]))
+	}
+	switch len(body) {
+	case 4:
+		return time.Unix(int64(binary.BigEndian.Uint32(body)), 0), nil
+	case 8:
+		v := binary.BigEndian.Uint64(body)
+		return time.Unix(int64(v&(1<<34-1)), int64(v>>34)), nil
+	case 12:
+		return time.Unix(int64(binary.BigEndian.Uint64(body[4:])), int64(binary.BigEndian.Uint32(body[:4]))), nil
+	}
+	return nil, fmt.Errorf("dbkit: msgpack: invalid timestamp length %d", len(body))
+}
+
+// decodeRecord 按编码时的字段顺序还原 Record
+func (d *msgpackDecoder) decodeRecord(r *Record) error {
+	n, err := d.readLen(0x80, 0xde, 0xdf)
+	if err != nil {
+		return err
+	}
+	columns := make(map[string]interface{}, n)
+	order := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		key, err := d.readBytes()
+		if err != nil {
+			return err
+		}
+		v, err := d.decodeInterface()
+		if err != nil {
+			return err
+		}
+		columns[string(key)] = v
+		order = append(order, string(key))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.columns = columns
+	r.rebuildKeys(order)
+	return nil
+}
+
+func (d *msgpackDecoder) decode(v reflect.Value) error {
+	if c, err := d.peek(); err != nil {
+		return err
+	} else if c == 0xc0 {
+		d.pos++
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+
+	switch v.Type() {
+	case recordType:
+		return d.decodeRecord(v.Addr().Interface().(*Record))
+	case timeType:
+		val, err := d.decodeInterface()
+		if err != nil {
+			return err
+		}
+		t, ok := val.(time.Time)
+		if !ok {
+			return fmt.Errorf("dbkit: msgpack: cannot decode %T into time.Time", val)
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return d.decode(v.Elem())
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b, err := d.readBytes()
+			if err != nil {
+				return err
+			}
+			v.SetBytes(append([]byte(nil), b...))
+			return nil
+		}
+		n, err := d.readLen(0x90, 0xdc, 0xdd)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.MakeSlice(v.Type(), n, n))
+		for i := 0; i < n; i++ {
+			if err := d.decode(v.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Map:
+		n, err := d.readLen(0x80, 0xde, 0xdf)
+		if err != nil {
+			return err
+		}
+		m := reflect.MakeMapWithSize(v.Type(), n)
+		for i := 0; i < n; i++ {
+			k := reflect.New(v.Type().Key()).Elem()
+			if err := d.decode(k); err != nil {
+				return err
+			}
+			val := reflect.New(v.Type().Elem()).Elem()
+			if err := d.decode(val); err != nil {
+				return err
+			}
+			m.SetMapIndex(k, val)
+		}
+		v.Set(m)
+		return nil
+	case reflect.Struct:
+		n, err := d.readLen(0x80, 0xde, 0xdf)
+		if err != nil {
+			return err
+		}
+		fields := msgpackFields(v.Type())
+		for i := 0; i < n; i++ {
+			key, err := d.readBytes()
+			if err != nil {
+				return err
+			}
+			target := -1
+			for _, f := range fields {
+				if strings.EqualFold(f.name, string(key)) {
+					target = f.index
+					break
+				}
+			}
+			if target < 0 {
+				// 未知字段跳过
+				if _, err := d.decodeInterface(); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := d.decode(v.Field(target)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	val, err := d.decodeInterface()
+	if err != nil {
+		return err
+	}
+	return setMsgpackScalar(v, val)
+}
+
+// setMsgpackScalar 把解码出的基本类型值赋给 v，数值类型之间按需转换
+func setMsgpackScalar(v reflect.Value, val interface{}) error {
+	rv := reflect.ValueOf(val)
+	switch v.Kind() {
+	case reflect.Interface:
+		if rv.Type().Implements(v.Type()) {
+			v.Set(rv)
+			return nil
+		}
+	case reflect.Bool, reflect.String:
+		if rv.Kind() == v.Kind() {
+			v.Set(rv.Convert(v.Type()))
+			return nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		switch rv.Kind() {
+		case reflect.Int64, reflect.Uint64, reflect.Float32, reflect.Float64:
+			v.Set(rv.Convert(v.Type()))
+			return nil
+		}
+	case reflect.Array:
+		if list, ok := val.([]interface{}); ok && len(list) == v.Len() {
+			for i, item := range list {
+				if err := setMsgpackScalar(v.Index(i), item); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("dbkit: msgpack: cannot decode %T into %s", val, v.Type())
+}
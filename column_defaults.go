@@ -0,0 +1,134 @@
+package dbkit
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// columnDefaultRegistry stores per-table column defaults applied on insert
+type columnDefaultRegistry struct {
+	defaults map[string]map[string]interface{} // table（小写）-> column -> 默认值
+	mu       sync.RWMutex
+}
+
+// newColumnDefaultRegistry creates a new column default registry
+func newColumnDefaultRegistry() *columnDefaultRegistry {
+	return &columnDefaultRegistry{
+		defaults: make(map[string]map[string]interface{}),
+	}
+}
+
+// set replaces the defaults for a table
+func (r *columnDefaultRegistry) set(table string, defaults map[string]interface{}) {
+	copied := make(map[string]interface{}, len(defaults))
+	for col, val := range defaults {
+		copied[col] = val
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.defaults[strings.ToLower(table)] = copied
+}
+
+// get returns the defaults for a table
+func (r *columnDefaultRegistry) get(table string) map[string]interface{} {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.defaults[strings.ToLower(table)]
+}
+
+// remove removes the defaults for a table
+func (r *columnDefaultRegistry) remove(table string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.defaults, strings.ToLower(table))
+}
+
+// --- Global Functions (for default database) ---
+
+// ConfigDefaults sets default column values for a table on the default database.
+// Insert、BatchInsert 时记录中没有的列会填入默认值，并写回调用方的 Record，插入后无需重新查询即可读到；
+// 列已存在（包括值为 nil）时保持不变。再次调用会替换该表之前的配置
+func ConfigDefaults(table string, defaults map[string]interface{}) {
+	db, err := defaultDB()
+	if err != nil {
+		return
+	}
+	db.ConfigDefaults(table, defaults)
+}
+
+// RemoveDefaults removes the default column values for a table
+func RemoveDefaults(table string) {
+	db, err := defaultDB()
+	if err != nil {
+		return
+	}
+	db.RemoveDefaults(table)
+}
+
+// --- DB Methods ---
+
+// ConfigDefaults sets default column values for a table
+func (db *DB) ConfigDefaults(table string, defaults map[string]interface{}) *DB {
+	if db.lastErr != nil || db.dbMgr == nil {
+		return db
+	}
+	if err := validateIdentifier(table); err != nil {
+		db.lastErr = err
+		return db
+	}
+	for col := range defaults {
+		if err := validateIdentifier(col); err != nil {
+			db.lastErr = fmt.Errorf("dbkit: invalid default column %s.%s: %v", table, col, err)
+			return db
+		}
+	}
+	db.dbMgr.setColumnDefaults(table, defaults)
+	return db
+}
+
+// RemoveDefaults removes the default column values for a table
+func (db *DB) RemoveDefaults(table string) *DB {
+	if db.lastErr != nil || db.dbMgr == nil {
+		return db
+	}
+	db.dbMgr.removeColumnDefaults(table)
+	return db
+}
+
+// --- dbManager Methods ---
+
+func (mgr *dbManager) setColumnDefaults(table string, defaults map[string]interface{}) {
+	if mgr.columnDefaults == nil {
+		mgr.columnDefaults = newColumnDefaultRegistry()
+	}
+	mgr.columnDefaults.set(table, defaults)
+}
+
+func (mgr *dbManager) removeColumnDefaults(table string) {
+	if mgr.columnDefaults == nil {
+		return
+	}
+	mgr.columnDefaults.remove(table)
+}
+
+// applyColumnDefaults 为记录中缺少的列填入默认值
+func (mgr *dbManager) applyColumnDefaults(table string, records ...*Record) {
+	if mgr.columnDefaults == nil {
+		return
+	}
+	defaults := mgr.columnDefaults.get(table)
+	if len(defaults) == 0 {
+		return
+	}
+	for _, record := range records {
+		if record == nil {
+			continue
+		}
+		for col, val := range defaults {
+			if !record.Has(col) {
+				record.Set(col, val)
+			}
+		}
+	}
+}
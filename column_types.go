@@ -0,0 +1,209 @@
+package dbkit
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ColumnKind declares the Go kind a column value should be bound as
+type ColumnKind int
+
+const (
+	ColumnInt   ColumnKind = iota + 1 // int64
+	ColumnFloat                       // float64
+	ColumnBool                        // bool
+	ColumnTime                        // time.Time
+)
+
+// String returns the name of the column kind
+func (k ColumnKind) String() string {
+	switch k {
+	case ColumnInt:
+		return "int"
+	case ColumnFloat:
+		return "float"
+	case ColumnBool:
+		return "bool"
+	case ColumnTime:
+		return "time"
+	}
+	return fmt.Sprintf("ColumnKind(%d)", int(k))
+}
+
+// columnTypeRegistry stores column type hints per database
+type columnTypeRegistry struct {
+	types map[string]map[string]ColumnKind // table -> column -> kind（均为小写）
+	mu    sync.RWMutex
+}
+
+// newColumnTypeRegistry creates a new column type registry
+func newColumnTypeRegistry() *columnTypeRegistry {
+	return &columnTypeRegistry{
+		types: make(map[string]map[string]ColumnKind),
+	}
+}
+
+// set replaces the column type hints for a table
+func (r *columnTypeRegistry) set(table string, types map[string]ColumnKind) {
+	kinds := make(map[string]ColumnKind, len(types))
+	for col, kind := range types {
+		kinds[strings.ToLower(col)] = kind
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.types[strings.ToLower(table)] = kinds
+}
+
+// get returns the column type hints for a table
+func (r *columnTypeRegistry) get(table string) map[string]ColumnKind {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.types[strings.ToLower(table)]
+}
+
+// remove removes the column type hints for a table
+func (r *columnTypeRegistry) remove(table string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.types, strings.ToLower(table))
+}
+
+// --- Global Functions (for default database) ---
+
+// ConfigColumnTypes declares column kinds for a table on the default database.
+// 插入、更新时这些列上的字符串值会在绑定前转换为声明的类型（如 Set("quantity", "1") 绑定为 int64 1），
+// 无法转换时返回错误而不是交给数据库报错。再次调用会替换该表之前的配置
+func ConfigColumnTypes(table string, types map[string]ColumnKind) {
+	db, err := defaultDB()
+	if err != nil {
+		return
+	}
+	db.ConfigColumnTypes(table, types)
+}
+
+// RemoveColumnTypes removes the column type hints for a table
+func RemoveColumnTypes(table string) {
+	db, err := defaultDB()
+	if err != nil {
+		return
+	}
+	db.RemoveColumnTypes(table)
+}
+
+// --- DB Methods ---
+
+// ConfigColumnTypes declares column kinds for a table
+func (db *DB) ConfigColumnTypes(table string, types map[string]ColumnKind) *DB {
+	if db.lastErr != nil || db.dbMgr == nil {
+		return db
+	}
+	for col, kind := range types {
+		if kind < ColumnInt || kind > ColumnTime {
+			db.lastErr = fmt.Errorf("dbkit: invalid column kind %v for %s.%s", kind, table, col)
+			return db
+		}
+	}
+	db.dbMgr.setColumnTypes(table, types)
+	return db
+}
+
+// RemoveColumnTypes removes the column type hints for a table
+func (db *DB) RemoveColumnTypes(table string) *DB {
+	if db.lastErr != nil || db.dbMgr == nil {
+		return db
+	}
+	db.dbMgr.removeColumnTypes(table)
+	return db
+}
+
+// --- dbManager Methods ---
+
+func (mgr *dbManager) setColumnTypes(table string, types map[string]ColumnKind) {
+	if mgr.columnTypes == nil {
+		mgr.columnTypes = newColumnTypeRegistry()
+	}
+	mgr.columnTypes.set(table, types)
+}
+
+func (mgr *dbManager) removeColumnTypes(table string) {
+	if mgr.columnTypes == nil {
+		return
+	}
+	mgr.columnTypes.remove(table)
+}
+
+// coerceColumnValues 按表的列类型配置原地转换 values 中的字符串值，columns 与 values 一一对应
+func (mgr *dbManager) coerceColumnValues(table string, columns []string, values []interface{}) error {
+	if mgr.columnTypes == nil {
+		return nil
+	}
+	kinds := mgr.columnTypes.get(table)
+	if len(kinds) == 0 {
+		return nil
+	}
+	for i, col := range columns {
+		kind, ok := kinds[strings.ToLower(col)]
+		if !ok {
+			continue
+		}
+		converted, err := coerceColumnValue(values[i], kind)
+		if err != nil {
+			return fmt.Errorf("dbkit: column %s.%s: %v", table, col, err)
+		}
+		values[i] = converted
+	}
+	return nil
+}
+
+// coerceRecords 返回列值已按类型配置转换的记录；无需转换时直接返回原切片，不修改调用方的记录
+func (mgr *dbManager) coerceRecords(table string, records []*Record) ([]*Record, error) {
+	if mgr.columnTypes == nil || len(mgr.columnTypes.get(table)) == 0 {
+		return records, nil
+	}
+	result := make([]*Record, len(records))
+	for i, record := range records {
+		if record == nil {
+			continue
+		}
+		clone := record.Clone()
+		columns, values := mgr.getOrderedColumns(clone)
+		if err := mgr.coerceColumnValues(table, columns, values); err != nil {
+			return nil, err
+		}
+		for j, col := range columns {
+			clone.columns[col] = values[j]
+		}
+		result[i] = clone
+	}
+	return result, nil
+}
+
+// coerceColumnValue 只转换字符串值，其他类型（包括 nil）原样绑定
+func coerceColumnValue(val interface{}, kind ColumnKind) (interface{}, error) {
+	s, ok := val.(string)
+	if !ok {
+		return val, nil
+	}
+	s = strings.TrimSpace(s)
+	var (
+		converted interface{}
+		err       error
+	)
+	switch kind {
+	case ColumnInt:
+		converted, err = toInt64(s)
+	case ColumnFloat:
+		converted, err = toFloat64(s)
+	case ColumnBool:
+		converted, err = toBool(s)
+	case ColumnTime:
+		converted, err = toTime(s)
+	default:
+		return val, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot convert %q to %s", val, kind)
+	}
+	return converted, nil
+}
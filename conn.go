@@ -0,0 +1,225 @@
+package dbkit
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+)
+
+// Conn is a single pinned connection taken from the pool.
+// 同一个 Conn 上的所有操作都在同一条物理连接上执行，适用于临时表、会话变量
+// （MySQL 用户变量、PostgreSQL SET）、LAST_INSERT_ID() 等依赖会话状态的场景。
+// 使用完毕必须调用 Close 归还连接。
+type Conn struct {
+	conn  *sql.Conn
+	dbMgr *dbManager
+	ctx   context.Context
+}
+
+// connExecutor 将 *sql.Conn 适配为 sqlExecutor，非 Context 方法统一使用 Conn 的 ctx
+type connExecutor struct {
+	ctx  context.Context
+	conn *sql.Conn
+}
+
+func (e *connExecutor) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return e.conn.QueryContext(e.ctx, query, args...)
+}
+
+func (e *connExecutor) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return e.conn.ExecContext(e.ctx, query, args...)
+}
+
+func (e *connExecutor) QueryRow(query string, args ...interface{}) *sql.Row {
+	return e.conn.QueryRowContext(e.ctx, query, args...)
+}
+
+func (e *connExecutor) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return e.conn.QueryContext(ctx, query, args...)
+}
+
+func (e *connExecutor) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return e.conn.ExecContext(ctx, query, args...)
+}
+
+func (e *connExecutor) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return e.conn.QueryRowContext(ctx, query, args...)
+}
+
+func (e *connExecutor) Prepare(query string) (*sql.Stmt, error) {
+	return e.conn.PrepareContext(e.ctx, query)
+}
+
+// GetConn takes a dedicated connection from the default database pool.
+// ctx 作用于获取连接以及该连接上执行的所有操作
+func GetConn(ctx context.Context) (*Conn, error) {
+	db, err := defaultDB()
+	if err != nil {
+		return nil, err
+	}
+	return db.Conn(ctx)
+}
+
+// Conn takes a dedicated connection from this database's pool
+func (db *DB) Conn(ctx context.Context) (*Conn, error) {
+	if db.lastErr != nil {
+		return nil, db.lastErr
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	sdb, err := db.dbMgr.getDB()
+	if err != nil {
+		return nil, err
+	}
+	var conn *sql.Conn
+	if db.dbMgr.config.AcquireTimeout > 0 {
+		conn, err = db.dbMgr.acquireConn(ctx, sdb)
+	} else {
+		conn, err = sdb.Conn(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Conn{conn: conn, dbMgr: db.dbMgr, ctx: ctx}, nil
+}
+
+func (c *Conn) executor() sqlExecutor {
+	return wrapMiddleware(c.ctx, &connExecutor{ctx: c.ctx, conn: c.conn})
+}
+
+// Close returns the connection to the pool
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
+
+func (c *Conn) Query(querySQL string, args ...interface{}) ([]Record, error) {
+	return c.dbMgr.queryWithContext(c.ctx, c.executor(), querySQL, args...)
+}
+
+func (c *Conn) QueryFirst(querySQL string, args ...interface{}) (*Record, error) {
+	return queryFirstResult(c.dbMgr.queryFirstWithContext(c.ctx, c.executor(), querySQL, args...))
+}
+
+func (c *Conn) QueryMap(querySQL string, args ...interface{}) ([]map[string]interface{}, error) {
+	return c.dbMgr.queryMapWithContext(c.ctx, c.executor(), querySQL, args...)
+}
+
+func (c *Conn) QueryMulti(querySQL string, args ...interface{}) ([][]*Record, error) {
+	return c.dbMgr.queryMultiWithContext(c.ctx, c.executor(), querySQL, args...)
+}
+
+func (c *Conn) Exec(querySQL string, args ...interface{}) (sql.Result, error) {
+	return c.dbMgr.execWithContext(c.ctx, c.executor(), querySQL, args...)
+}
+
+func (c *Conn) Save(table string, record *Record, opts ...UpsertOptions) (int64, error) {
+	return c.dbMgr.save(c.executor(), table, record, upsertOptions(opts))
+}
+
+func (c *Conn) Insert(table string, record *Record) (int64, error) {
+	return c.dbMgr.insert(c.executor(), table, record)
+}
+
+func (c *Conn) Update(table string, record *Record, whereSql string, whereArgs ...interface{}) (int64, error) {
+	return c.dbMgr.update(c.executor(), table, record, whereSql, whereArgs...)
+}
+
+func (c *Conn) Delete(table string, whereSql string, whereArgs ...interface{}) (int64, error) {
+	return c.dbMgr.delete(c.executor(), table, whereSql, whereArgs...)
+}
+
+func (c *Conn) Count(table string, whereSql string, whereArgs ...interface{}) (int64, error) {
+	return c.dbMgr.count(c.executor(), table, whereSql, whereArgs...)
+}
+
+// BeginTransaction starts a transaction on this connection; the returned Tx
+// shares the connection's session state (temp tables, session variables)
+func (c *Conn) BeginTransaction() (*Tx, error) {
+	tx, err := c.conn.BeginTx(c.ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{tx: tx, dbMgr: c.dbMgr}, nil
+}
+
+// SessionConn is a newly established physical connection passed to Config.OnConnect
+type SessionConn interface {
+	// Exec 在该连接上执行一条不带参数的语句
+	Exec(ctx context.Context, query string) error
+}
+
+// driverSessionConn 将 driver.Conn 适配为 SessionConn
+type driverSessionConn struct {
+	conn driver.Conn
+}
+
+func (c driverSessionConn) Exec(ctx context.Context, query string) error {
+	if execer, ok := c.conn.(driver.ExecerContext); ok {
+		_, err := execer.ExecContext(ctx, query, nil)
+		if err != driver.ErrSkip {
+			return err
+		}
+	}
+	stmt, err := c.conn.Prepare(query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	if execer, ok := stmt.(driver.StmtExecContext); ok {
+		_, err = execer.ExecContext(ctx, nil)
+		return err
+	}
+	_, err = stmt.Exec(nil)
+	return err
+}
+
+// onConnectConnector 包装驱动的 Connector，在每个新连接建立后执行初始化函数
+type onConnectConnector struct {
+	base      driver.Connector
+	onConnect func(ctx context.Context, conn SessionConn) error
+}
+
+func newOnConnectConnector(drv driver.Driver, dsn string, onConnect func(ctx context.Context, conn SessionConn) error) (driver.Connector, error) {
+	var base driver.Connector
+	if dc, ok := drv.(driver.DriverContext); ok {
+		c, err := dc.OpenConnector(dsn)
+		if err != nil {
+			return nil, err
+		}
+		base = c
+	} else {
+		base = dsnConnector{dsn: dsn, drv: drv}
+	}
+	return &onConnectConnector{base: base, onConnect: onConnect}, nil
+}
+
+func (c *onConnectConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.base.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.onConnect(ctx, driverSessionConn{conn: conn}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+func (c *onConnectConnector) Driver() driver.Driver {
+	return c.base.Driver()
+}
+
+// dsnConnector 为未实现 driver.DriverContext 的驱动提供 Connector
+type dsnConnector struct {
+	dsn string
+	drv driver.Driver
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.drv.Open(c.dsn)
+}
+
+func (c dsnConnector) Driver() driver.Driver {
+	return c.drv
+}
@@ -1,6 +1,7 @@
 package dbkit
 
 import (
+	"fmt"
 	"sync"
 	"time"
 )
@@ -39,8 +40,106 @@ var (
 	// globalCheckMu 全局检查锁，确保同时只有一个数据库在进行连接检查
 	// 避免多个数据库同时 Ping 造成网络拥塞
 	globalCheckMu sync.Mutex
+
+	// connectionHooks 存储连接状态变化的回调
+	connectionHooks   = make(map[string]*connectionHookSet) // 数据库名 -> 回调
+	connectionHooksMu sync.RWMutex
 )
 
+// connectionHookSet 单个数据库的连接状态回调
+type connectionHookSet struct {
+	lost     []func(err error)
+	restored []func()
+}
+
+// OnConnectionLost registers a callback invoked when the health monitor detects that the database became unreachable
+// 回调在监控 goroutine 中同步执行，耗时操作请自行启动 goroutine
+func OnConnectionLost(dbName string, fn func(err error)) {
+	if fn == nil {
+		return
+	}
+	connectionHooksMu.Lock()
+	defer connectionHooksMu.Unlock()
+	hooks := connectionHooks[dbName]
+	if hooks == nil {
+		hooks = &connectionHookSet{}
+		connectionHooks[dbName] = hooks
+	}
+	hooks.lost = append(hooks.lost, fn)
+}
+
+// OnConnectionRestored registers a callback invoked when the database becomes reachable again
+func OnConnectionRestored(dbName string, fn func()) {
+	if fn == nil {
+		return
+	}
+	connectionHooksMu.Lock()
+	defer connectionHooksMu.Unlock()
+	hooks := connectionHooks[dbName]
+	if hooks == nil {
+		hooks = &connectionHookSet{}
+		connectionHooks[dbName] = hooks
+	}
+	hooks.restored = append(hooks.restored, fn)
+}
+
+// notifyConnectionHooks 在连接状态变化时调用已注册的回调，回调 panic 不影响监控器
+func notifyConnectionHooks(dbName string, healthy bool, err error) {
+	connectionHooksMu.RLock()
+	hooks := connectionHooks[dbName]
+	var lost []func(error)
+	var restored []func()
+	if hooks != nil {
+		lost = append(lost, hooks.lost...)
+		restored = append(restored, hooks.restored...)
+	}
+	connectionHooksMu.RUnlock()
+
+	defer func() {
+		if r := recover(); r != nil {
+			LogError("连接状态回调发生 panic", map[string]interface{}{
+				"database": dbName,
+				"panic":    fmt.Sprintf("%v", r),
+			})
+		}
+	}()
+
+	if healthy {
+		for _, fn := range restored {
+			fn()
+		}
+	} else {
+		for _, fn := range lost {
+			fn(err)
+		}
+	}
+}
+
+// EnableHealthMonitor enables (or restarts) the background health pinger of the named database with the given interval
+// interval <= 0 表示停止监控；连接故障期间的检查间隔取 MonitorErrorInterval 与 interval 中较小者
+func EnableHealthMonitor(dbName string, interval time.Duration) error {
+	mgr := GetDatabase(dbName)
+	if mgr == nil {
+		return fmt.Errorf("dbkit: database '%s' not found", dbName)
+	}
+
+	cleanupMonitor(dbName)
+
+	mgr.mu.Lock()
+	mgr.config.MonitorNormalInterval = interval
+	if interval > 0 && (mgr.config.MonitorErrorInterval <= 0 || mgr.config.MonitorErrorInterval > interval) {
+		mgr.config.MonitorErrorInterval = interval
+	}
+	initialized := mgr.db != nil
+	mgr.mu.Unlock()
+
+	// 未初始化的数据库会在 initDB 时按配置启动监控
+	if interval <= 0 || !initialized {
+		return nil
+	}
+	return mgr.startConnectionMonitoring()
+}
+
 // Stop 停止连接监控器
 func (cm *ConnectionMonitor) Stop() {
 	if cm == nil {
@@ -72,14 +171,14 @@ func (cm *ConnectionMonitor) checkConnection() bool {
 	// 使用全局锁确保同时只有一个数据库在进行连接检查
 	// 避免多个数据库同时 Ping 造成网络拥塞
 	globalCheckMu.Lock()
-	defer globalCheckMu.Unlock()
 
 	// 使用简单的 Ping 操作检查连接
 	err := cm.pinger.Ping()
 	isHealthy := err == nil
 
 	// 只在状态变化时记录日志
-	if cm.lastHealthy != isHealthy {
+	changed := cm.lastHealthy != isHealthy
+	if changed {
 		if isHealthy {
 			LogConnectionRecovered(cm.dbName)
 		} else {
@@ -87,6 +186,12 @@ func (cm *ConnectionMonitor) checkConnection() bool {
 		}
 		cm.lastHealthy = isHealthy
 	}
+	globalCheckMu.Unlock()
+
+	// 释放全局锁后再执行回调，避免回调阻塞其他数据库的检查
+	if changed {
+		notifyConnectionHooks(cm.dbName, isHealthy, err)
+	}
 
 	return isHealthy
 }
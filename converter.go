@@ -18,6 +18,7 @@ type structFieldInfo struct {
 	fieldType  reflect.Type // 字段类型
 	fieldKind  reflect.Kind // 字段种类
 	canSet     bool         // 是否可设置（可导出）
+	validate   string       // validate 标签中的校验规则
 }
 
 // structCacheInfo 存储整个结构体的缓存信息
@@ -76,6 +77,7 @@ func getStructCacheInfo(structType reflect.Type) *structCacheInfo {
 			fieldType:  field.Type,
 			fieldKind:  field.Type.Kind(),
 			canSet:     field.IsExported(), // Go 1.17+ 使用 IsExported 判断是否可导出
+			validate:   field.Tag.Get("validate"),
 		})
 	}
 
@@ -216,6 +218,12 @@ func setFieldValue(field reflect.Value, value interface{}) error {
 		return nil
 	}
 
+	// []byte 字段：非二进制列（如 TEXT）扫描为字符串，按 GetBytes 的规则转换
+	if field.Type() == reflect.TypeOf([]byte(nil)) {
+		field.SetBytes(toBytes(value))
+		return nil
+	}
+
 	// Advanced conversions
 	switch field.Kind() {
 	case reflect.String:
@@ -406,6 +414,9 @@ func toTime(v interface{}) (time.Time, error) {
 				return t, nil
 			}
 		}
+	case []byte:
+		// MySQL 未开启 parseTime 时时间列以 []byte 返回
+		return toTime(string(val))
 	case int64:
 		return time.Unix(val, 0), nil
 	}
@@ -453,3 +464,37 @@ func ToStructs(records []Record, dest interface{}) error {
 
 	return nil
 }
+
+// RecordTo allocates a T and fills it from the record using the same column mapping as ToStruct.
+// T 可以是结构体或结构体指针（此时分配新的结构体），转换失败时返回 T 的零值和错误
+func RecordTo[T any](r *Record) (T, error) {
+	var zero T
+	var result T
+	val := reflect.ValueOf(&result).Elem()
+	target := val
+	if val.Kind() == reflect.Ptr {
+		target = reflect.New(val.Type().Elem())
+		val.Set(target)
+		target = target.Elem()
+	}
+	if target.Kind() != reflect.Struct {
+		return zero, fmt.Errorf("dbkit: RecordTo type %s must be a struct or pointer to struct", val.Type())
+	}
+	if err := ToStruct(r, target.Addr().Interface()); err != nil {
+		return zero, err
+	}
+	return result, nil
+}
+
+// RecordsTo converts records to a []T with RecordTo, 任一条失败时返回 nil 和包含下标的错误
+func RecordsTo[T any](records []*Record) ([]T, error) {
+	list := make([]T, 0, len(records))
+	for i, r := range records {
+		item, err := RecordTo[T](r)
+		if err != nil {
+			return nil, fmt.Errorf("dbkit: record %d: %w", i, err)
+		}
+		list = append(list, item)
+	}
+	return list, nil
+}
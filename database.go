@@ -3,6 +3,8 @@ package dbkit
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"errors"
 	"fmt"
 	"reflect"
 	"regexp"
@@ -60,10 +62,25 @@ type Config struct {
 	MaxIdle         int           // Maximum number of idle connections
 	ConnMaxLifetime time.Duration // Maximum connection lifetime
 	QueryTimeout    time.Duration // Default query timeout (0 means no timeout)
+	AcquireTimeout  time.Duration // Max wait for a pooled connection in Query/Exec (0 means wait indefinitely); when set, the prepared statement cache is not used
 
 	// 连接监控配置（新增）
 	MonitorNormalInterval time.Duration // 正常检查间隔（默认60秒，0表示禁用监控）
 	MonitorErrorInterval  time.Duration // 故障检查间隔（默认10秒）
+
+	// 单条语句最大绑定参数数量（0 表示使用方言默认值，-1 表示不检查）
+	MaxParams int
+
+	// Oracle 11g 及更早版本不支持 OFFSET ... FETCH，开启后分页与 QueryBuilder.Offset 改用 ROWNUM 包装。
+	// 未开启时建立连接池时会检测服务器版本，旧版本或无法检测版本时自动使用 ROWNUM，通常无需设置
+	OracleLegacyPaging bool
+
+	// OnConnect 在连接池每次新建物理连接后调用，用于执行会话初始化语句（如 SET / PRAGMA）
+	// 返回错误时该连接会被关闭并丢弃
+	OnConnect func(ctx context.Context, conn SessionConn) error
+
+	// WarmUpOnOpen 为 true 时，打开连接池后立即建立 MaxIdle 个连接，避免首批查询的建连延迟
+	WarmUpOnOpen bool
 }
 
 // SupportedDrivers returns a list of all supported database drivers
@@ -87,9 +104,12 @@ type DB struct {
 	lastErr             error
 	cacheRepositoryName string
 	cacheTTL            time.Duration
-	timeout             time.Duration // Query timeout for this instance
-	cacheProvider       CacheProvider // 指定的缓存提供者（nil 表示使用默认缓存）
-	countCacheTTL       time.Duration // 分页计数缓存时间（-1 表示不使用，0 表示不缓存，>0 表示使用指定时间）
+	timeout             time.Duration   // Query timeout for this instance
+	cacheProvider       CacheProvider   // 指定的缓存提供者（nil 表示使用默认缓存）
+	countCacheTTL       time.Duration   // 分页计数缓存时间（-1 表示不使用，0 表示不缓存，>0 表示使用指定时间）
+	debug               bool            // 记录本实例执行的 SQL（QueryBuilder.Debug）
+	ctx                 context.Context // WithContext 设置的上下文，语句与事务从它派生
+	skipTenant          bool            // 租户条件已由 QueryBuilder 处理或 IgnoreTenantScope，写入时不再填充、追加
 }
 
 // GetConfig returns the database configuration
@@ -113,11 +133,20 @@ func (db *DB) getTimeout() time.Duration {
 
 // getContext returns a context with timeout if configured
 func (db *DB) getContext() (context.Context, context.CancelFunc) {
+	parent := db.parentContext()
 	timeout := db.getTimeout()
 	if timeout > 0 {
-		return context.WithTimeout(context.Background(), timeout)
+		return context.WithTimeout(parent, timeout)
 	}
-	return context.Background(), func() {}
+	return parent, func() {}
+}
+
+// parentContext returns the context set by WithContext, or context.Background()
+func (db *DB) parentContext() context.Context {
+	if db.ctx != nil {
+		return db.ctx
+	}
+	return context.Background()
 }
 
 // getEffectiveCache 获取当前有效的缓存提供者
@@ -135,9 +164,15 @@ type Tx struct {
 	dbMgr               *dbManager
 	cacheRepositoryName string
 	cacheTTL            time.Duration
-	timeout             time.Duration // Query timeout for this transaction
-	cacheProvider       CacheProvider // 指定的缓存提供者（nil 表示使用默认缓存）
-	countCacheTTL       time.Duration // 分页计数缓存时间（-1 表示不使用，0 表示不缓存，>0 表示使用指定时间）
+	timeout             time.Duration   // Query timeout for this transaction
+	cacheProvider       CacheProvider   // 指定的缓存提供者（nil 表示使用默认缓存）
+	countCacheTTL       time.Duration   // 分页计数缓存时间（-1 表示不使用，0 表示不缓存，>0 表示使用指定时间）
+	ctx                 context.Context // 整个事务的上下文（TransactionTimeout 设置），语句超时在其基础上派生
+	stateMu             sync.Mutex      // 保护 rollbackOnly / rollbackCause
+	rollbackOnly        bool            // 标记为只能回滚，Commit 时改为执行回滚
+	rollbackCause       error           // MarkRollbackOnly 时记录的首个原因
+	debug               bool            // 记录本实例执行的 SQL（QueryBuilder.Debug）
+	skipTenant          bool            // 租户条件已由 QueryBuilder 处理或 IgnoreTenantScope，写入时不再填充、追加
 }
 
 // getEffectiveCache 获取当前有效的缓存提供者
@@ -175,10 +210,20 @@ type dbManager struct {
 	softDeletes     *softDeleteRegistry     // Soft delete configurations
 	timestamps      *timestampRegistry      // Auto timestamp configurations
 	optimisticLocks *optimisticLockRegistry // Optimistic lock configurations
+	columnTypes     *columnTypeRegistry     // Column type hints for value coercion
+	columnDefaults  *columnDefaultRegistry  // ConfigDefaults 设置的插入默认值
+	tableCaches     map[string][]string     // BindTableCache 绑定的查询缓存仓库，DDL 后清空
+	relations       *relationRegistry       // ConfigRelation 配置的关联，供 With 预加载
 	// Feature flags
-	enableTimestampCheck      bool // Enable auto timestamp check in Update (default: false)
-	enableOptimisticLockCheck bool // Enable optimistic lock check in Update (default: false)
-	enableSoftDeleteCheck     bool // Enable soft delete check in queries (default: false)
+	enableTimestampCheck      bool           // Enable auto timestamp check in Update (default: false)
+	enableOptimisticLockCheck bool           // Enable optimistic lock check in Update (default: false)
+	enableSoftDeleteCheck     bool           // Enable soft delete check in queries (default: false)
+	tenantColumn              string         // EnableTenantScope 设置的租户列，为空表示未启用
+	timeLocation              *time.Location // 时间参数绑定与结果解释使用的时区（nil 表示不转换）
+	timestampsUTC             bool           // 自动填充的时间戳使用 UTC 时间
+	sqlitePragmas             []string       // ConfigSQLite 设置的 PRAGMA 语句，在每个新连接上执行
+	windowFunctions           *bool          // 是否支持窗口函数，建立连接池时检测，nil 表示未检测
+	legacyPagingDetected      *bool          // Oracle / SQL Server 是否为不支持 OFFSET ... FETCH 的旧版本，建立连接池时检测，nil 表示未检测
 
 	// 连接监控相关（默认启用）
 	monitor      *ConnectionMonitor // 连接监控器实例
@@ -278,11 +323,12 @@ func Use(dbname string) *DB {
 func UseWithError(dbname string) (*DB, error) {
 	multiMgr.mu.RLock()
 	dbMgr, exists := multiMgr.databases[dbname]
-	multiMgr.mu.RUnlock()
-
 	if !exists {
-		return nil, fmt.Errorf("database '%s' not found", dbname)
+		err := unknownDatabaseError(dbname)
+		multiMgr.mu.RUnlock()
+		return nil, err
 	}
+	multiMgr.mu.RUnlock()
 
 	return &DB{dbMgr: dbMgr}, nil
 }
@@ -290,6 +336,15 @@ func UseWithError(dbname string) (*DB, error) {
 var (
 	// ErrNotInitialized is returned when an operation is performed on an uninitialized database
 	ErrNotInitialized = fmt.Errorf("dbkit: database not initialized. Please call dbkit.OpenDatabase() before using dbkit operations")
+	// ErrPoolTimeout is returned when no connection could be acquired from the pool within Config.AcquireTimeout
+	ErrPoolTimeout = errors.New("dbkit: timed out waiting for a connection from the pool")
+	// ErrRecordNotFound is returned when a single-row struct query matches no rows
+	ErrRecordNotFound = errors.New("dbkit: no record found")
+	// ErrNoRows is the canonical "no rows" error, returned by QueryFirst / FindFirst when SetQueryFirstNotFoundError(true).
+	// 与 ErrRecordNotFound 是同一个值，errors.Is(err, dbkit.ErrNoRows) 可统一判断各单行查询的未找到错误
+	ErrNoRows = ErrRecordNotFound
+	// ErrTxRollbackOnly is returned by Commit when the transaction was marked rollback-only
+	ErrTxRollbackOnly = errors.New("dbkit: transaction marked rollback-only, rolled back instead of commit")
 )
 
 // defaultDB returns the default DB object (first registered database or single database mode)
@@ -387,24 +442,45 @@ func isStmtInvalidError(err error) bool {
 }
 
 func (mgr *dbManager) query(executor sqlExecutor, querySQL string, args ...interface{}) ([]Record, error) {
-	return mgr.queryWithContext(context.Background(), executor, querySQL, args...)
+	return mgr.queryWithContext(executorContext(executor), executor, querySQL, args...)
 }
 
 func (mgr *dbManager) queryWithContext(ctx context.Context, executor sqlExecutor, querySQL string, args ...interface{}) ([]Record, error) {
+	if err := checkPlaceholderStyle(querySQL, mgr.config.Driver); err != nil {
+		return nil, err
+	}
 	querySQL, args = mgr.prepareQuerySQL(querySQL, args...)
+	executor, querySQL, args, err := runMiddlewares(ctx, executor, OpQuery, querySQL, args)
+	if err != nil {
+		return nil, err
+	}
+	if err := mgr.checkParamLimit(len(args)); err != nil {
+		return nil, err
+	}
+	// Debug 包装会挡住下面的 *sql.DB 判断，先拆开，日志由 debug.log 补记
+	executor, debug := splitDebugExecutor(executor)
 	start := time.Now()
 
 	var rows *sql.Rows
-	var err error
 
 	// 只有当 executor 是 *sql.DB 时才使用预编译语句缓存
 	// 事务（*sql.Tx）不使用缓存，因为事务有自己的生命周期
-	if db, ok := executor.(*sql.DB); ok && db == mgr.db {
+	if db, ok := executor.(*sql.DB); ok && db == mgr.db && mgr.config.AcquireTimeout > 0 {
+		// 配置了获取连接超时：先在限定时间内取得连接，再在该连接上执行。
+		// database/sql 的预编译语句无法指定在哪条连接上执行，因此这里不使用语句缓存
+		conn, connErr := mgr.acquireConn(ctx, db)
+		if connErr != nil {
+			debug.log(start, querySQL, args, connErr)
+			return nil, mgr.logTrace(start, querySQL, args, connErr)
+		}
+		defer conn.Close()
+		rows, err = conn.QueryContext(ctx, querySQL, args...)
+	} else if db, ok := executor.(*sql.DB); ok && db == mgr.db {
 		// 使用缓存的预编译语句
 		stmt, fromCache, stmtErr := mgr.getOrPrepareStmt(querySQL)
 		if stmtErr != nil {
-			mgr.logTrace(start, querySQL, args, stmtErr)
-			return nil, stmtErr
+			debug.log(start, querySQL, args, stmtErr)
+			return nil, mgr.logTrace(start, querySQL, args, stmtErr)
 		}
 
 		// 执行查询（使用 context）
@@ -426,14 +502,15 @@ func (mgr *dbManager) queryWithContext(ctx context.Context, executor sqlExecutor
 		}
 	}
 
-	mgr.logTrace(start, querySQL, args, err)
+	debug.log(start, querySQL, args, err)
+	err = mgr.logTrace(start, querySQL, args, err)
 
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	results, err := scanRecords(rows, mgr.config.Driver)
+	results, err := scanRecords(rows, mgr.config.Driver, mgr.getTimeLocation())
 	if err != nil {
 		return nil, err
 	}
@@ -441,7 +518,7 @@ func (mgr *dbManager) queryWithContext(ctx context.Context, executor sqlExecutor
 }
 
 func (mgr *dbManager) queryFirst(executor sqlExecutor, querySQL string, args ...interface{}) (*Record, error) {
-	return mgr.queryFirstWithContext(context.Background(), executor, querySQL, args...)
+	return mgr.queryFirstWithContext(executorContext(executor), executor, querySQL, args...)
 }
 
 func (mgr *dbManager) queryFirstWithContext(ctx context.Context, executor sqlExecutor, querySQL string, args ...interface{}) (*Record, error) {
@@ -450,7 +527,7 @@ func (mgr *dbManager) queryFirstWithContext(ctx context.Context, executor sqlExe
 }
 
 func (mgr *dbManager) queryFirstInternal(executor sqlExecutor, querySQL string, args ...interface{}) (*Record, error) {
-	return mgr.queryFirstInternalWithContext(context.Background(), executor, querySQL, args...)
+	return mgr.queryFirstInternalWithContext(executorContext(executor), executor, querySQL, args...)
 }
 
 func (mgr *dbManager) queryFirstInternalWithContext(ctx context.Context, executor sqlExecutor, querySQL string, args ...interface{}) (*Record, error) {
@@ -465,23 +542,43 @@ func (mgr *dbManager) queryFirstInternalWithContext(ctx context.Context, executo
 }
 
 func (mgr *dbManager) queryMap(executor sqlExecutor, querySQL string, args ...interface{}) ([]map[string]interface{}, error) {
-	return mgr.queryMapWithContext(context.Background(), executor, querySQL, args...)
+	return mgr.queryMapWithContext(executorContext(executor), executor, querySQL, args...)
 }
 
 func (mgr *dbManager) queryMapWithContext(ctx context.Context, executor sqlExecutor, querySQL string, args ...interface{}) ([]map[string]interface{}, error) {
+	if err := checkPlaceholderStyle(querySQL, mgr.config.Driver); err != nil {
+		return nil, err
+	}
 	querySQL, args = mgr.prepareQuerySQL(querySQL, args...)
+	executor, querySQL, args, err := runMiddlewares(ctx, executor, OpQuery, querySQL, args)
+	if err != nil {
+		return nil, err
+	}
+	if err := mgr.checkParamLimit(len(args)); err != nil {
+		return nil, err
+	}
+	// Debug 包装会挡住下面的 *sql.DB 判断，先拆开，日志由 debug.log 补记
+	executor, debug := splitDebugExecutor(executor)
 	start := time.Now()
 
 	var rows *sql.Rows
-	var err error
 
 	// 只有当 executor 是 *sql.DB 时才使用预编译语句缓存
-	if db, ok := executor.(*sql.DB); ok && db == mgr.db {
+	if db, ok := executor.(*sql.DB); ok && db == mgr.db && mgr.config.AcquireTimeout > 0 {
+		// 与 queryWithContext 相同，设置了获取连接超时时不使用语句缓存
+		conn, connErr := mgr.acquireConn(ctx, db)
+		if connErr != nil {
+			debug.log(start, querySQL, args, connErr)
+			return nil, mgr.logTrace(start, querySQL, args, connErr)
+		}
+		defer conn.Close()
+		rows, err = conn.QueryContext(ctx, querySQL, args...)
+	} else if db, ok := executor.(*sql.DB); ok && db == mgr.db {
 		// 使用缓存的预编译语句
 		stmt, fromCache, stmtErr := mgr.getOrPrepareStmt(querySQL)
 		if stmtErr != nil {
-			mgr.logTrace(start, querySQL, args, stmtErr)
-			return nil, stmtErr
+			debug.log(start, querySQL, args, stmtErr)
+			return nil, mgr.logTrace(start, querySQL, args, stmtErr)
 		}
 
 		// 执行查询（使用 context）
@@ -503,14 +600,15 @@ func (mgr *dbManager) queryMapWithContext(ctx context.Context, executor sqlExecu
 		}
 	}
 
-	mgr.logTrace(start, querySQL, args, err)
+	debug.log(start, querySQL, args, err)
+	err = mgr.logTrace(start, querySQL, args, err)
 
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	results, err := scanMaps(rows, mgr.config.Driver)
+	results, err := scanMaps(rows, mgr.config.Driver, mgr.getTimeLocation())
 	if err != nil {
 		return nil, err
 	}
@@ -550,25 +648,86 @@ func (mgr *dbManager) addLimitOne(querySQL string) string {
 	}
 }
 
+// queryMultiWithContext 执行可能返回多个结果集的语句（存储过程、多条 SELECT 组成的批处理），按顺序返回全部结果集
+// 不使用预编译语句缓存：部分驱动对预编译语句只返回第一个结果集
+func (mgr *dbManager) queryMultiWithContext(ctx context.Context, executor sqlExecutor, querySQL string, args ...interface{}) ([][]*Record, error) {
+	if err := checkPlaceholderStyle(querySQL, mgr.config.Driver); err != nil {
+		return nil, err
+	}
+	querySQL, args = mgr.prepareQuerySQL(querySQL, args...)
+	executor, querySQL, args, err := runMiddlewares(ctx, executor, OpQuery, querySQL, args)
+	if err != nil {
+		return nil, err
+	}
+	if err := mgr.checkParamLimit(len(args)); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+
+	var rows *sql.Rows
+	if execCtx, ok := executor.(sqlExecutorContext); ok {
+		rows, err = execCtx.QueryContext(ctx, querySQL, args...)
+	} else {
+		rows, err = executor.Query(querySQL, args...)
+	}
+	err = mgr.logTrace(start, querySQL, args, err)
+	if err != nil {
+		return nil, err
+	}
+
+	sets, err := mgr.scanResultSets(rows)
+	if err != nil {
+		return nil, err
+	}
+	results := make([][]*Record, len(sets))
+	for i, set := range sets {
+		results[i] = make([]*Record, len(set))
+		for j := range set {
+			results[i][j] = &set[j]
+		}
+	}
+	return results, nil
+}
+
 func (mgr *dbManager) exec(executor sqlExecutor, querySQL string, args ...interface{}) (sql.Result, error) {
-	return mgr.execWithContext(context.Background(), executor, querySQL, args...)
+	return mgr.execWithContext(executorContext(executor), executor, querySQL, args...)
 }
 
 func (mgr *dbManager) execWithContext(ctx context.Context, executor sqlExecutor, querySQL string, args ...interface{}) (sql.Result, error) {
+	if err := mgr.checkParamLimit(len(args)); err != nil {
+		return nil, err
+	}
+	if err := checkPlaceholderStyle(querySQL, mgr.config.Driver); err != nil {
+		return nil, err
+	}
 	querySQL = mgr.convertPlaceholder(querySQL, mgr.config.Driver)
 	args = mgr.sanitizeArgs(querySQL, args)
+	executor, querySQL, args, err := runMiddlewares(ctx, executor, OpExec, querySQL, args)
+	if err != nil {
+		return nil, err
+	}
+	// Debug 包装会挡住下面的 *sql.DB 判断，先拆开，日志由 debug.log 补记
+	executor, debug := splitDebugExecutor(executor)
 	start := time.Now()
 
 	var result sql.Result
-	var err error
 
 	// 只有当 executor 是 *sql.DB 时才使用预编译语句缓存
-	if db, ok := executor.(*sql.DB); ok && db == mgr.db {
+	if db, ok := executor.(*sql.DB); ok && db == mgr.db && mgr.config.AcquireTimeout > 0 {
+		// 与 queryWithContext 相同，设置了获取连接超时时不使用语句缓存
+		conn, connErr := mgr.acquireConn(ctx, db)
+		if connErr != nil {
+			debug.log(start, querySQL, args, connErr)
+			return nil, mgr.logTrace(start, querySQL, args, connErr)
+		}
+		defer conn.Close()
+		result, err = conn.ExecContext(ctx, querySQL, args...)
+	} else if db, ok := executor.(*sql.DB); ok && db == mgr.db {
 		// 使用缓存的预编译语句
 		stmt, fromCache, stmtErr := mgr.getOrPrepareStmt(querySQL)
 		if stmtErr != nil {
-			mgr.logTrace(start, querySQL, args, stmtErr)
-			return nil, stmtErr
+			debug.log(start, querySQL, args, stmtErr)
+			return nil, mgr.logTrace(start, querySQL, args, stmtErr)
 		}
 
 		// 执行命令（使用 context）
@@ -590,11 +749,20 @@ func (mgr *dbManager) execWithContext(ctx context.Context, executor sqlExecutor,
 		}
 	}
 
-	mgr.logTrace(start, querySQL, args, err)
+	debug.log(start, querySQL, args, err)
+	err = mgr.logTrace(start, querySQL, args, err)
 
 	if err != nil {
 		return nil, err
 	}
+	mgr.invalidateAfterDDL(querySQL)
+	// 原生 SQL 与 InsertFrom 等写入同样清除目标表上的负缓存
+	if table := dmlTable(querySQL); table != "" {
+		mgr.invalidateNegativeCacheOn(executor, table)
+		if name := unqualifiedName(table); name != table {
+			mgr.invalidateNegativeCacheOn(executor, name)
+		}
+	}
 	return result, nil
 }
 
@@ -862,15 +1030,25 @@ func (mgr *dbManager) getRecordID(record *Record, pks []string) (int64, bool) {
 	return 0, false
 }
 
-func (mgr *dbManager) save(executor sqlExecutor, table string, record *Record) (int64, error) {
+func (mgr *dbManager) save(executor sqlExecutor, table string, record *Record, opts UpsertOptions) (int64, error) {
 	if err := validateIdentifier(table); err != nil {
 		return 0, err
 	}
 	if record == nil || len(record.columns) == 0 {
 		return 0, fmt.Errorf("record is empty")
 	}
+	if err := mgr.applyTenant(executor, table, record); err != nil {
+		return 0, err
+	}
 
 	pks, _ := mgr.getPrimaryKeys(executor, table)
+	if opts.ConflictTarget != "" {
+		// 指定了冲突目标时按该目标 upsert，不要求记录包含主键
+		if mgr.config.Driver != PostgreSQL && mgr.config.Driver != SQLite3 {
+			return 0, fmt.Errorf("dbkit: UpsertOptions.ConflictTarget is only supported on PostgreSQL and SQLite, not %s", mgr.config.Driver)
+		}
+		return mgr.nativeUpsert(executor, table, record, pks, opts.conflictTarget(pks))
+	}
 	if len(pks) == 0 {
 		// 没有主键，直接执行插入
 		return mgr.insert(executor, table, record)
@@ -940,7 +1118,7 @@ func (mgr *dbManager) save(executor sqlExecutor, table string, record *Record) (
 		// 如果是 MySQL, PostgreSQL, SQLite, Oracle, SQLServer，使用原生的 Upsert 语法
 		driver := mgr.config.Driver
 		if driver == MySQL || driver == PostgreSQL || driver == SQLite3 || driver == Oracle || driver == SQLServer {
-			return mgr.nativeUpsert(executor, table, record, pks)
+			return mgr.nativeUpsert(executor, table, record, pks, "")
 		}
 
 		// 所有主键字段都存在，检查记录是否存在
@@ -981,11 +1159,11 @@ func (mgr *dbManager) getOrderedColumns(record *Record) ([]string, []interface{}
 	if record == nil || len(record.columns) == 0 {
 		return nil, nil
 	}
-	columns := make([]string, 0, len(record.columns))
-	values := make([]interface{}, 0, len(record.columns))
-	for col, val := range record.columns {
+	columns := make([]string, 0, len(record.keys))
+	values := make([]interface{}, 0, len(record.keys))
+	for _, col := range record.keys {
 		columns = append(columns, col)
-		values = append(values, val)
+		values = append(values, record.columns[col])
 	}
 	return columns, values
 }
@@ -996,10 +1174,10 @@ func (mgr *dbManager) getOrderedColumnsForInsert(record *Record) ([]string, []in
 	if record == nil || len(record.columns) == 0 {
 		return nil, nil
 	}
-	columns := make([]string, 0, len(record.columns))
-	values := make([]interface{}, 0, len(record.columns))
-	for col, val := range record.columns {
-		if val != nil { // 只包含非 nil 的字段
+	columns := make([]string, 0, len(record.keys))
+	values := make([]interface{}, 0, len(record.keys))
+	for _, col := range record.keys {
+		if val := record.columns[col]; val != nil { // 只包含非 nil 的字段
 			columns = append(columns, col)
 			values = append(values, val)
 		}
@@ -1007,7 +1185,9 @@ func (mgr *dbManager) getOrderedColumnsForInsert(record *Record) ([]string, []in
 	return columns, values
 }
 
-func (mgr *dbManager) nativeUpsert(executor sqlExecutor, table string, record *Record, pks []string) (int64, error) {
+// nativeUpsert 使用数据库原生语法 upsert；conflictTarget 为 PostgreSQL / SQLite 的冲突目标，为空时使用 (主键)
+func (mgr *dbManager) nativeUpsert(executor sqlExecutor, table string, record *Record, pks []string, conflictTarget string) (int64, error) {
+	defer mgr.invalidateNegativeCacheOn(executor, table)
 	driver := mgr.config.Driver
 
 	// 如果是 Oracle 或 SQL Server，使用 MERGE 语句
@@ -1019,6 +1199,12 @@ func (mgr *dbManager) nativeUpsert(executor sqlExecutor, table string, record *R
 	mgr.applyVersionInit(table, record)
 
 	columns, values := mgr.getOrderedColumnsForInsert(record)
+	if len(columns) == 0 {
+		return 0, fmt.Errorf("dbkit: record has no non-NULL columns to save")
+	}
+	if err := mgr.coerceColumnValues(table, columns, values); err != nil {
+		return 0, err
+	}
 	var placeholders []string
 	for range columns {
 		placeholders = append(placeholders, "?")
@@ -1053,18 +1239,21 @@ func (mgr *dbManager) nativeUpsert(executor sqlExecutor, table string, record *R
 	// 这与 MERGE 语法强制要求排除 IDENTITY 不同。
 	// 因此这里保持现状，允许 INSERT 部分包含所有 Record 字段。
 
+	if conflictTarget == "" {
+		conflictTarget = "(" + joinStrings(pks) + ")"
+	}
 	if len(updateClauses) > 0 {
 		if driver == MySQL {
 			sqlStr += " ON DUPLICATE KEY UPDATE " + joinStrings(updateClauses)
 		} else { // PostgreSQL, SQLite
-			sqlStr += fmt.Sprintf(" ON CONFLICT (%s) DO UPDATE SET %s", joinStrings(pks), joinStrings(updateClauses))
+			sqlStr += fmt.Sprintf(" ON CONFLICT %s DO UPDATE SET %s", conflictTarget, joinStrings(updateClauses))
 		}
 	} else {
 		// 如果只有主键字段，执行一个无意义的更新以确保能返回 ID
 		if driver == MySQL {
 			sqlStr += fmt.Sprintf(" ON DUPLICATE KEY UPDATE %s = %s", pks[0], pks[0])
 		} else {
-			sqlStr += fmt.Sprintf(" ON CONFLICT (%s) DO UPDATE SET %s = EXCLUDED.%s", joinStrings(pks), pks[0], pks[0])
+			sqlStr += fmt.Sprintf(" ON CONFLICT %s DO UPDATE SET %s = EXCLUDED.%s", conflictTarget, pks[0], pks[0])
 		}
 	}
 
@@ -1077,8 +1266,8 @@ func (mgr *dbManager) nativeUpsert(executor sqlExecutor, table string, record *R
 			sqlStr += " RETURNING id"
 			var id int64
 			start := time.Now()
-			err := executor.QueryRow(sqlStr, values...).Scan(&id)
-			mgr.logTrace(start, sqlStr, values, err)
+			err := queryRowScan(executor, sqlStr, values, &id)
+			err = mgr.logTrace(start, sqlStr, values, err)
 			if err != nil {
 				return 0, err
 			}
@@ -1088,7 +1277,7 @@ func (mgr *dbManager) nativeUpsert(executor sqlExecutor, table string, record *R
 
 	start := time.Now()
 	res, err := executor.Exec(sqlStr, values...)
-	mgr.logTrace(start, sqlStr, values, err)
+	err = mgr.logTrace(start, sqlStr, values, err)
 	if err != nil {
 		return 0, err
 	}
@@ -1120,6 +1309,9 @@ func (mgr *dbManager) mergeUpsert(executor sqlExecutor, table string, record *Re
 	mgr.applyVersionInit(table, record)
 
 	columns, values := mgr.getOrderedColumnsForInsert(record)
+	if err := mgr.coerceColumnValues(table, columns, values); err != nil {
+		return 0, err
+	}
 
 	// 构造 USING 子句
 	var selectCols []string
@@ -1198,7 +1390,7 @@ func (mgr *dbManager) mergeUpsert(executor sqlExecutor, table string, record *Re
 	// 但这会改变执行方式（从 Exec 变为 QueryRow），为了保持简单，我们先解决报错问题
 	start := time.Now()
 	res, err := executor.Exec(sqlStr, values...)
-	mgr.logTrace(start, sqlStr, values, err)
+	err = mgr.logTrace(start, sqlStr, values, err)
 	if err != nil {
 		return 0, err
 	}
@@ -1212,11 +1404,130 @@ func (mgr *dbManager) mergeUpsert(executor sqlExecutor, table string, record *Re
 	return res.RowsAffected()
 }
 
+// insertIgnore 插入记录，若与已有数据冲突则跳过（不更新），返回 1 表示已插入，0 表示已跳过
+// MySQL: INSERT IGNORE；PostgreSQL/SQLite: ON CONFLICT DO NOTHING；
+// SQL Server/Oracle: MERGE ... WHEN NOT MATCHED THEN INSERT（未指定冲突列时使用主键）
+func (mgr *dbManager) insertIgnore(executor sqlExecutor, table string, record *Record, conflictColumns []string) (int64, error) {
+	defer mgr.invalidateNegativeCacheOn(executor, table)
+	if err := validateIdentifier(table); err != nil {
+		return 0, err
+	}
+	if record == nil || len(record.columns) == 0 {
+		return 0, fmt.Errorf("record is empty")
+	}
+	for _, col := range conflictColumns {
+		if err := validateIdentifier(col); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := mgr.applyTenant(executor, table, record); err != nil {
+		return 0, err
+	}
+	mgr.applyCreatedAtTimestamp(table, record, false)
+	mgr.applyVersionInit(table, record)
+
+	columns, values := mgr.getOrderedColumnsForInsert(record)
+	if err := mgr.coerceColumnValues(table, columns, values); err != nil {
+		return 0, err
+	}
+	placeholders := make([]string, len(columns))
+	for i := range columns {
+		placeholders[i] = "?"
+	}
+
+	driver := mgr.config.Driver
+	var sqlStr string
+	switch driver {
+	case MySQL:
+		// 注意：INSERT IGNORE 同时会把部分其他错误（如截断）降级为警告
+		sqlStr = fmt.Sprintf("INSERT IGNORE INTO %s (%s) VALUES (%s)", table, joinStrings(columns), joinStrings(placeholders))
+	case PostgreSQL, SQLite3:
+		sqlStr = fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) ON CONFLICT", table, joinStrings(columns), joinStrings(placeholders))
+		if len(conflictColumns) > 0 {
+			sqlStr += fmt.Sprintf(" (%s)", joinStrings(conflictColumns))
+		}
+		sqlStr += " DO NOTHING"
+	case SQLServer, Oracle:
+		// ON 条件引用 USING 中的列，冲突列必须在记录中有值；未指定时使用记录中有值的主键列
+		if len(conflictColumns) == 0 {
+			pks, _ := mgr.getPrimaryKeys(executor, table)
+			if len(pks) == 0 {
+				return 0, fmt.Errorf("dbkit: InsertIgnore on %s requires conflict columns or a primary key", table)
+			}
+			for _, pk := range pks {
+				if containsFold(columns, pk) {
+					conflictColumns = append(conflictColumns, pk)
+				}
+			}
+			if len(conflictColumns) == 0 {
+				return 0, fmt.Errorf("dbkit: InsertIgnore on %s requires conflict columns when the record has no primary key value", table)
+			}
+		}
+		for _, col := range conflictColumns {
+			if !containsFold(columns, col) {
+				return 0, fmt.Errorf("dbkit: InsertIgnore on %s requires a value for conflict column %s", table, col)
+			}
+		}
+
+		selectCols := make([]string, len(columns))
+		for i, col := range columns {
+			selectCols[i] = "? AS " + col
+		}
+		usingSQL := "SELECT " + strings.Join(selectCols, ", ")
+		if driver == Oracle {
+			usingSQL += " FROM DUAL"
+		}
+		onClauses := make([]string, len(conflictColumns))
+		for i, col := range conflictColumns {
+			onClauses[i] = fmt.Sprintf("t.%s = s.%s", col, col)
+		}
+
+		// 与 mergeUpsert 一致，插入部分排除自增列
+		identityCol := mgr.getIdentityColumn(executor, table)
+		var insertCols, insertVals []string
+		for _, col := range columns {
+			if identityCol != "" && strings.EqualFold(col, identityCol) {
+				continue
+			}
+			insertCols = append(insertCols, col)
+			insertVals = append(insertVals, "s."+col)
+		}
+
+		sqlStr = fmt.Sprintf("MERGE INTO %s t USING (%s) s ON (%s) WHEN NOT MATCHED THEN INSERT (%s) VALUES (%s)",
+			table, usingSQL, strings.Join(onClauses, " AND "), strings.Join(insertCols, ", "), strings.Join(insertVals, ", "))
+		if driver == SQLServer {
+			sqlStr += ";" // SQL Server 的 MERGE 语句必须以分号结束
+		}
+	default:
+		return 0, fmt.Errorf("dbkit: InsertIgnore is not supported by %s", driver)
+	}
+
+	sqlStr = mgr.convertPlaceholder(sqlStr, driver)
+	values = mgr.sanitizeArgs(sqlStr, values)
+
+	start := time.Now()
+	res, err := executor.Exec(sqlStr, values...)
+	err = mgr.logTrace(start, sqlStr, values, err)
+	if err != nil {
+		return 0, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if affected > 0 {
+		return 1, nil
+	}
+	return 0, nil
+}
+
 func (mgr *dbManager) insert(executor sqlExecutor, table string, record *Record) (int64, error) {
 	return mgr.insertWithOptions(executor, table, record, false)
 }
 
 func (mgr *dbManager) insertWithOptions(executor sqlExecutor, table string, record *Record, skipTimestamps bool) (int64, error) {
+	defer mgr.invalidateNegativeCacheOn(executor, table)
 	if err := validateIdentifier(table); err != nil {
 		return 0, err
 	}
@@ -1224,6 +1535,11 @@ func (mgr *dbManager) insertWithOptions(executor sqlExecutor, table string, reco
 		return 0, fmt.Errorf("record is empty")
 	}
 
+	if err := mgr.applyTenant(executor, table, record); err != nil {
+		return 0, err
+	}
+	mgr.applyColumnDefaults(table, record)
+
 	// Apply created_at timestamp
 	mgr.applyCreatedAtTimestamp(table, record, skipTimestamps)
 
@@ -1231,6 +1547,9 @@ func (mgr *dbManager) insertWithOptions(executor sqlExecutor, table string, reco
 	mgr.applyVersionInit(table, record)
 
 	columns, values := mgr.getOrderedColumnsForInsert(record)
+	if err := mgr.coerceColumnValues(table, columns, values); err != nil {
+		return 0, err
+	}
 	var placeholders []string
 	for range columns {
 		placeholders = append(placeholders, "?")
@@ -1249,8 +1568,8 @@ func (mgr *dbManager) insertWithOptions(executor sqlExecutor, table string, reco
 			values = mgr.sanitizeArgs(querySQL, values)
 			var id int64
 			start := time.Now()
-			err := executor.QueryRow(querySQL, values...).Scan(&id)
-			mgr.logTrace(start, querySQL, values, err)
+			err := queryRowScan(executor, querySQL, values, &id)
+			err = mgr.logTrace(start, querySQL, values, err)
 			if err != nil {
 				return 0, err
 			}
@@ -1262,7 +1581,7 @@ func (mgr *dbManager) insertWithOptions(executor sqlExecutor, table string, reco
 		values = mgr.sanitizeArgs(querySQL, values)
 		start := time.Now()
 		res, err := executor.Exec(querySQL, values...)
-		mgr.logTrace(start, querySQL, values, err)
+		err = mgr.logTrace(start, querySQL, values, err)
 		if err != nil {
 			return 0, err
 		}
@@ -1279,8 +1598,8 @@ func (mgr *dbManager) insertWithOptions(executor sqlExecutor, table string, reco
 			values = mgr.sanitizeArgs(querySQL, values)
 			var id int64
 			start := time.Now()
-			err := executor.QueryRow(querySQL, values...).Scan(&id)
-			mgr.logTrace(start, querySQL, values, err)
+			err := queryRowScan(executor, querySQL, values, &id)
+			err = mgr.logTrace(start, querySQL, values, err)
 			if err == nil {
 				return id, nil
 			}
@@ -1291,7 +1610,7 @@ func (mgr *dbManager) insertWithOptions(executor sqlExecutor, table string, reco
 		values = mgr.sanitizeArgs(querySQL, values)
 		start := time.Now()
 		res, err := executor.Exec(querySQL, values...)
-		mgr.logTrace(start, querySQL, values, err)
+		err = mgr.logTrace(start, querySQL, values, err)
 		if err != nil {
 			return 0, err
 		}
@@ -1313,7 +1632,7 @@ func (mgr *dbManager) insertWithOptions(executor sqlExecutor, table string, reco
 			values = mgr.sanitizeArgs(querySQL, values)
 			start := time.Now()
 			_, err := executor.Exec(querySQL, values...)
-			mgr.logTrace(start, querySQL, values, err)
+			err = mgr.logTrace(start, querySQL, values, err)
 			if err != nil {
 				return 0, err
 			}
@@ -1330,7 +1649,7 @@ func (mgr *dbManager) insertWithOptions(executor sqlExecutor, table string, reco
 			var lastID int64
 			argsWithOut := append(values, sql.Out{Dest: &lastID})
 			_, err := executor.Exec(returningSql, argsWithOut...)
-			mgr.logTrace(start, returningSql, values, err)
+			err = mgr.logTrace(start, returningSql, values, err)
 			if err == nil {
 				return lastID, nil
 			}
@@ -1342,7 +1661,7 @@ func (mgr *dbManager) insertWithOptions(executor sqlExecutor, table string, reco
 		values = mgr.sanitizeArgs(querySQL, values)
 		start := time.Now()
 		res, err := executor.Exec(querySQL, values...)
-		mgr.logTrace(start, querySQL, values, err)
+		err = mgr.logTrace(start, querySQL, values, err)
 		if err != nil {
 			return 0, err
 		}
@@ -1354,7 +1673,7 @@ func (mgr *dbManager) insertWithOptions(executor sqlExecutor, table string, reco
 	values = mgr.sanitizeArgs(querySQL, values)
 	start := time.Now()
 	result, err := executor.Exec(querySQL, values...)
-	mgr.logTrace(start, querySQL, values, err)
+	err = mgr.logTrace(start, querySQL, values, err)
 	if err != nil {
 		return 0, err
 	}
@@ -1372,14 +1691,22 @@ func (mgr *dbManager) update(executor sqlExecutor, table string, record *Record,
 
 // updateFast is a lightweight update that skips timestamp and optimistic lock checks for better performance
 func (mgr *dbManager) updateFast(executor sqlExecutor, table string, record *Record, where string, whereArgs ...interface{}) (int64, error) {
+	defer mgr.invalidateNegativeCacheOn(executor, table)
 	if err := validateIdentifier(table); err != nil {
 		return 0, err
 	}
 	if record == nil || len(record.columns) == 0 {
 		return 0, fmt.Errorf("record is empty")
 	}
+	where, whereArgs, err := mgr.scopeTenant(executor, table, where, whereArgs)
+	if err != nil {
+		return 0, err
+	}
 
 	columns, values := mgr.getOrderedColumns(record)
+	if err := mgr.coerceColumnValues(table, columns, values); err != nil {
+		return 0, err
+	}
 	var setClauses []string
 	for _, col := range columns {
 		setClauses = append(setClauses, fmt.Sprintf("%s = ?", col))
@@ -1398,7 +1725,7 @@ func (mgr *dbManager) updateFast(executor sqlExecutor, table string, record *Rec
 	values = mgr.sanitizeArgs(querySQL, values)
 	start := time.Now()
 	result, err := executor.Exec(querySQL, values...)
-	mgr.logTrace(start, querySQL, values, err)
+	err = mgr.logTrace(start, querySQL, values, err)
 	if err != nil {
 		return 0, err
 	}
@@ -1407,18 +1734,138 @@ func (mgr *dbManager) updateFast(executor sqlExecutor, table string, record *Rec
 }
 
 func (mgr *dbManager) updateWithOptions(executor sqlExecutor, table string, record *Record, where string, skipTimestamps bool, whereArgs ...interface{}) (int64, error) {
+	defer mgr.invalidateNegativeCacheOn(executor, table)
 	if err := validateIdentifier(table); err != nil {
 		return 0, err
 	}
 	if record == nil || len(record.columns) == 0 {
 		return 0, fmt.Errorf("record is empty")
 	}
+	where, whereArgs, err := mgr.scopeTenant(executor, table, where, whereArgs)
+	if err != nil {
+		return 0, err
+	}
 
 	// Apply updated_at timestamp (only if feature is enabled)
 	if mgr.enableTimestampCheck {
 		mgr.applyUpdatedAtTimestamp(table, record, skipTimestamps)
 	}
 
+	setSQL, where, values, versionChecked, err := mgr.buildUpdateParts(table, record, where, whereArgs)
+	if err != nil {
+		return 0, err
+	}
+
+	var querySQL string
+	if where != "" {
+		querySQL = fmt.Sprintf("UPDATE %s SET %s WHERE %s", table, setSQL, where)
+	} else {
+		querySQL = fmt.Sprintf("UPDATE %s SET %s", table, setSQL)
+	}
+
+	querySQL = mgr.convertPlaceholder(querySQL, mgr.config.Driver)
+	values = mgr.sanitizeArgs(querySQL, values)
+	start := time.Now()
+	result, err := executor.Exec(querySQL, values...)
+	err = mgr.logTrace(start, querySQL, values, err)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	// If version was checked and no rows were affected, it's a version mismatch
+	if versionChecked && rowsAffected == 0 {
+		return 0, ErrVersionMismatch
+	}
+
+	return rowsAffected, nil
+}
+
+// increment 执行 UPDATE table SET col = col <op> ? ...，op 为 "+" 或 "-"，deltas 为列名 -> 增量，
+// 按列名排序生成 SET 子句；配置了 updated_at 时同时更新该字段
+func (mgr *dbManager) increment(executor sqlExecutor, table string, deltas map[string]interface{}, op string, where string, skipTimestamps bool, whereArgs ...interface{}) (int64, error) {
+	defer mgr.invalidateNegativeCacheOn(executor, table)
+	if err := validateIdentifier(table); err != nil {
+		return 0, err
+	}
+	if len(deltas) == 0 {
+		return 0, fmt.Errorf("dbkit: no columns to increment")
+	}
+	where, whereArgs, err := mgr.scopeTenant(executor, table, where, whereArgs)
+	if err != nil {
+		return 0, err
+	}
+
+	columns := make([]string, 0, len(deltas))
+	for col := range deltas {
+		if err := validateIdentifier(col); err != nil {
+			return 0, err
+		}
+		columns = append(columns, col)
+	}
+	sort.Strings(columns)
+
+	setClauses := make([]string, 0, len(columns)+1)
+	values := make([]interface{}, 0, len(columns)+1+len(whereArgs))
+	for _, col := range columns {
+		by := normalizeArg(deltas[col])
+		if !isNumericValue(by) {
+			return 0, fmt.Errorf("dbkit: increment value for column '%s' must be numeric, got %T", col, deltas[col])
+		}
+		setClauses = append(setClauses, fmt.Sprintf("%s = %s %s ?", col, col, op))
+		values = append(values, by)
+	}
+
+	if mgr.enableTimestampCheck && !skipTimestamps {
+		if config := mgr.getTimestampConfig(table); config != nil && config.UpdatedAtField != "" {
+			if _, ok := deltas[config.UpdatedAtField]; !ok {
+				setClauses = append(setClauses, fmt.Sprintf("%s = ?", config.UpdatedAtField))
+				values = append(values, mgr.timestampNow())
+			}
+		}
+	}
+	values = append(values, whereArgs...)
+
+	querySQL := fmt.Sprintf("UPDATE %s SET %s", table, joinStrings(setClauses))
+	if where != "" {
+		querySQL += " WHERE " + where
+	}
+
+	querySQL = mgr.convertPlaceholder(querySQL, mgr.config.Driver)
+	values = mgr.sanitizeArgs(querySQL, values)
+	start := time.Now()
+	result, err := executor.Exec(querySQL, values...)
+	err = mgr.logTrace(start, querySQL, values, err)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// isNumericValue 判断值是否为数值类型（driver.Valuer 交给驱动处理，视为合法）
+func isNumericValue(v interface{}) bool {
+	if v == nil {
+		return false
+	}
+	if _, ok := v.(driver.Valuer); ok {
+		return true
+	}
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// buildUpdateParts 构建 UPDATE 的 SET 子句、WHERE 子句与参数（SET 参数在前，WHERE 参数在后）
+// 启用乐观锁且记录包含版本字段时，版本号自增并追加到 WHERE 条件中
+func (mgr *dbManager) buildUpdateParts(table string, record *Record, where string, whereArgs []interface{}) (string, string, []interface{}, bool, error) {
 	// Check for optimistic lock (only if feature is enabled)
 	versionChecked := false
 	var currentVersion int64
@@ -1437,6 +1884,9 @@ func (mgr *dbManager) updateWithOptions(executor sqlExecutor, table string, reco
 	}
 
 	columns, values := mgr.getOrderedColumns(record)
+	if err := mgr.coerceColumnValues(table, columns, values); err != nil {
+		return "", "", nil, false, err
+	}
 	var setClauses []string
 	for _, col := range columns {
 		setClauses = append(setClauses, fmt.Sprintf("%s = ?", col))
@@ -1460,33 +1910,7 @@ func (mgr *dbManager) updateWithOptions(executor sqlExecutor, table string, reco
 
 	values = append(values, whereArgs...)
 
-	var querySQL string
-	if where != "" {
-		querySQL = fmt.Sprintf("UPDATE %s SET %s WHERE %s", table, joinStrings(setClauses), where)
-	} else {
-		querySQL = fmt.Sprintf("UPDATE %s SET %s", table, joinStrings(setClauses))
-	}
-
-	querySQL = mgr.convertPlaceholder(querySQL, mgr.config.Driver)
-	values = mgr.sanitizeArgs(querySQL, values)
-	start := time.Now()
-	result, err := executor.Exec(querySQL, values...)
-	mgr.logTrace(start, querySQL, values, err)
-	if err != nil {
-		return 0, err
-	}
-
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return 0, err
-	}
-
-	// If version was checked and no rows were affected, it's a version mismatch
-	if versionChecked && rowsAffected == 0 {
-		return 0, ErrVersionMismatch
-	}
-
-	return rowsAffected, nil
+	return joinStrings(setClauses), where, values, versionChecked, nil
 }
 
 func (mgr *dbManager) delete(executor sqlExecutor, table string, where string, whereArgs ...interface{}) (int64, error) {
@@ -1496,6 +1920,10 @@ func (mgr *dbManager) delete(executor sqlExecutor, table string, where string, w
 	if where == "" {
 		return 0, fmt.Errorf("where condition is required for delete")
 	}
+	where, whereArgs, err := mgr.scopeTenant(executor, table, where, whereArgs)
+	if err != nil {
+		return 0, err
+	}
 
 	// Check if soft delete is configured for this table
 	if mgr.hasSoftDelete(table) {
@@ -1507,7 +1935,7 @@ func (mgr *dbManager) delete(executor sqlExecutor, table string, where string, w
 
 	start := time.Now()
 	result, err := executor.Exec(querySQL, whereArgs...)
-	mgr.logTrace(start, querySQL, whereArgs, err)
+	err = mgr.logTrace(start, querySQL, whereArgs, err)
 	if err != nil {
 		return 0, err
 	}
@@ -1615,8 +2043,8 @@ func (mgr *dbManager) count(executor sqlExecutor, table string, where string, wh
 
 	var count int64
 	start := time.Now()
-	err := executor.QueryRow(querySQL, whereArgs...).Scan(&count)
-	mgr.logTrace(start, querySQL, whereArgs, err)
+	err := queryRowScan(executor, querySQL, whereArgs, &count)
+	err = mgr.logTrace(start, querySQL, whereArgs, err)
 	if err != nil {
 		return 0, err
 	}
@@ -1632,16 +2060,26 @@ func (mgr *dbManager) exists(executor sqlExecutor, table string, where string, w
 }
 
 func (mgr *dbManager) batchInsert(executor sqlExecutor, table string, records []*Record, batchSize int) (int64, error) {
+	defer mgr.invalidateNegativeCacheOn(executor, table)
 	if err := validateIdentifier(table); err != nil {
 		return 0, err
 	}
 	if len(records) == 0 {
 		return 0, fmt.Errorf("no records to insert")
 	}
+	if err := mgr.applyTenant(executor, table, records...); err != nil {
+		return 0, err
+	}
+	mgr.applyColumnDefaults(table, records...)
+	mgr.applyCreatedAtTimestamps(table, records)
+	records, err := mgr.coerceRecords(table, records)
+	if err != nil {
+		return 0, err
+	}
+
+	var totalAffected int64
+	driver := mgr.config.Driver
 
-	var totalAffected int64
-	driver := mgr.config.Driver
-
 	// Extract and sort columns once for all batches
 	firstRecord := records[0]
 	firstRecord.mu.RLock()
@@ -1699,7 +2137,7 @@ func (mgr *dbManager) batchInsert(executor sqlExecutor, table string, records []
 					placeholderIdx := rowIdx*numCols + colIdx + 1
 					sb.WriteString("$")
 					sb.WriteString(strconv.Itoa(placeholderIdx))
-					flatArgs = append(flatArgs, record.columns[col])
+					flatArgs = append(flatArgs, mgr.localizeArg(normalizeArg(record.columns[col])))
 				}
 				record.mu.RUnlock()
 				sb.WriteString(")")
@@ -1727,7 +2165,7 @@ func (mgr *dbManager) batchInsert(executor sqlExecutor, table string, records []
 						values = mgr.sanitizeArgs(querySQL, values)
 						start := time.Now()
 						result, err := executor.Exec(querySQL, values...)
-						mgr.logTrace(start, querySQL, values, err)
+						err = mgr.logTrace(start, querySQL, values, err)
 						if err != nil {
 							return totalAffected, err
 						}
@@ -1746,7 +2184,7 @@ func (mgr *dbManager) batchInsert(executor sqlExecutor, table string, records []
 					values = mgr.sanitizeArgs(querySQL, values)
 					start := time.Now()
 					result, err := stmt.Exec(values...)
-					mgr.logTrace(start, querySQL, values, err)
+					err = mgr.logTrace(start, querySQL, values, err)
 					if err != nil {
 						return totalAffected, err
 					}
@@ -1762,7 +2200,7 @@ func (mgr *dbManager) batchInsert(executor sqlExecutor, table string, records []
 					values = mgr.sanitizeArgs(querySQL, values)
 					start := time.Now()
 					result, err := executor.Exec(querySQL, values...)
-					mgr.logTrace(start, querySQL, values, err)
+					err = mgr.logTrace(start, querySQL, values, err)
 					if err != nil {
 						return totalAffected, err
 					}
@@ -1788,16 +2226,19 @@ func (mgr *dbManager) batchInsert(executor sqlExecutor, table string, records []
 				sb.WriteString(rowPlaceholder)
 				record.mu.RLock()
 				for _, col := range columns {
-					flatArgs = append(flatArgs, record.columns[col])
+					flatArgs = append(flatArgs, mgr.localizeArg(normalizeArg(record.columns[col])))
 				}
 				record.mu.RUnlock()
 			}
 			querySQL = sb.String()
 		}
 
+		if err := mgr.checkParamLimit(len(flatArgs)); err != nil {
+			return totalAffected, err
+		}
 		start := time.Now()
 		result, err := executor.Exec(querySQL, flatArgs...)
-		mgr.logTrace(start, querySQL, flatArgs, err)
+		err = mgr.logTrace(start, querySQL, flatArgs, err)
 		if err != nil {
 			return totalAffected, err
 		}
@@ -1807,14 +2248,159 @@ func (mgr *dbManager) batchInsert(executor sqlExecutor, table string, records []
 	return totalAffected, nil
 }
 
+// BatchError 描述批量操作中某一行的失败信息
+type BatchError struct {
+	Index int   // 记录在输入切片中的下标
+	Err   error // 数据库驱动返回的错误
+}
+
+func (e BatchError) Error() string {
+	return fmt.Sprintf("row %d: %v", e.Index, e.Err)
+}
+
+func (e BatchError) Unwrap() error {
+	return e.Err
+}
+
+// batchInsertPartial 逐行插入记录，单行失败不会中断整个批次
+// 在事务中执行时每行使用保存点，失败行回滚到保存点，避免 PostgreSQL 等数据库将整个事务标记为失败
+func (mgr *dbManager) batchInsertPartial(executor sqlExecutor, table string, records []*Record) (int64, []BatchError, error) {
+	if err := validateIdentifier(table); err != nil {
+		return 0, nil, err
+	}
+	if len(records) == 0 {
+		return 0, nil, fmt.Errorf("no records to insert")
+	}
+
+	_, inTx := unwrapExecutor(executor).(*sql.Tx)
+	var inserted int64
+	var failures []BatchError
+
+	for i, record := range records {
+		if inTx {
+			if _, err := mgr.exec(executor, mgr.savepointSQL("SAVEPOINT")); err != nil {
+				return inserted, failures, err
+			}
+		}
+
+		_, err := mgr.insert(executor, table, record)
+		if err != nil {
+			failures = append(failures, BatchError{Index: i, Err: err})
+			if inTx {
+				if _, rbErr := mgr.exec(executor, mgr.savepointSQL("ROLLBACK")); rbErr != nil {
+					return inserted, failures, rbErr
+				}
+			}
+			continue
+		}
+
+		inserted++
+		if inTx {
+			if releaseSQL := mgr.savepointSQL("RELEASE"); releaseSQL != "" {
+				if _, err := mgr.exec(executor, releaseSQL); err != nil {
+					return inserted, failures, err
+				}
+			}
+		}
+	}
+	return inserted, failures, nil
+}
+
+// batchInsertGrouped 按列集合对记录分组，每组单独调用 batchInsert，组的顺序与各列集合首次出现的顺序一致
+func (mgr *dbManager) batchInsertGrouped(executor sqlExecutor, table string, records []*Record, batchSize int) (int64, error) {
+	if err := validateIdentifier(table); err != nil {
+		return 0, err
+	}
+	if len(records) == 0 {
+		return 0, fmt.Errorf("no records to insert")
+	}
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	if err := mgr.applyTenant(executor, table, records...); err != nil {
+		return 0, err
+	}
+	mgr.applyColumnDefaults(table, records...)
+	mgr.applyCreatedAtTimestamps(table, records)
+
+	var order []string
+	groups := make(map[string][]*Record)
+	for i, record := range records {
+		if record == nil || len(record.columns) == 0 {
+			return 0, fmt.Errorf("dbkit: record at index %d is empty", i)
+		}
+		columns := record.Keys()
+		sort.Strings(columns)
+		key := strings.Join(columns, "\x00")
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], record)
+	}
+
+	var totalAffected int64
+	for _, key := range order {
+		affected, err := mgr.batchInsert(executor, table, groups[key], batchSize)
+		totalAffected += affected
+		if err != nil {
+			return totalAffected, err
+		}
+	}
+	return totalAffected, nil
+}
+
+// savepointSQL 返回各数据库的保存点语句，action 为 SAVEPOINT、ROLLBACK 或 RELEASE
+// SQL Server 和 Oracle 不支持释放保存点，RELEASE 返回空字符串
+func (mgr *dbManager) savepointSQL(action string) string {
+	const name = "dbkit_sp"
+	switch mgr.config.Driver {
+	case SQLServer:
+		switch action {
+		case "SAVEPOINT":
+			return "SAVE TRANSACTION " + name
+		case "ROLLBACK":
+			return "ROLLBACK TRANSACTION " + name
+		}
+		return ""
+	case Oracle:
+		switch action {
+		case "SAVEPOINT":
+			return "SAVEPOINT " + name
+		case "ROLLBACK":
+			return "ROLLBACK TO SAVEPOINT " + name
+		}
+		return ""
+	default:
+		switch action {
+		case "SAVEPOINT":
+			return "SAVEPOINT " + name
+		case "ROLLBACK":
+			return "ROLLBACK TO SAVEPOINT " + name
+		case "RELEASE":
+			return "RELEASE SAVEPOINT " + name
+		}
+		return ""
+	}
+}
+
 // batchUpdate 批量更新记录（根据主键）
+// 单主键表每批生成一条 UPDATE ... SET col = CASE pk WHEN ? THEN ? ... END WHERE pk IN (...)，
+// 复合主键或单条记录的批次退回逐行执行预编译 UPDATE
 func (mgr *dbManager) batchUpdate(executor sqlExecutor, table string, records []*Record, batchSize int) (int64, error) {
+	defer mgr.invalidateNegativeCacheOn(executor, table)
 	if err := validateIdentifier(table); err != nil {
 		return 0, err
 	}
 	if len(records) == 0 {
 		return 0, fmt.Errorf("no records to update")
 	}
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	records, err := mgr.coerceRecords(table, records)
+	if err != nil {
+		return 0, err
+	}
 
 	// 获取表的主键
 	pks, err := mgr.getPrimaryKeys(executor, table)
@@ -1829,7 +2415,7 @@ func (mgr *dbManager) batchUpdate(executor sqlExecutor, table string, records []
 	firstRecord := records[0]
 	firstRecord.mu.RLock()
 	var updateCols []string
-	for col := range firstRecord.columns {
+	for _, col := range firstRecord.keys {
 		isPK := false
 		for _, pk := range pks {
 			if strings.EqualFold(col, pk) {
@@ -1856,21 +2442,38 @@ func (mgr *dbManager) batchUpdate(executor sqlExecutor, table string, records []
 	for _, pk := range pks {
 		whereClauses = append(whereClauses, fmt.Sprintf("%s = ?", pk))
 	}
+	// 启用租户隔离时按主键更新也只命中当前租户的行
+	where, tenantArgs, err := mgr.scopeTenant(executor, table, strings.Join(whereClauses, " AND "), nil)
+	if err != nil {
+		return 0, err
+	}
 
 	querySQL := fmt.Sprintf("UPDATE %s SET %s WHERE %s",
-		table, joinStrings(setClauses), joinStrings(whereClauses))
+		table, joinStrings(setClauses), where)
 	querySQL = mgr.convertPlaceholder(querySQL, mgr.config.Driver)
 
 	var totalAffected int64
 	numUpdateCols := len(updateCols)
 	numPKs := len(pks)
-	numTotalArgs := numUpdateCols + numPKs
+	numTotalArgs := numUpdateCols + numPKs + len(tenantArgs)
+
+	// 单主键时每批合并为一条 CASE 语句，减少往返次数
+	rowsPerStmt := 0
+	if numPKs == 1 {
+		rowsPerStmt = batchSize
+		// 每行占用 numUpdateCols*2+1 个参数，按数据库的参数上限限制每条语句的行数
+		if limit := mgr.paramLimit(); limit > 0 {
+			if rows := (limit - len(tenantArgs)) / (numUpdateCols*2 + 1); rows < rowsPerStmt {
+				rowsPerStmt = rows
+			}
+		}
+	}
 
-	// Try to use prepared statement for all batches
+	// Per-row fallback (composite keys or single-row batches) uses a prepared statement
 	var stmt *sql.Stmt
 	if preparer, ok := executor.(interface {
 		Prepare(query string) (*sql.Stmt, error)
-	}); ok {
+	}); ok && (rowsPerStmt <= 1 || len(records)%batchSize == 1) {
 		stmt, _ = preparer.Prepare(querySQL)
 	}
 	if stmt != nil {
@@ -1885,6 +2488,21 @@ func (mgr *dbManager) batchUpdate(executor sqlExecutor, table string, records []
 		}
 		batch := records[i:end]
 
+		if rowsPerStmt > 1 && len(batch) > 1 {
+			for j := 0; j < len(batch); j += rowsPerStmt {
+				chunkEnd := j + rowsPerStmt
+				if chunkEnd > len(batch) {
+					chunkEnd = len(batch)
+				}
+				affected, err := mgr.batchUpdateCase(executor, table, pks[0], updateCols, batch[j:chunkEnd])
+				totalAffected += affected
+				if err != nil {
+					return totalAffected, err
+				}
+			}
+			continue
+		}
+
 		for _, record := range batch {
 			values := make([]interface{}, numTotalArgs)
 			record.mu.RLock()
@@ -1897,6 +2515,7 @@ func (mgr *dbManager) batchUpdate(executor sqlExecutor, table string, records []
 				values[numUpdateCols+j] = record.columns[pk]
 			}
 			record.mu.RUnlock()
+			copy(values[numUpdateCols+numPKs:], tenantArgs)
 
 			start := time.Now()
 			var result sql.Result
@@ -1907,7 +2526,7 @@ func (mgr *dbManager) batchUpdate(executor sqlExecutor, table string, records []
 				sanitizedValues := mgr.sanitizeArgs(querySQL, values)
 				result, err = executor.Exec(querySQL, sanitizedValues...)
 			}
-			mgr.logTrace(start, querySQL, values, err)
+			err = mgr.logTrace(start, querySQL, values, err)
 			if err != nil {
 				return totalAffected, err
 			}
@@ -1919,7 +2538,79 @@ func (mgr *dbManager) batchUpdate(executor sqlExecutor, table string, records []
 	return totalAffected, nil
 }
 
+// batchUpdateCase 使用单条 CASE 语句更新一批记录（仅支持单主键）
+// UPDATE t SET c = CASE pk WHEN ? THEN ? ... ELSE c END WHERE pk IN (?, ...)
+// ELSE 分支引用列本身，便于 PostgreSQL 推断参数类型
+func (mgr *dbManager) batchUpdateCase(executor sqlExecutor, table, pk string, updateCols []string, batch []*Record) (int64, error) {
+	// 主键重复时 CASE 只会命中第一个 WHEN，这里保留最后一条记录的值，与逐行更新的结果一致
+	pkValues := make([]interface{}, 0, len(batch))
+	rowValues := make([][]interface{}, 0, len(batch))
+	positions := make(map[string]int, len(batch))
+	for _, record := range batch {
+		record.mu.RLock()
+		pkValue := record.columns[pk]
+		values := make([]interface{}, len(updateCols))
+		for j, col := range updateCols {
+			values[j] = record.columns[col]
+		}
+		record.mu.RUnlock()
+		key := relationKey(pkValue)
+		if i, ok := positions[key]; ok {
+			rowValues[i] = values
+			continue
+		}
+		positions[key] = len(pkValues)
+		pkValues = append(pkValues, pkValue)
+		rowValues = append(rowValues, values)
+	}
+
+	args := make([]interface{}, 0, len(pkValues)*(len(updateCols)*2+1))
+	setClauses := make([]string, len(updateCols))
+	for j, col := range updateCols {
+		var sb strings.Builder
+		sb.WriteString(col)
+		sb.WriteString(" = CASE ")
+		sb.WriteString(pk)
+		for i := range pkValues {
+			sb.WriteString(" WHEN ? THEN ?")
+			args = append(args, pkValues[i], rowValues[i][j])
+		}
+		sb.WriteString(" ELSE ")
+		sb.WriteString(col)
+		sb.WriteString(" END")
+		setClauses[j] = sb.String()
+	}
+
+	placeholders := make([]string, len(pkValues))
+	for i := range pkValues {
+		placeholders[i] = "?"
+		args = append(args, pkValues[i])
+	}
+	where, args, err := mgr.scopeTenant(executor, table, fmt.Sprintf("%s IN (%s)", pk, joinStrings(placeholders)), args)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := mgr.checkParamLimit(len(args)); err != nil {
+		return 0, err
+	}
+
+	querySQL := fmt.Sprintf("UPDATE %s SET %s WHERE %s", table, joinStrings(setClauses), where)
+	querySQL = mgr.convertPlaceholder(querySQL, mgr.config.Driver)
+	args = mgr.sanitizeArgs(querySQL, args)
+
+	start := time.Now()
+	result, err := executor.Exec(querySQL, args...)
+	err = mgr.logTrace(start, querySQL, args, err)
+	if err != nil {
+		return 0, err
+	}
+	affected, _ := result.RowsAffected()
+	return affected, nil
+}
+
 // batchDelete 批量删除记录（根据主键）
+// 主键值按升序排列后再分批删除，使并发的批量删除以相同顺序加锁，避免 MySQL/PostgreSQL 死锁
 func (mgr *dbManager) batchDelete(executor sqlExecutor, table string, records []*Record, batchSize int) (int64, error) {
 	if err := validateIdentifier(table); err != nil {
 		return 0, err
@@ -1927,6 +2618,9 @@ func (mgr *dbManager) batchDelete(executor sqlExecutor, table string, records []
 	if len(records) == 0 {
 		return 0, fmt.Errorf("no records to delete")
 	}
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
 
 	// 获取表的主键
 	pks, err := mgr.getPrimaryKeys(executor, table)
@@ -1937,117 +2631,88 @@ func (mgr *dbManager) batchDelete(executor sqlExecutor, table string, records []
 		return 0, fmt.Errorf("table %s has no primary key, cannot use BatchDelete", table)
 	}
 
-	var totalAffected int64
-	driver := mgr.config.Driver
-
-	// 分批处理
-	for i := 0; i < len(records); i += batchSize {
-		end := i + batchSize
-		if end > len(records) {
-			end = len(records)
-		}
-
-		batch := records[i:end]
-
-		// 对于单主键，使用 IN 子句优化
-		if len(pks) == 1 {
-			pk := pks[0]
-			var pkValues []interface{}
-			var placeholders []string
-
-			for idx, record := range batch {
-				pkVal := record.Get(pk)
-				if pkVal == nil {
-					continue
-				}
+	// 对于单主键，使用 IN 子句优化；主键值为 nil 的记录被跳过
+	if len(pks) == 1 {
+		pk := pks[0]
+		var pkValues []interface{}
+		for _, record := range records {
+			if pkVal := record.Get(pk); pkVal != nil {
 				pkValues = append(pkValues, pkVal)
-				if driver == PostgreSQL {
-					placeholders = append(placeholders, fmt.Sprintf("$%d", idx+1))
-				} else if driver == SQLServer {
-					placeholders = append(placeholders, fmt.Sprintf("@p%d", idx+1))
-				} else if driver == Oracle {
-					placeholders = append(placeholders, fmt.Sprintf(":%d", idx+1))
-				} else {
-					placeholders = append(placeholders, "?")
-				}
-			}
-
-			if len(pkValues) == 0 {
-				continue
-			}
-
-			querySQL := fmt.Sprintf("DELETE FROM %s WHERE %s IN (%s)",
-				table, pk, strings.Join(placeholders, ", "))
-
-			start := time.Now()
-			result, err := executor.Exec(querySQL, pkValues...)
-			mgr.logTrace(start, querySQL, pkValues, err)
-			if err != nil {
-				return totalAffected, err
-			}
-			affected, _ := result.RowsAffected()
-			totalAffected += affected
-		} else {
-			// 复合主键，使用预处理语句逐条删除
-			var whereClauses []string
-			for _, pk := range pks {
-				whereClauses = append(whereClauses, fmt.Sprintf("%s = ?", pk))
 			}
+		}
+		if len(pkValues) == 0 {
+			return 0, nil
+		}
+		return mgr.deleteByKeyValues(executor, table, pk, sortKeyValues(pkValues), batchSize)
+	}
 
-			querySQL := fmt.Sprintf("DELETE FROM %s WHERE %s",
-				table, strings.Join(whereClauses, " AND "))
-			querySQL = mgr.convertPlaceholder(querySQL, driver)
-
-			// 尝试使用预处理语句
-			if preparer, ok := executor.(interface {
-				Prepare(query string) (*sql.Stmt, error)
-			}); ok {
-				stmt, err := preparer.Prepare(querySQL)
-				if err == nil {
-					defer stmt.Close()
-
-					for _, record := range batch {
-						var pkValues []interface{}
-						for _, pk := range pks {
-							pkValues = append(pkValues, record.Get(pk))
-						}
-
-						start := time.Now()
-						result, err := stmt.Exec(pkValues...)
-						mgr.logTrace(start, querySQL, pkValues, err)
-						if err != nil {
-							return totalAffected, err
-						}
-						affected, _ := result.RowsAffected()
-						totalAffected += affected
-					}
-					continue
-				}
+	// 复合主键，按主键元组升序逐条删除
+	keys := make([][]interface{}, len(records))
+	for i, record := range records {
+		keys[i] = make([]interface{}, len(pks))
+		for j, pk := range pks {
+			keys[i][j] = normalizeArg(record.Get(pk))
+		}
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		for k := range pks {
+			if c := compareKeyValues(keys[i][k], keys[j][k]); c != 0 {
+				return c < 0
 			}
+		}
+		return false
+	})
 
-			// 回退到单条执行
-			for _, record := range batch {
-				var pkValues []interface{}
-				for _, pk := range pks {
-					pkValues = append(pkValues, record.Get(pk))
-				}
+	var whereClauses []string
+	for _, pk := range pks {
+		whereClauses = append(whereClauses, fmt.Sprintf("%s = ?", pk))
+	}
+	where, tenantArgs, err := mgr.scopeTenant(executor, table, strings.Join(whereClauses, " AND "), nil)
+	if err != nil {
+		return 0, err
+	}
+	for i := range keys {
+		keys[i] = append(keys[i], tenantArgs...)
+	}
+	querySQL := fmt.Sprintf("DELETE FROM %s WHERE %s", table, where)
+	querySQL = mgr.convertPlaceholder(querySQL, mgr.config.Driver)
 
+	var totalAffected int64
+	// 尝试使用预处理语句
+	if preparer, ok := executor.(interface {
+		Prepare(query string) (*sql.Stmt, error)
+	}); ok {
+		if stmt, err := preparer.Prepare(querySQL); err == nil {
+			defer stmt.Close()
+			for _, pkValues := range keys {
 				start := time.Now()
-				result, err := executor.Exec(querySQL, pkValues...)
-				mgr.logTrace(start, querySQL, pkValues, err)
+				result, err := stmt.Exec(pkValues...)
+				err = mgr.logTrace(start, querySQL, pkValues, err)
 				if err != nil {
 					return totalAffected, err
 				}
 				affected, _ := result.RowsAffected()
 				totalAffected += affected
 			}
+			return totalAffected, nil
 		}
 	}
 
+	// 回退到单条执行
+	for _, pkValues := range keys {
+		start := time.Now()
+		result, err := executor.Exec(querySQL, pkValues...)
+		err = mgr.logTrace(start, querySQL, pkValues, err)
+		if err != nil {
+			return totalAffected, err
+		}
+		affected, _ := result.RowsAffected()
+		totalAffected += affected
+	}
 	return totalAffected, nil
 }
 
-// batchDeleteByIds 根据主键ID列表批量删除
+// batchDeleteByIds 根据主键ID列表批量删除，ID 按升序排列后再分批
 func (mgr *dbManager) batchDeleteByIds(executor sqlExecutor, table string, ids []interface{}, batchSize int) (int64, error) {
 	if err := validateIdentifier(table); err != nil {
 		return 0, err
@@ -2055,6 +2720,9 @@ func (mgr *dbManager) batchDeleteByIds(executor sqlExecutor, table string, ids [
 	if len(ids) == 0 {
 		return 0, fmt.Errorf("no ids to delete")
 	}
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
 
 	// 获取表的主键
 	pks, err := mgr.getPrimaryKeys(executor, table)
@@ -2068,65 +2736,180 @@ func (mgr *dbManager) batchDeleteByIds(executor sqlExecutor, table string, ids [
 		return 0, fmt.Errorf("BatchDeleteByIds only supports single primary key tables")
 	}
 
-	pk := pks[0]
-	var totalAffected int64
-	driver := mgr.config.Driver
+	return mgr.deleteByKeyValues(executor, table, pks[0], sortKeyValues(ids), batchSize)
+}
 
-	// 分批处理
-	for i := 0; i < len(ids); i += batchSize {
+// deleteByKeyValues 按 batchSize 分批执行 DELETE ... WHERE pk IN (...)，values 应已排序
+func (mgr *dbManager) deleteByKeyValues(executor sqlExecutor, table, pk string, values []interface{}, batchSize int) (int64, error) {
+	var totalAffected int64
+	for i := 0; i < len(values); i += batchSize {
 		end := i + batchSize
-		if end > len(ids) {
-			end = len(ids)
+		if end > len(values) {
+			end = len(values)
 		}
 
-		batch := ids[i:end]
-		var placeholders []string
-
-		for idx := range batch {
-			if driver == PostgreSQL {
-				placeholders = append(placeholders, fmt.Sprintf("$%d", idx+1))
-			} else if driver == SQLServer {
-				placeholders = append(placeholders, fmt.Sprintf("@p%d", idx+1))
-			} else if driver == Oracle {
-				placeholders = append(placeholders, fmt.Sprintf(":%d", idx+1))
-			} else {
-				placeholders = append(placeholders, "?")
-			}
+		placeholders := make([]string, end-i)
+		for idx := range placeholders {
+			placeholders[idx] = "?"
 		}
-
-		querySQL := fmt.Sprintf("DELETE FROM %s WHERE %s IN (%s)",
-			table, pk, strings.Join(placeholders, ", "))
+		// 启用租户隔离时只删除当前租户的行
+		where, batch, err := mgr.scopeTenant(executor, table, fmt.Sprintf("%s IN (%s)", pk, strings.Join(placeholders, ", ")), values[i:end])
+		if err != nil {
+			return totalAffected, err
+		}
+		if err := mgr.checkParamLimit(len(batch)); err != nil {
+			return totalAffected, err
+		}
+		querySQL := fmt.Sprintf("DELETE FROM %s WHERE %s", table, where)
+		querySQL = mgr.convertPlaceholder(querySQL, mgr.config.Driver)
 
 		start := time.Now()
 		result, err := executor.Exec(querySQL, batch...)
-		mgr.logTrace(start, querySQL, batch, err)
+		err = mgr.logTrace(start, querySQL, batch, err)
 		if err != nil {
 			return totalAffected, err
 		}
 		affected, _ := result.RowsAffected()
 		totalAffected += affected
 	}
-
 	return totalAffected, nil
 }
 
-func (mgr *dbManager) paginate(executor sqlExecutor, querySQL string, page, pageSize int, countCacheTTL time.Duration, args ...interface{}) ([]Record, int64, error) {
-	if page < 1 {
-		page = DefaultPage
+// sortKeyValues 返回升序排列的主键值副本（指针参数先解引用），不修改调用方的切片
+func sortKeyValues(values []interface{}) []interface{} {
+	sorted := make([]interface{}, len(values))
+	for i, v := range values {
+		sorted[i] = normalizeArg(v)
 	}
-	if pageSize < 1 {
-		pageSize = DefaultPageSize
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return compareKeyValues(sorted[i], sorted[j]) < 0
+	})
+	return sorted
+}
+
+// compareKeyValues 比较两个主键值：数值、字符串、时间分别按自然顺序比较，
+// 不同类别之间按类别排序，其他类型按 fmt 格式化后的字符串比较
+func compareKeyValues(a, b interface{}) int {
+	ca, cb := keyValueClass(a), keyValueClass(b)
+	if ca != cb {
+		if ca < cb {
+			return -1
+		}
+		return 1
+	}
+	switch ca {
+	case 1:
+		if isIntegerValue(a) && isIntegerValue(b) {
+			x, _ := toInt64(a)
+			y, _ := toInt64(b)
+			return compareOrdered(x, y)
+		}
+		x, _ := toFloat64(a)
+		y, _ := toFloat64(b)
+		return compareOrdered(x, y)
+	case 2:
+		return strings.Compare(keyValueString(a), keyValueString(b))
+	case 3:
+		x, y := a.(time.Time), b.(time.Time)
+		if x.Before(y) {
+			return -1
+		}
+		if x.After(y) {
+			return 1
+		}
+		return 0
+	case 4:
+		return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+	}
+	return 0
+}
+
+// keyValueClass 返回主键值的类别：0 nil，1 数值，2 字符串，3 时间，4 其他
+func keyValueClass(v interface{}) int {
+	switch v.(type) {
+	case nil:
+		return 0
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return 1
+	case string, []byte:
+		return 2
+	case time.Time:
+		return 3
+	}
+	return 4
+}
+
+func isIntegerValue(v interface{}) bool {
+	switch v.(type) {
+	case float32, float64:
+		return false
+	}
+	return true
+}
+
+func keyValueString(v interface{}) string {
+	if b, ok := v.([]byte); ok {
+		return string(b)
+	}
+	return v.(string)
+}
+
+func compareOrdered[T int64 | float64](x, y T) int {
+	if x < y {
+		return -1
 	}
-	// 限制最大页面大小，防止一次查询过多数据
-	if pageSize > MaxPageSize {
-		pageSize = MaxPageSize
+	if x > y {
+		return 1
 	}
+	return 0
+}
 
+// topLevelOrderByIndex 返回最外层 " order by " 的位置，忽略子查询、窗口函数和引号内的 ORDER BY，没有时返回 -1
+func topLevelOrderByIndex(lowerSQL string) int {
+	idx := -1
+	depth := 0
+	var quote byte
+	for i := 0; i < len(lowerSQL); i++ {
+		c := lowerSQL[i]
+		if quote != 0 {
+			if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '\'', '"', '`':
+			quote = c
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ' ':
+			if depth == 0 && strings.HasPrefix(lowerSQL[i:], " order by ") {
+				idx = i
+			}
+		}
+	}
+	return idx
+}
+
+func (mgr *dbManager) paginate(executor sqlExecutor, querySQL string, page, pageSize int, countCacheTTL time.Duration, args ...interface{}) ([]Record, int64, error) {
+	total, err := mgr.paginateCount(executor, querySQL, countCacheTTL, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	results, err := mgr.paginateList(executor, querySQL, page, pageSize, args...)
+	if err != nil {
+		return nil, total, err
+	}
+	return results, total, nil
+}
+
+// paginateCount 执行分页的 COUNT 查询，去掉最外层 ORDER BY，countCacheTTL > 0 时缓存计数
+func (mgr *dbManager) paginateCount(executor sqlExecutor, querySQL string, countCacheTTL time.Duration, args ...interface{}) (int64, error) {
 	driver := mgr.config.Driver
-	lowerSQL := strings.ToLower(querySQL)
 	baseSQL := querySQL
-	if strings.Contains(lowerSQL, " order by ") {
-		orderIdx := strings.Index(lowerSQL, " order by ")
+	if orderIdx := topLevelOrderByIndex(strings.ToLower(querySQL)); orderIdx >= 0 {
 		baseSQL = querySQL[:orderIdx]
 	}
 
@@ -2162,10 +2945,10 @@ func (mgr *dbManager) paginate(executor sqlExecutor, querySQL string, page, page
 		} else {
 			// 缓存未命中，执行 COUNT 查询
 			startCount := time.Now()
-			err := executor.QueryRow(countSQL, args...).Scan(&total)
-			mgr.logTrace(startCount, countSQL, args, err)
+			err := queryRowScan(executor, countSQL, args, &total)
+			err = mgr.logTrace(startCount, countSQL, args, err)
 			if err != nil {
-				return nil, 0, err
+				return 0, err
 			}
 
 			// 将计数结果缓存
@@ -2174,50 +2957,51 @@ func (mgr *dbManager) paginate(executor sqlExecutor, querySQL string, page, page
 	} else {
 		// 不使用缓存，直接执行 COUNT 查询
 		startCount := time.Now()
-		err := executor.QueryRow(countSQL, args...).Scan(&total)
-		mgr.logTrace(startCount, countSQL, args, err)
+		err := queryRowScan(executor, countSQL, args, &total)
+		err = mgr.logTrace(startCount, countSQL, args, err)
 		if err != nil {
-			return nil, 0, err
+			return 0, err
 		}
 	}
+	return total, nil
+}
 
+// paginateList 按方言为 querySQL 追加分页子句并查询一页数据
+func (mgr *dbManager) paginateList(executor sqlExecutor, querySQL string, page, pageSize int, args ...interface{}) ([]Record, error) {
+	page, pageSize = normalizePageParams(page, pageSize)
+
+	driver := mgr.config.Driver
+	orderIdx := topLevelOrderByIndex(strings.ToLower(querySQL))
 	offset := (page - 1) * pageSize
 	var paginatedSQL string
-	if driver == SQLServer {
-		if strings.Contains(lowerSQL, " order by ") {
-			paginatedSQL = fmt.Sprintf("%s OFFSET %d ROWS FETCH NEXT %d ROWS ONLY", querySQL, offset, pageSize)
-		} else {
-			paginatedSQL = fmt.Sprintf("%s ORDER BY (SELECT NULL) OFFSET %d ROWS FETCH NEXT %d ROWS ONLY", querySQL, offset, pageSize)
-		}
-	} else if driver == Oracle {
-		if strings.Contains(lowerSQL, " order by ") {
-			paginatedSQL = fmt.Sprintf("SELECT a.* FROM (SELECT a.*, ROWNUM rn FROM (%s) a WHERE ROWNUM <= %d) a WHERE rn > %d", querySQL, offset+pageSize, offset)
-		} else {
-			paginatedSQL = fmt.Sprintf("SELECT a.* FROM (SELECT a.*, ROWNUM rn FROM (%s ORDER BY 1) a WHERE ROWNUM <= %d) a WHERE rn > %d", querySQL, offset+pageSize, offset)
+	if (driver == SQLServer || driver == Oracle) && mgr.legacyPaging() {
+		paginatedSQL = mgr.legacyPageSQL(querySQL, offset, pageSize)
+	} else if driver == SQLServer || driver == Oracle {
+		// SQL Server 2012+ / Oracle 12c+ 原生分页；SQL Server 的 OFFSET 必须配合 ORDER BY
+		if driver == SQLServer && orderIdx < 0 {
+			querySQL += " ORDER BY (SELECT NULL)"
 		}
+		paginatedSQL = fmt.Sprintf("%s OFFSET %d ROWS FETCH NEXT %d ROWS ONLY", querySQL, offset, pageSize)
 	} else {
 		paginatedSQL = fmt.Sprintf("%s LIMIT %d OFFSET %d", querySQL, pageSize, offset)
 	}
 
 	paginatedSQL = mgr.convertPlaceholder(paginatedSQL, driver)
+	args = mgr.sanitizeArgs(paginatedSQL, args)
 
 	startPaginate := time.Now()
 	rows, err := executor.Query(paginatedSQL, args...)
-	mgr.logTrace(startPaginate, paginatedSQL, args, err)
+	err = mgr.logTrace(startPaginate, paginatedSQL, args, err)
 	if err != nil {
-		return nil, total, err
+		return nil, err
 	}
 	defer rows.Close()
 
-	results, err := scanRecords(rows, driver)
-	if err != nil {
-		return nil, total, err
-	}
-	return results, total, nil
+	return scanRecords(rows, driver, mgr.getTimeLocation())
 }
 
 // scanRows is a helper function to scan sql.Rows into a slice of maps
-func scanRows(rows *sql.Rows) ([]map[string]interface{}, error) {
+func scanRows(rows *sql.Rows, loc *time.Location) ([]map[string]interface{}, error) {
 	columns, err := rows.Columns()
 	if err != nil {
 		return nil, err
@@ -2244,6 +3028,9 @@ func scanRows(rows *sql.Rows) ([]map[string]interface{}, error) {
 
 		entry := make(map[string]interface{}, numCols)
 		for i, col := range columns {
+			if isPageRowNumberColumn(col) {
+				continue
+			}
 			val := values[i]
 
 			// Handle []byte conversion for numeric/decimal types
@@ -2271,7 +3058,7 @@ func scanRows(rows *sql.Rows) ([]map[string]interface{}, error) {
 				continue
 			}
 
-			entry[col] = val
+			entry[col] = localizeScannedTime(val, strings.ToUpper(columnTypes[i].DatabaseTypeName()), loc)
 		}
 		results = append(results, entry)
 	}
@@ -2340,7 +3127,7 @@ func optimizeCountSQL(querySQL string) (string, bool) {
 // 2. 通过中间map转换，增加了一次内存分配
 // 3. 没有利用已知的列数信息进行精确容量分配
 func scanRecords_inefficiency(rows *sql.Rows, driver DriverType) ([]Record, error) {
-	maps, err := scanRows(rows)
+	maps, err := scanRows(rows, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -2390,7 +3177,7 @@ func processDBValue(val interface{}, dbType string) interface{} {
 // 注意：由于需要返回Record值而非指针，这里直接创建精确容量的Record
 //
 //	对象池更适合用于临时操作的场景
-func scanRecords(rows *sql.Rows, driver DriverType) ([]Record, error) {
+func scanRecords(rows *sql.Rows, driver DriverType, loc *time.Location) ([]Record, error) {
 	columns, err := rows.Columns()
 	if err != nil {
 		return nil, err
@@ -2420,14 +3207,18 @@ func scanRecords(rows *sql.Rows, driver DriverType) ([]Record, error) {
 		resultRecord := &Record{
 			columns:     make(map[string]interface{}, numCols),
 			lowerKeyMap: make(map[string]string, numCols),
+			keys:        make([]string, 0, numCols),
 		}
 
 		for i, col := range columns {
+			if isPageRowNumberColumn(col) {
+				continue
+			}
 			val := values[i]
 			dbType := strings.ToUpper(columnTypes[i].DatabaseTypeName())
 
 			// 使用专门的函数处理数据库值转换
-			processedVal := processDBValue(val, dbType)
+			processedVal := localizeScannedTime(processDBValue(val, dbType), dbType, loc)
 			resultRecord.Set(col, processedVal)
 		}
 
@@ -2442,8 +3233,8 @@ func scanRecords(rows *sql.Rows, driver DriverType) ([]Record, error) {
 }
 
 // scanMaps is a helper function to scan sql.Rows into a slice of map
-func scanMaps(rows *sql.Rows, driver DriverType) ([]map[string]interface{}, error) {
-	return scanRows(rows)
+func scanMaps(rows *sql.Rows, driver DriverType, loc *time.Location) ([]map[string]interface{}, error) {
+	return scanRows(rows, loc)
 }
 
 // GetDB returns the underlying database connection
@@ -2461,21 +3252,43 @@ func (db *DB) Close() error {
 
 // SetCurrentDB switches the global default database by name
 func SetCurrentDB(dbname string) error {
-	multiMgr.mu.RLock()
-	_, exists := multiMgr.databases[dbname]
-	multiMgr.mu.RUnlock()
+	multiMgr.mu.Lock()
+	defer multiMgr.mu.Unlock()
 
-	if !exists {
-		return fmt.Errorf("database '%s' not found", dbname)
+	if _, exists := multiMgr.databases[dbname]; !exists {
+		return unknownDatabaseError(dbname)
 	}
+	multiMgr.currentDB = dbname
+	return nil
+}
 
+// SetDefaultDatabase sets the database that top-level functions (dbkit.Query, dbkit.Table, ...) use.
+// 默认使用第一个注册的数据库；与 SetCurrentDB 不同，这里设置的数据库也是 CloseDB 关闭当前数据库后的回退目标
+func SetDefaultDatabase(dbname string) error {
 	multiMgr.mu.Lock()
-	multiMgr.currentDB = dbname
-	multiMgr.mu.Unlock()
+	defer multiMgr.mu.Unlock()
 
+	if _, exists := multiMgr.databases[dbname]; !exists {
+		return unknownDatabaseError(dbname)
+	}
+	multiMgr.defaultDB = dbname
+	multiMgr.currentDB = dbname
 	return nil
 }
 
+// unknownDatabaseError 返回包含已注册数据库名称的错误，调用方需持有 multiMgr.mu
+func unknownDatabaseError(dbname string) error {
+	names := make([]string, 0, len(multiMgr.databases))
+	for name := range multiMgr.databases {
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return fmt.Errorf("%w: '%s' (no databases registered, call dbkit.OpenDatabase or dbkit.Register first)", ErrDatabaseNotFound, dbname)
+	}
+	sort.Strings(names)
+	return fmt.Errorf("%w: '%s' (registered: %s)", ErrDatabaseNotFound, dbname, strings.Join(names, ", "))
+}
+
 // safeGetCurrentDB returns the current database manager without panicking
 func safeGetCurrentDB() (*dbManager, error) {
 	if multiMgr == nil {
@@ -2547,11 +3360,12 @@ func GetDB() (*sql.DB, error) {
 func GetDBByName(dbname string) (*sql.DB, error) {
 	multiMgr.mu.RLock()
 	dbMgr, exists := multiMgr.databases[dbname]
-	multiMgr.mu.RUnlock()
-
 	if !exists {
-		return nil, fmt.Errorf("database '%s' not found", dbname)
+		err := unknownDatabaseError(dbname)
+		multiMgr.mu.RUnlock()
+		return nil, err
 	}
+	multiMgr.mu.RUnlock()
 
 	db, err := dbMgr.getDB()
 	if err != nil {
@@ -2572,11 +3386,13 @@ func Close() error {
 	defer multiMgr.mu.Unlock()
 
 	for _, dbMgr := range multiMgr.databases {
-		// 停止连接监控
+		// 停止连接监控和连接池自动调整
 		cleanupMonitor(dbMgr.name)
+		StopAutoSizePool(dbMgr.name)
 
-		// 清理预编译语句缓存
+		// 清理预编译语句与列名缓存
 		dbMgr.clearStmtCache()
+		dbMgr.clearJoinColumns(nil)
 
 		// 关闭数据库连接
 		if dbMgr.db != nil {
@@ -2597,11 +3413,13 @@ func CloseDB(dbname string) error {
 		defer multiMgr.mu.Unlock()
 
 		if dbMgr, exists := multiMgr.databases[dbname]; exists {
-			// 停止连接监控
+			// 停止连接监控和连接池自动调整
 			cleanupMonitor(dbname)
+			StopAutoSizePool(dbname)
 
-			// 清理预编译语句缓存
+			// 清理预编译语句与列名缓存
 			dbMgr.clearStmtCache()
+			dbMgr.clearJoinColumns(nil)
 
 			// 关闭数据库连接
 			if dbMgr.db != nil {
@@ -2767,11 +3585,45 @@ func (mgr *dbManager) initDB() error {
 		return nil
 	}
 
-	db, err := sql.Open(string(mgr.config.Driver), mgr.config.DSN)
+	db, err := mgr.openPool()
 	if err != nil {
 		return err
 	}
 
+	mgr.db = db
+
+	// 根据配置启用连接监控
+	if mgr.config.MonitorNormalInterval > 0 {
+		if err := mgr.startConnectionMonitoring(); err != nil {
+			// 监控启动失败不影响数据库连接，只记录警告日志
+			LogWarn("连接监控启动失败", map[string]interface{}{
+				"database": mgr.name,
+				"error":    err.Error(),
+			})
+		}
+	}
+
+	return nil
+}
+
+// openPool 按当前配置创建并校验连接池，调用方负责加锁与替换 mgr.db
+func (mgr *dbManager) openPool() (*sql.DB, error) {
+	db, err := sql.Open(string(mgr.config.Driver), mgr.config.DSN)
+	if err != nil {
+		return nil, err
+	}
+
+	// 配置了连接初始化语句时，改用包装后的 Connector 在每个新连接上执行
+	if mgr.config.OnConnect != nil || len(mgr.sqlitePragmas) > 0 {
+		drv := db.Driver()
+		db.Close()
+		connector, err := newOnConnectConnector(drv, mgr.config.DSN, sessionInitializer(mgr.sqlitePragmas, mgr.config.OnConnect))
+		if err != nil {
+			return nil, err
+		}
+		db = sql.OpenDB(connector)
+	}
+
 	// Configure connection pool
 	db.SetMaxOpenConns(mgr.config.MaxOpen)
 	db.SetMaxIdleConns(mgr.config.MaxIdle)
@@ -2780,23 +3632,39 @@ func (mgr *dbManager) initDB() error {
 	// Verify connection
 	if err := db.Ping(); err != nil {
 		db.Close()
-		return err
+		return nil, err
 	}
 
-	mgr.db = db
+	// 与版本相关的能力在此检测一次，之后的查询只读取结果，不会在事务中占用额外连接
+	mgr.windowFunctions = detectWindowFunctions(mgr.config.Driver, db)
+	mgr.legacyPagingDetected = detectLegacyPaging(mgr.config.Driver, db)
 
-	// 根据配置启用连接监控
-	if mgr.config.MonitorNormalInterval > 0 {
-		if err := mgr.startConnectionMonitoring(); err != nil {
-			// 监控启动失败不影响数据库连接，只记录警告日志
-			LogWarn("连接监控启动失败", map[string]interface{}{
+	// 预热失败不影响连接池使用，连接会在首次查询时按需建立
+	if mgr.config.WarmUpOnOpen {
+		if err := mgr.warmUpPool(db, mgr.config.MaxIdle); err != nil {
+			LogWarn("连接池预热失败", map[string]interface{}{
 				"database": mgr.name,
 				"error":    err.Error(),
 			})
 		}
 	}
+	return db, nil
+}
 
-	return nil
+// sessionInitializer 返回新连接的初始化函数：先执行 SQLite PRAGMA，再调用用户配置的 OnConnect
+// 在创建连接池时捕获配置，连接建立过程中不再访问 dbManager 的可变状态
+func sessionInitializer(pragmas []string, onConnect func(ctx context.Context, conn SessionConn) error) func(ctx context.Context, conn SessionConn) error {
+	return func(ctx context.Context, conn SessionConn) error {
+		for _, pragma := range pragmas {
+			if err := conn.Exec(ctx, pragma); err != nil {
+				return fmt.Errorf("dbkit: %s failed: %v", pragma, err)
+			}
+		}
+		if onConnect != nil {
+			return onConnect(ctx, conn)
+		}
+		return nil
+	}
 }
 
 // getDB returns the database connection, initializing if necessary
@@ -2814,6 +3682,22 @@ func (mgr *dbManager) getDB() (*sql.DB, error) {
 	return mgr.db, nil
 }
 
+// acquireConn 在 Config.AcquireTimeout 内从连接池获取一个连接
+// 等待超时返回 ErrPoolTimeout；调用方的 ctx 本身已取消或超时则返回 ctx 的错误
+func (mgr *dbManager) acquireConn(ctx context.Context, db *sql.DB) (*sql.Conn, error) {
+	acquireCtx, cancel := context.WithTimeout(ctx, mgr.config.AcquireTimeout)
+	defer cancel()
+
+	conn, err := db.Conn(acquireCtx)
+	if err != nil {
+		if ctx.Err() == nil && errors.Is(acquireCtx.Err(), context.DeadlineExceeded) {
+			return nil, ErrPoolTimeout
+		}
+		return nil, err
+	}
+	return conn, nil
+}
+
 // Ping checks if the database connection is alive
 func (mgr *dbManager) Ping() error {
 	if mgr == nil {
@@ -2880,6 +3764,11 @@ func (mgr *dbManager) convertPlaceholderWithOffset(querySQL string, driver Drive
 		}
 
 		if char == '?' && !inSingleQuote && !inDoubleQuote && !inBacktick {
+			if driver == PostgreSQL && isJSONBOperator(querySQL, i) {
+				// jsonb 运算符 ?、?|、?& 原样保留，不占用参数序号
+				builder.WriteByte(char)
+				continue
+			}
 			switch driver {
 			case PostgreSQL:
 				builder.WriteString(fmt.Sprintf("$%d", paramIndex))
@@ -2970,50 +3859,70 @@ func (mgr *dbManager) sanitizeArgs(querySQL string, args []interface{}) []interf
 		maxArgs = len(args)
 	}
 
-	// 处理参数，解引用指针类型以便在日志中显示实际值
+	// 处理参数：typed nil 指针绑定为 NULL，其他指针解引用为实际值
 	for i := 0; i < maxArgs; i++ {
-		arg := args[i]
-		if arg != nil {
-			// 使用反射检查是否为指针类型
-			v := reflect.ValueOf(arg)
-			if v.Kind() == reflect.Ptr {
-				if v.IsNil() {
-					cleanedArgs = append(cleanedArgs, nil)
-				} else {
-					// 解引用指针，获取实际值用于日志显示
-					actualValue := v.Elem().Interface()
-					// 如果是时间类型，格式化显示
-					if t, ok := actualValue.(time.Time); ok {
-						cleanedArgs = append(cleanedArgs, t.Format("2006-01-02 15:04:05"))
-					} else {
-						cleanedArgs = append(cleanedArgs, actualValue)
-					}
-				}
-			} else {
-				// 如果是时间类型，格式化显示
-				if t, ok := arg.(time.Time); ok {
-					cleanedArgs = append(cleanedArgs, t.Format("2006-01-02 15:04:05"))
-				} else {
-					cleanedArgs = append(cleanedArgs, arg)
-				}
-			}
+		arg := mgr.localizeArg(normalizeArg(args[i]))
+		// 如果是时间类型，格式化显示
+		if t, ok := arg.(time.Time); ok {
+			cleanedArgs = append(cleanedArgs, t.Format("2006-01-02 15:04:05"))
 		} else {
-			cleanedArgs = append(cleanedArgs, nil)
+			cleanedArgs = append(cleanedArgs, arg)
 		}
 	}
 
 	return cleanedArgs
 }
 
-// logTrace 辅助函数，封装 SQL 日志记录逻辑
-func (mgr *dbManager) logTrace(start time.Time, sql string, args []interface{}, err error) {
+// normalizeArg 统一各驱动对指针参数的处理：typed nil 指针（如 (*float64)(nil)）返回 nil 以绑定 SQL NULL，
+// 非 nil 指针（包括多级指针）解引用为实际值；实现了 driver.Valuer 的类型原样交给驱动处理
+func normalizeArg(arg interface{}) interface{} {
+	for arg != nil {
+		if _, ok := arg.(driver.Valuer); ok {
+			return arg
+		}
+		v := reflect.ValueOf(arg)
+		if v.Kind() != reflect.Ptr {
+			return arg
+		}
+		if v.IsNil() {
+			return nil
+		}
+		arg = v.Elem().Interface()
+	}
+	return nil
+}
+
+// QueryError wraps a driver error together with the SQL and arguments dbkit actually sent.
+// 错误信息与原始错误一致，可通过 errors.As 取出最终生成的 SQL（占位符已按方言转换）用于排查
+type QueryError struct {
+	SQL  string
+	Args []interface{}
+	Err  error
+}
+
+func (e *QueryError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *QueryError) Unwrap() error {
+	return e.Err
+}
+
+// logTrace 记录 SQL 执行日志，执行失败时返回携带 SQL 与参数的 *QueryError，驱动错误包装为 *DBError
+func (mgr *dbManager) logTrace(start time.Time, sql string, args []interface{}, err error) error {
 	duration := time.Since(start)
 	cleanArgs := mgr.sanitizeArgs(sql, args)
-	if err != nil {
-		LogSQLError(mgr.name, sql, cleanArgs, duration, err)
-	} else {
+	if err == nil {
 		LogSQL(mgr.name, sql, cleanArgs, duration)
+		checkNPlusOne(mgr.name, sql)
+		return nil
+	}
+	LogSQLError(mgr.name, sql, cleanArgs, duration, err)
+	var qe *QueryError
+	if errors.As(err, &qe) {
+		return err
 	}
+	return &QueryError{SQL: sql, Args: cleanArgs, Err: wrapDBError(mgr.config.Driver, err)}
 }
 
 // checkTableColumn 检查表中是否存在指定字段
@@ -3145,6 +4054,25 @@ func GetPoolStatsDB(dbname string) *PoolStats {
 	return Use(dbname).PoolStats()
 }
 
+// PoolSnapshot returns a point-in-time copy of the pool statistics for dbName ("" for the default database).
+// 适合在压测等场景中定期采样，对比 InUse、WaitCount、WaitDuration 的变化判断连接池是否饱和；
+// 数据库不存在或未连接时返回零值
+func PoolSnapshot(dbName string) PoolStats {
+	var db *DB
+	if dbName == "" {
+		var err error
+		if db, err = defaultDB(); err != nil {
+			return PoolStats{}
+		}
+	} else {
+		db = Use(dbName)
+	}
+	if stats := db.PoolStats(); stats != nil {
+		return *stats
+	}
+	return PoolStats{}
+}
+
 // AllPoolStats returns the connection pool statistics for all registered databases
 func AllPoolStats() map[string]*PoolStats {
 	result := make(map[string]*PoolStats)
@@ -0,0 +1,288 @@
+package dbkit
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+)
+
+// dbErrorKind 跨数据库统一的错误分类
+type dbErrorKind int
+
+const (
+	dbErrorOther dbErrorKind = iota
+	dbErrorUniqueViolation
+	dbErrorForeignKeyViolation
+	dbErrorDeadlock
+	dbErrorSerializationFailure
+)
+
+// dbErrorNumbers 各数据库的厂商错误号 -> 错误分类
+var dbErrorNumbers = map[DriverType]map[int64]dbErrorKind{
+	MySQL: {
+		1062: dbErrorUniqueViolation, // ER_DUP_ENTRY
+		1586: dbErrorUniqueViolation, // ER_DUP_ENTRY_WITH_KEY_NAME
+		1216: dbErrorForeignKeyViolation,
+		1217: dbErrorForeignKeyViolation,
+		1451: dbErrorForeignKeyViolation, // ER_ROW_IS_REFERENCED_2
+		1452: dbErrorForeignKeyViolation, // ER_NO_REFERENCED_ROW_2
+		1213: dbErrorDeadlock,            // ER_LOCK_DEADLOCK
+	},
+	SQLServer: {
+		2601: dbErrorUniqueViolation, // 唯一索引
+		2627: dbErrorUniqueViolation, // 主键 / 唯一约束
+		547:  dbErrorForeignKeyViolation,
+		1205: dbErrorDeadlock,
+		3960: dbErrorSerializationFailure, // 快照隔离更新冲突
+	},
+	Oracle: {
+		1:    dbErrorUniqueViolation, // ORA-00001
+		2291: dbErrorForeignKeyViolation,
+		2292: dbErrorForeignKeyViolation,
+		60:   dbErrorDeadlock,             // ORA-00060
+		8177: dbErrorSerializationFailure, // ORA-08177
+	},
+	SQLite3: { // 扩展错误码
+		2067: dbErrorUniqueViolation, // SQLITE_CONSTRAINT_UNIQUE
+		1555: dbErrorUniqueViolation, // SQLITE_CONSTRAINT_PRIMARYKEY
+		787:  dbErrorForeignKeyViolation,
+		517:  dbErrorSerializationFailure, // SQLITE_BUSY_SNAPSHOT
+	},
+}
+
+// dbErrorSQLStates 标准 SQLSTATE -> 错误分类（PostgreSQL 及其他提供 SQLSTATE 的驱动）
+var dbErrorSQLStates = map[string]dbErrorKind{
+	"23505": dbErrorUniqueViolation,
+	"23503": dbErrorForeignKeyViolation,
+	"40P01": dbErrorDeadlock,
+	"40001": dbErrorSerializationFailure,
+}
+
+// dbErrorMessages 无法取得错误码时按错误信息识别
+var dbErrorMessages = []struct {
+	substr string
+	kind   dbErrorKind
+}{
+	{"Error 1062", dbErrorUniqueViolation},
+	{"SQLSTATE 23505", dbErrorUniqueViolation},
+	{"duplicate key value violates unique constraint", dbErrorUniqueViolation},
+	{"UNIQUE constraint failed", dbErrorUniqueViolation},
+	{"ORA-00001", dbErrorUniqueViolation},
+	{"Cannot insert duplicate key", dbErrorUniqueViolation},
+	{"Error 1451", dbErrorForeignKeyViolation},
+	{"Error 1452", dbErrorForeignKeyViolation},
+	{"SQLSTATE 23503", dbErrorForeignKeyViolation},
+	{"violates foreign key constraint", dbErrorForeignKeyViolation},
+	{"FOREIGN KEY constraint failed", dbErrorForeignKeyViolation},
+	{"ORA-02291", dbErrorForeignKeyViolation},
+	{"ORA-02292", dbErrorForeignKeyViolation},
+	{"Error 1213", dbErrorDeadlock},
+	{"SQLSTATE 40P01", dbErrorDeadlock},
+	{"deadlock detected", dbErrorDeadlock},
+	{"was deadlocked on lock", dbErrorDeadlock},
+	{"ORA-00060", dbErrorDeadlock},
+	{"SQLSTATE 40001", dbErrorSerializationFailure},
+	{"could not serialize access", dbErrorSerializationFailure},
+	{"ORA-08177", dbErrorSerializationFailure},
+}
+
+// DBError wraps an error reported by the database driver with its vendor code and SQLSTATE.
+// 执行失败的 SQL 返回的 *QueryError 内部包含 *DBError，可通过 errors.As 取出后按错误类别处理，
+// 不必再匹配各驱动不同的错误信息
+type DBError struct {
+	Driver   DriverType // 产生错误的数据库类型
+	Number   int64      // 厂商错误号（MySQL 1062、SQL Server 2627、Oracle 1 即 ORA-00001、SQLite 扩展错误码），未知时为 0
+	SQLState string     // 驱动提供的 SQLSTATE，未提供时为空
+	Err      error      // 原始驱动错误
+
+	kind dbErrorKind
+}
+
+func (e *DBError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *DBError) Unwrap() error {
+	return e.Err
+}
+
+// Code returns the SQLSTATE of the error. 驱动未提供 SQLSTATE 时（SQL Server、Oracle、SQLite），
+// 按错误类别返回标准值：23505 唯一约束、23503 外键、40P01 死锁、40001 序列化失败，无法归类时为空
+func (e *DBError) Code() string {
+	if e.SQLState != "" {
+		return e.SQLState
+	}
+	switch e.kind {
+	case dbErrorUniqueViolation:
+		return "23505"
+	case dbErrorForeignKeyViolation:
+		return "23503"
+	case dbErrorDeadlock:
+		return "40P01"
+	case dbErrorSerializationFailure:
+		return "40001"
+	}
+	return ""
+}
+
+// IsUniqueViolation reports whether a unique or primary key constraint was violated
+func (e *DBError) IsUniqueViolation() bool {
+	return e.kind == dbErrorUniqueViolation
+}
+
+// IsForeignKeyViolation reports whether a foreign key constraint was violated
+func (e *DBError) IsForeignKeyViolation() bool {
+	return e.kind == dbErrorForeignKeyViolation
+}
+
+// IsDeadlock reports whether the statement was chosen as a deadlock victim
+func (e *DBError) IsDeadlock() bool {
+	return e.kind == dbErrorDeadlock
+}
+
+// IsSerializationFailure reports whether the transaction failed because of a serialization conflict
+func (e *DBError) IsSerializationFailure() bool {
+	return e.kind == dbErrorSerializationFailure
+}
+
+// wrapDBError 把驱动错误包装为 *DBError，已包装或无法识别的错误原样返回
+func wrapDBError(driver DriverType, err error) error {
+	var de *DBError
+	if errors.As(err, &de) {
+		return err
+	}
+	if de = newDBError(driver, err); de != nil {
+		return de
+	}
+	return err
+}
+
+// newDBError 从错误链中提取驱动错误码并归类，driver 为空时按错误类型推断，
+// 既无错误码也无法按错误信息归类时返回 nil
+func newDBError(driver DriverType, err error) *DBError {
+	if err == nil {
+		return nil
+	}
+	de := &DBError{Driver: driver, Err: err}
+	found := false
+	for e := err; e != nil && !found; e = errors.Unwrap(e) {
+		found = de.readDriverError(e)
+	}
+
+	if de.Number != 0 {
+		de.kind = dbErrorNumbers[de.Driver][de.Number]
+	}
+	if de.kind == dbErrorOther && de.SQLState != "" {
+		de.kind = dbErrorSQLStates[de.SQLState]
+	}
+	if de.kind == dbErrorOther {
+		msg := err.Error()
+		for _, m := range dbErrorMessages {
+			if strings.Contains(msg, m.substr) {
+				de.kind = m.kind
+				break
+			}
+		}
+	}
+	if !found && de.kind == dbErrorOther {
+		return nil
+	}
+	return de
+}
+
+// readDriverError 识别常见驱动的错误类型并读取错误码，dbkit 本身不依赖这些驱动
+func (de *DBError) readDriverError(e error) bool {
+	// go-mssqldb (mssql.Error)，同样带有 Number 字段，需先于 MySQL 判断
+	if s, ok := e.(interface{ SQLErrorNumber() int32 }); ok {
+		de.setDriver(SQLServer)
+		de.Number = int64(s.SQLErrorNumber())
+		return true
+	}
+	// pgx (*pgconn.PgError) 与 lib/pq (*pq.Error) 均提供 SQLState()
+	if s, ok := e.(interface{ SQLState() string }); ok {
+		de.setDriver(PostgreSQL)
+		de.SQLState = s.SQLState()
+		return true
+	}
+	// mattn/go-sqlite3 (sqlite3.Error)
+	if code, ok := errorIntField(e, "ExtendedCode"); ok {
+		de.setDriver(SQLite3)
+		de.Number = code
+		return true
+	}
+	// sijms/go-ora (*network.OracleError)
+	if code, ok := errorIntField(e, "ErrCode"); ok {
+		de.setDriver(Oracle)
+		de.Number = code
+		return true
+	}
+	// go-sql-driver/mysql (*mysql.MySQLError) 只暴露 Number 与 SQLState 字段
+	if number, ok := errorIntField(e, "Number"); ok {
+		de.setDriver(MySQL)
+		de.Number = number
+		de.SQLState = errorSQLStateField(e)
+		return true
+	}
+	// godror (*godror.OraErr) 与 modernc.org/sqlite (*sqlite.Error) 均提供 Code() int，只能按已知驱动区分
+	if s, ok := e.(interface{ Code() int }); ok && (de.Driver == Oracle || de.Driver == SQLite3) {
+		de.Number = int64(s.Code())
+		return true
+	}
+	return false
+}
+
+func (de *DBError) setDriver(driver DriverType) {
+	if de.Driver == "" {
+		de.Driver = driver
+	}
+}
+
+// errorIntField 读取错误结构体中指定名称的整数字段
+func errorIntField(err error, name string) (int64, bool) {
+	v := errorStructValue(err)
+	if !v.IsValid() {
+		return 0, false
+	}
+	f := v.FieldByName(name)
+	if !f.IsValid() {
+		return 0, false
+	}
+	switch f.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return f.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(f.Uint()), true
+	}
+	return 0, false
+}
+
+// errorSQLStateField 读取 MySQL 错误的 SQLState [5]byte 字段
+func errorSQLStateField(err error) string {
+	v := errorStructValue(err)
+	if !v.IsValid() {
+		return ""
+	}
+	f := v.FieldByName("SQLState")
+	if !f.IsValid() || f.Kind() != reflect.Array || f.Type().Elem().Kind() != reflect.Uint8 {
+		return ""
+	}
+	b := make([]byte, f.Len())
+	for i := range b {
+		b[i] = byte(f.Index(i).Uint())
+	}
+	return strings.TrimRight(string(b), "\x00")
+}
+
+func errorStructValue(err error) reflect.Value {
+	v := reflect.ValueOf(err)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}
+	}
+	return v
+}
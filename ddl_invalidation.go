@@ -0,0 +1,161 @@
+package dbkit
+
+import (
+	"database/sql"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+var (
+	ddlStatementPattern = regexp.MustCompile(`(?is)^\s*(?:CREATE|ALTER|DROP|RENAME)\s`)
+	ddlTablePattern     = regexp.MustCompile("(?is)\\bTABLE\\s+(?:IF\\s+(?:NOT\\s+)?EXISTS\\s+)?(?:ONLY\\s+)?([\\w.`\"\\[\\]]+(?:\\s*,\\s*[\\w.`\"\\[\\]]+)*)")
+	ddlIndexPattern     = regexp.MustCompile("(?is)^\\s*CREATE\\s+.*?\\bINDEX\\b.*?\\bON\\s+([\\w.`\"\\[\\]]+)")
+)
+
+// ddlTables 判断 querySQL 是否为 CREATE/ALTER/DROP/RENAME 语句，并返回其涉及的表名（去掉 schema 与引号，小写）。
+// 是 DDL 但无法确定表（如 DROP VIEW）时 tables 为空
+func ddlTables(querySQL string) (tables []string, isDDL bool) {
+	if !ddlStatementPattern.MatchString(querySQL) {
+		return nil, false
+	}
+	var names []string
+	if m := ddlIndexPattern.FindStringSubmatch(querySQL); m != nil {
+		names = append(names, m[1])
+	} else if m := ddlTablePattern.FindStringSubmatch(querySQL); m != nil {
+		names = strings.Split(m[1], ",")
+	}
+	for _, name := range names {
+		name = strings.NewReplacer("`", "", `"`, "", "[", "", "]", "").Replace(strings.TrimSpace(name))
+		if name = strings.ToLower(unqualifiedName(name)); name != "" {
+			tables = append(tables, name)
+		}
+	}
+	return tables, true
+}
+
+// BindTableCache binds query cache repositories to a table on the default database.
+// 通过 dbkit 对该表执行 CREATE/ALTER/DROP/RENAME 后，这些仓库会被清空
+func BindTableCache(table string, repositories ...string) {
+	db, err := defaultDB()
+	if err != nil {
+		return
+	}
+	db.BindTableCache(table, repositories...)
+}
+
+// BindTableCache binds query cache repositories (the names passed to Cache) to a table.
+// DDL 执行成功后，除了总会清除的预编译语句与主键、自增列元数据外，还会在默认缓存和本地缓存中清空这些仓库，
+// 避免缓存的查询结果仍是表结构变更前的列。再次调用会替换该表之前绑定的仓库，不传仓库则解除绑定
+func (db *DB) BindTableCache(table string, repositories ...string) *DB {
+	if db.lastErr != nil || db.dbMgr == nil {
+		return db
+	}
+	if err := validateIdentifier(table); err != nil {
+		db.lastErr = err
+		return db
+	}
+	db.dbMgr.bindTableCache(table, repositories)
+	return db
+}
+
+func (mgr *dbManager) bindTableCache(table string, repositories []string) {
+	key := strings.ToLower(unqualifiedName(table))
+	mgr.mu.Lock()
+	defer mgr.mu.Unlock()
+	if len(repositories) == 0 {
+		delete(mgr.tableCaches, key)
+		return
+	}
+	if mgr.tableCaches == nil {
+		mgr.tableCaches = make(map[string][]string)
+	}
+	mgr.tableCaches[key] = append([]string(nil), repositories...)
+}
+
+// invalidateAfterDDL 在 DDL 成功执行后清除引用相关表的预编译语句、表元数据与列名缓存以及绑定的查询缓存；
+// 无法确定表时清除该数据库的全部预编译语句与元数据缓存
+func (mgr *dbManager) invalidateAfterDDL(querySQL string) {
+	tables, isDDL := ddlTables(querySQL)
+	if !isDDL {
+		return
+	}
+
+	mgr.mu.Lock()
+	var repos []string
+	for key := range mgr.pkCache {
+		if len(tables) == 0 || containsFold(tables, unqualifiedName(key)) {
+			delete(mgr.pkCache, key)
+		}
+	}
+	for key := range mgr.identityCache {
+		if len(tables) == 0 || containsFold(tables, unqualifiedName(key)) {
+			delete(mgr.identityCache, key)
+		}
+	}
+	for _, table := range tables {
+		repos = append(repos, mgr.tableCaches[table]...)
+	}
+	mgr.mu.Unlock()
+
+	mgr.closeCachedStmts(tables)
+	// 列名缓存同时决定 SELECT * 展开与租户列判断，表结构变更后需重新读取
+	mgr.clearJoinColumns(tables)
+	for _, table := range tables {
+		mgr.invalidateNegativeCache(table)
+	}
+	for _, repo := range repos {
+		GetCache().CacheClearRepository(repo)
+		if local := GetLocalCacheInstance(); local != GetCache() {
+			local.CacheClearRepository(repo)
+		}
+	}
+}
+
+// closeCachedStmts 关闭并移除本数据库中 SQL 引用了 tables 的缓存预编译语句，tables 为空时移除全部
+func (mgr *dbManager) closeCachedStmts(tables []string) {
+	lc, ok := GetLocalCacheInstance().(*localCache)
+	if !ok {
+		return
+	}
+	store, ok := lc.stores.Load(stmtCacheRepository)
+	if !ok {
+		return
+	}
+	var patterns []*regexp.Regexp
+	for _, table := range tables {
+		patterns = append(patterns, regexp.MustCompile(`(?i)(?:^|[^\w$])`+regexp.QuoteMeta(table)+`(?:$|[^\w$])`))
+	}
+	prefix := mgr.name + ":"
+	store.(*sync.Map).Range(func(key, value interface{}) bool {
+		cacheKey, _ := key.(string)
+		if !strings.HasPrefix(cacheKey, prefix) {
+			return true
+		}
+		matched := len(patterns) == 0
+		for _, p := range patterns {
+			if p.MatchString(cacheKey[len(prefix):]) {
+				matched = true
+				break
+			}
+		}
+		if matched {
+			store.(*sync.Map).Delete(key)
+			if entry, ok := value.(cacheEntry); ok {
+				if stmt, ok := entry.value.(*sql.Stmt); ok {
+					stmt.Close()
+				}
+			}
+		}
+		return true
+	})
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+package dbkit
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// deleteLimited 执行带 Limit 的 Delete，每次最多删除 limit 行，按 OrderBy 决定先删除哪些行：
+//   - MySQL: DELETE FROM t WHERE ... ORDER BY ... LIMIT n
+//   - 其他数据库: DELETE FROM t WHERE key IN (取前 n 个 key 的子查询)，key 为单列主键，SQLite 为 rowid
+//
+// 配置了软删除时转为对这些行的软删除，子查询只选取未删除的行，分批清理时不会反复命中同一批
+func (qb *QueryBuilder) deleteLimited(whereSql string) (int64, error) {
+	if qb.offset > 0 {
+		return 0, fmt.Errorf("dbkit: Delete does not support Offset")
+	}
+	mgr := qb.getDbManager()
+	if mgr == nil {
+		return 0, fmt.Errorf("dbkit: no database bound to the query builder")
+	}
+
+	var executor sqlExecutor
+	if qb.tx != nil {
+		executor = qb.tx.executor()
+	} else {
+		sdb, err := mgr.getDB()
+		if err != nil {
+			return 0, err
+		}
+		executor = qb.db.executor(sdb)
+	}
+
+	softDelete := !qb.ignoreSoftDelete && mgr.hasSoftDelete(qb.table)
+	if !softDelete && mgr.config.Driver == MySQL {
+		return mgr.deleteLimit(executor, qb.table, whereSql, qb.orderBy, qb.limit, qb.whereArgs...)
+	}
+
+	whereArgs := qb.whereArgs
+	if softDelete {
+		active, activeArgs := softDeleteActiveCondition(mgr.getSoftDeleteConfig(qb.table))
+		whereSql = fmt.Sprintf("(%s) AND %s", whereSql, active)
+		whereArgs = append(append([]interface{}(nil), whereArgs...), activeArgs...)
+	}
+	keyWhere, err := mgr.limitedKeyCondition(executor, qb.table, whereSql, qb.orderBy, qb.limit)
+	if err != nil {
+		return 0, err
+	}
+	if softDelete {
+		return mgr.softDelete(executor, qb.table, keyWhere, whereArgs...)
+	}
+	return mgr.forceDelete(executor, qb.table, keyWhere, whereArgs...)
+}
+
+// deleteLimit MySQL 原生 DELETE ... ORDER BY ... LIMIT n
+func (mgr *dbManager) deleteLimit(executor sqlExecutor, table, where, orderBy string, limit int, whereArgs ...interface{}) (int64, error) {
+	querySQL := fmt.Sprintf("DELETE FROM %s WHERE %s", table, where)
+	if orderBy != "" {
+		querySQL += " ORDER BY " + orderBy
+	}
+	querySQL += fmt.Sprintf(" LIMIT %d", limit)
+	querySQL, whereArgs = mgr.prepareQuerySQL(querySQL, whereArgs...)
+
+	start := time.Now()
+	result, err := executor.Exec(querySQL, whereArgs...)
+	err = mgr.logTrace(start, querySQL, whereArgs, err)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// limitedKeyCondition 生成 key IN (前 limit 行的 key) 条件，参数与 where 相同
+func (mgr *dbManager) limitedKeyCondition(executor sqlExecutor, table, where, orderBy string, limit int) (string, error) {
+	driver := mgr.config.Driver
+	key := "rowid"
+	if driver != SQLite3 {
+		pks, err := mgr.getPrimaryKeys(executor, table)
+		if err != nil {
+			return "", err
+		}
+		if len(pks) != 1 {
+			return "", fmt.Errorf("dbkit: Delete with Limit requires a single-column primary key on table %s", table)
+		}
+		key = pks[0]
+	}
+
+	var sb strings.Builder
+	if driver == SQLServer {
+		sb.WriteString(fmt.Sprintf("SELECT TOP (%d) %s FROM %s WHERE %s", limit, key, table, where))
+	} else {
+		sb.WriteString(fmt.Sprintf("SELECT %s FROM %s WHERE %s", key, table, where))
+	}
+	if orderBy != "" {
+		sb.WriteString(" ORDER BY " + orderBy)
+	}
+	sub := sb.String()
+
+	switch driver {
+	case MySQL:
+		// MySQL 不支持 IN 子查询中的 LIMIT，也不允许子查询读取被修改的表，包一层派生表
+		sub = fmt.Sprintf("SELECT %s FROM (%s LIMIT %d) AS dbkit_limited", key, sub, limit)
+	case Oracle:
+		sub = fmt.Sprintf("SELECT %s FROM (%s) WHERE ROWNUM <= %d", key, sub, limit)
+	case PostgreSQL, SQLite3:
+		sub += fmt.Sprintf(" LIMIT %d", limit)
+	}
+	return fmt.Sprintf("%s IN (%s)", key, sub), nil
+}
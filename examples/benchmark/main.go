@@ -159,6 +159,9 @@ func runBasicTests() {
 	// 测试更新（并发版本）
 	testConcurrentUpdate()
 
+	// 测试批量更新（CASE 单语句 vs 逐行更新）
+	testBatchUpdateCrossover()
+
 	// 测试删除（并发版本）
 	testConcurrentDelete()
 }
@@ -724,6 +727,62 @@ func testConcurrentUpdate() {
 	addResult("并发更新测试", dbkitTime, gormTime, UpdateCount)
 }
 
+// testBatchUpdateCrossover 对比 BatchUpdate（CASE 单语句）与逐行 Update 在不同批次大小下的耗时
+// 用于观察两种方式的交叉点
+func testBatchUpdateCrossover() {
+	fmt.Println("\n[测试 4.1] 批量更新交叉点 (BatchUpdate CASE vs 逐行 Update)")
+
+	err := connectDBKit(MaxConnections)
+	if err != nil {
+		log.Fatalf("DBKit连接失败: %v", err)
+	}
+	defer dbkit.Close()
+
+	createDBKitTable()
+	prepareDBKitData()
+
+	for _, size := range []int{1, 2, 5, 10, 20, 50, 100, 200} {
+		rounds := UpdateCount / size
+		if rounds == 0 {
+			rounds = 1
+		}
+
+		makeBatch := func(round int) []*dbkit.Record {
+			records := make([]*dbkit.Record, size)
+			for i := 0; i < size; i++ {
+				id := (round*size+i)%InsertCount + 1
+				records[i] = dbkit.NewRecord().
+					Set("id", id).
+					Set("age", 20+(round+i)%50).
+					Set("status", "updated")
+			}
+			return records
+		}
+
+		start := time.Now()
+		for r := 0; r < rounds; r++ {
+			for _, record := range makeBatch(r) {
+				id := record.Get("id")
+				record.Remove("id")
+				dbkit.Update(DbkitTable, record, "id = ?", id)
+			}
+		}
+		loopTime := time.Since(start)
+
+		start = time.Now()
+		for r := 0; r < rounds; r++ {
+			dbkit.BatchUpdate(DbkitTable, makeBatch(r), size)
+		}
+		caseTime := time.Since(start)
+
+		winner := "CASE"
+		if loopTime < caseTime {
+			winner = "逐行"
+		}
+		fmt.Printf("  批次大小 %3d: 逐行 %v, CASE %v (%s 更快)\n", size, loopTime, caseTime, winner)
+	}
+}
+
 // testConcurrentDelete 并发删除测试
 func testConcurrentDelete() {
 	fmt.Println("\n[测试 5] 并发删除测试 (Concurrent Delete)")
@@ -2034,6 +2093,8 @@ func writeDetailedReport(totalDbkit, totalGorm time.Duration, overall string, pr
 			}
 
 			fmt.Fprintf(f, "| GC次数 | %d | %d | - |\n", result.DBKitResult.GCCount, result.GORMResult.GCCount)
+			fmt.Fprintf(f, "| 连接池峰值占用 | %d/%d | %d/%d | - |\n", result.DBKitResult.PoolPeakInUse, result.DBKitResult.PoolMaxOpen, result.GORMResult.PoolPeakInUse, result.GORMResult.PoolMaxOpen)
+			fmt.Fprintf(f, "| 等待连接 | %d次 / %v | %d次 / %v | - |\n", result.DBKitResult.PoolWaitCount, result.DBKitResult.PoolWaitDuration.Round(time.Millisecond), result.GORMResult.PoolWaitCount, result.GORMResult.PoolWaitDuration.Round(time.Millisecond))
 			fmt.Fprintf(f, "| 性能等级 | %s | %s | - |\n\n", result.DBKitResult.PerformanceLevel, result.GORMResult.PerformanceLevel)
 		}
 
@@ -2129,6 +2190,45 @@ type StressTestResult struct {
 	MemoryMB         float64
 	GCCount          uint32
 	PerformanceLevel string
+	PoolMaxOpen      int           // 连接池最大连接数
+	PoolPeakInUse    int           // 测试期间采样到的最大使用中连接数
+	PoolWaitCount    int64         // 等待连接的总次数
+	PoolWaitDuration time.Duration // 等待连接的总时长
+}
+
+// poolSampler 在压力测试期间定期采样连接池，记录使用中连接数的峰值
+type poolSampler struct {
+	peakInUse int
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+// startPoolSampler 每 100ms 调用一次 inUse 采样
+func startPoolSampler(inUse func() int) *poolSampler {
+	s := &poolSampler{stop: make(chan struct{}), done: make(chan struct{})}
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if n := inUse(); n > s.peakInUse {
+					s.peakInUse = n
+				}
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+	return s
+}
+
+// Stop 停止采样并返回峰值
+func (s *poolSampler) Stop() int {
+	close(s.stop)
+	<-s.done
+	return s.peakInUse
 }
 
 // testDBKitCacheExtreme DBKit缓存极限压力测试
@@ -2554,6 +2654,8 @@ func runProgressiveTest(framework string, workers int, isDBKit bool) StressTestR
 
 	var successCount int64
 	var errorCount int64
+	var poolStats dbkit.PoolStats // 测试结束时的连接池统计
+	var peakInUse int
 	start := time.Now()
 	deadline := start.Add(time.Duration(StressTestTime) * time.Second)
 
@@ -2578,6 +2680,8 @@ func runProgressiveTest(framework string, workers int, isDBKit bool) StressTestR
 			dbkit.Insert("progressive_test_dbkit", record)
 		}
 
+		sampler := startPoolSampler(func() int { return dbkit.PoolSnapshot("postgres").InUse })
+
 		var wg sync.WaitGroup
 		wg.Add(workers)
 
@@ -2620,6 +2724,8 @@ func runProgressiveTest(framework string, workers int, isDBKit bool) StressTestR
 		}
 
 		wg.Wait()
+		peakInUse = sampler.Stop()
+		poolStats = dbkit.PoolSnapshot("postgres")
 		dbkit.Exec("DROP TABLE IF EXISTS progressive_test_dbkit")
 
 	} else {
@@ -2650,6 +2756,8 @@ func runProgressiveTest(framework string, workers int, isDBKit bool) StressTestR
 			gormDB.Table("progressive_test_gorm").Create(record)
 		}
 
+		sampler := startPoolSampler(func() int { return sqlDB.Stats().InUse })
+
 		var wg sync.WaitGroup
 		wg.Add(workers)
 
@@ -2699,6 +2807,16 @@ func runProgressiveTest(framework string, workers int, isDBKit bool) StressTestR
 		}
 
 		wg.Wait()
+		peakInUse = sampler.Stop()
+		stats := sqlDB.Stats()
+		poolStats = dbkit.PoolStats{
+			MaxOpenConnections: stats.MaxOpenConnections,
+			OpenConnections:    stats.OpenConnections,
+			InUse:              stats.InUse,
+			Idle:               stats.Idle,
+			WaitCount:          stats.WaitCount,
+			WaitDuration:       stats.WaitDuration,
+		}
 		gormDB.Exec("DROP TABLE IF EXISTS progressive_test_gorm")
 	}
 
@@ -2727,7 +2845,8 @@ func runProgressiveTest(framework string, workers int, isDBKit bool) StressTestR
 		performanceLevel = "⚠️ 性能待优化"
 	}
 
-	fmt.Printf(" TPS: %.0f, 成功率: %.1f%%\n", tps, successRate)
+	fmt.Printf(" TPS: %.0f, 成功率: %.1f%%, 连接峰值: %d/%d, 等待连接: %d次 %v\n",
+		tps, successRate, peakInUse, poolStats.MaxOpenConnections, poolStats.WaitCount, poolStats.WaitDuration.Round(time.Millisecond))
 
 	return StressTestResult{
 		TestName:         fmt.Sprintf("%s-%d协程", framework, workers),
@@ -2741,6 +2860,10 @@ func runProgressiveTest(framework string, workers int, isDBKit bool) StressTestR
 		MemoryMB:         float64(m.Alloc) / 1024 / 1024,
 		GCCount:          m.NumGC,
 		PerformanceLevel: performanceLevel,
+		PoolMaxOpen:      poolStats.MaxOpenConnections,
+		PoolPeakInUse:    peakInUse,
+		PoolWaitCount:    poolStats.WaitCount,
+		PoolWaitDuration: poolStats.WaitDuration,
 	}
 }
 
@@ -2821,6 +2944,8 @@ func generateProgressiveReport(results []ProgressiveTestResult) {
 		}
 
 		fmt.Printf("| GC次数 | %d | %d | - |\n", result.DBKitResult.GCCount, result.GORMResult.GCCount)
+		fmt.Printf("| 连接池峰值占用 | %d/%d | %d/%d | - |\n", result.DBKitResult.PoolPeakInUse, result.DBKitResult.PoolMaxOpen, result.GORMResult.PoolPeakInUse, result.GORMResult.PoolMaxOpen)
+		fmt.Printf("| 等待连接 | %d次 / %v | %d次 / %v | - |\n", result.DBKitResult.PoolWaitCount, result.DBKitResult.PoolWaitDuration.Round(time.Millisecond), result.GORMResult.PoolWaitCount, result.GORMResult.PoolWaitDuration.Round(time.Millisecond))
 		fmt.Printf("| 性能等级 | %s | %s | - |\n", result.DBKitResult.PerformanceLevel, result.GORMResult.PerformanceLevel)
 	}
 
@@ -1,6 +1,6 @@
 module cache_local
 
-go 1.23.0
+go 1.24.0
 
 replace github.com/zzguang83325/dbkit => ../../
 
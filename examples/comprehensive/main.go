@@ -435,7 +435,9 @@ func testGroupByHaving() {
 	// Basic GroupBy - Group records and aggregate
 	// 基本 GroupBy - 分组记录并聚合
 	stats, err := dbkit.Table("orders").
-		Select("status, COUNT(*) as count, SUM(amount) as total_amount").
+		Select("status").
+		SelectCount("*", "count").
+		SelectSum("amount", "total_amount").
 		GroupBy("status").
 		Find()
 	if err != nil {
@@ -450,7 +452,9 @@ func testGroupByHaving() {
 	// GroupBy + Having - Filter groups by aggregate condition
 	// GroupBy + Having - 按聚合条件过滤分组
 	userStats, err := dbkit.Table("orders").
-		Select("user_id, COUNT(*) as order_count, SUM(amount) as total_spent").
+		Select("user_id").
+		SelectCount("*", "order_count").
+		SelectSum("amount", "total_spent").
 		GroupBy("user_id").
 		Having("COUNT(*) >= ?", 2).
 		Find()
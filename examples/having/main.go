@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/zzguang83325/dbkit"
+	_ "github.com/zzguang83325/dbkit/drivers/mysql"
+	_ "github.com/zzguang83325/dbkit/drivers/postgres"
+	_ "github.com/zzguang83325/dbkit/drivers/sqlite"
+)
+
+// 同一段 GROUP BY / HAVING 代码在 SQLite、MySQL、PostgreSQL 上运行，对比各数据库的 HAVING 行为：
+//   - HavingCount 与 Having 中的聚合表达式在所有数据库上都可用，? 占位符按方言转换（PostgreSQL 为 $1）
+//   - HAVING 引用 SELECT 别名只有 SQLite、MySQL 支持，PostgreSQL 会报 column does not exist
+//
+// 连接不上的数据库会被跳过；可通过环境变量 MYSQL_DSN、POSTGRES_DSN 指定连接字符串
+
+type target struct {
+	name         string
+	driver       dbkit.DriverType
+	dsn          string
+	aliasAllowed bool // HAVING 是否可以引用 SELECT 别名
+}
+
+func main() {
+	targets := []target{
+		{"sqlite", dbkit.SQLite3, "file:having_demo.db?cache=shared&mode=rwc", true},
+		{"mysql", dbkit.MySQL, envOr("MYSQL_DSN", "root:123456@tcp(localhost:3306)/test?charset=utf8mb4&parseTime=True&loc=Local"), true},
+		{"postgresql", dbkit.PostgreSQL, envOr("POSTGRES_DSN", "user=postgres password=123456 host=127.0.0.1 port=5432 dbname=postgres sslmode=disable"), false},
+	}
+	defer os.Remove("having_demo.db")
+
+	dbkit.SetDebugMode(true)
+	failed := 0
+	for _, t := range targets {
+		fmt.Printf("\n========== %s ==========\n", t.name)
+		if err := dbkit.OpenDatabaseWithDBName(t.name, t.driver, t.dsn, 5); err != nil {
+			fmt.Printf("跳过 %s: %v\n", t.name, err)
+			continue
+		}
+		if err := dbkit.Use(t.name).Ping(); err != nil {
+			fmt.Printf("跳过 %s: %v\n", t.name, err)
+			continue
+		}
+		failed += runDemo(t)
+	}
+	dbkit.Close()
+
+	if failed > 0 {
+		log.Fatalf("%d 项检查未通过", failed)
+	}
+	fmt.Println("\n✓ 所有检查通过")
+}
+
+// runDemo 返回未通过的检查数
+func runDemo(t target) int {
+	db := dbkit.Use(t.name)
+	db.Exec("DROP TABLE IF EXISTS having_orders")
+	if _, err := db.Exec("CREATE TABLE having_orders (id INT PRIMARY KEY, dept VARCHAR(20), amount INT)"); err != nil {
+		fmt.Printf("✗ 创建表失败: %v\n", err)
+		return 1
+	}
+	defer db.Exec("DROP TABLE IF EXISTS having_orders")
+
+	rows := []struct {
+		dept   string
+		amount int
+	}{{"sales", 50}, {"sales", 80}, {"sales", 30}, {"hr", 40}, {"hr", 20}, {"it", 500}}
+	for i, r := range rows {
+		record := dbkit.NewRecord().Set("id", i+1).Set("dept", r.dept).Set("amount", r.amount)
+		if _, err := db.Insert("having_orders", record); err != nil {
+			fmt.Printf("✗ 插入失败: %v\n", err)
+			return 1
+		}
+	}
+
+	failed := 0
+	check := func(label string, ok bool, detail interface{}) {
+		if ok {
+			fmt.Printf("✓ %s: %v\n", label, detail)
+		} else {
+			fmt.Printf("✗ %s: %v\n", label, detail)
+			failed++
+		}
+	}
+
+	// 1. HavingCount：HAVING COUNT(*) >= ?
+	list, err := db.Table("having_orders").
+		Select("dept, COUNT(*) AS cnt").
+		GroupBy("dept").
+		HavingCount(">=", 2).
+		OrderBy("dept").
+		Find()
+	check("HavingCount(\">=\", 2)", err == nil && depts(list) == "[hr sales]", orErr(depts(list), err))
+
+	// 2. 聚合表达式 + 参数：HAVING SUM(amount) > ?
+	list, err = db.Table("having_orders").
+		Select("dept, SUM(amount) AS total").
+		GroupBy("dept").
+		Having("SUM(amount) > ?", 100).
+		OrderBy("dept").
+		Find()
+	check("Having(\"SUM(amount) > ?\", 100)", err == nil && depts(list) == "[it sales]", orErr(depts(list), err))
+
+	// 3. 多个 HAVING 条件与 WHERE 参数混用，占位符按顺序编号
+	list, err = db.Table("having_orders").
+		Select("dept, COUNT(*) AS cnt").
+		Where("amount > ?", 25).
+		GroupBy("dept").
+		HavingCount(">", 1).
+		Having("MAX(amount) < ?", 100).
+		Find()
+	check("WHERE + HavingCount + Having", err == nil && depts(list) == "[sales]", orErr(depts(list), err))
+
+	// 4. HAVING 引用 SELECT 别名：SQLite、MySQL 可用，PostgreSQL 报错
+	list, err = db.Table("having_orders").
+		Select("dept, SUM(amount) AS total").
+		GroupBy("dept").
+		Having("total > ?", 100).
+		OrderBy("dept").
+		Find()
+	if t.aliasAllowed {
+		check("HAVING 引用别名（应可用）", err == nil && depts(list) == "[it sales]", orErr(depts(list), err))
+	} else {
+		check("HAVING 引用别名（应报错）", err != nil, err)
+	}
+	return failed
+}
+
+func depts(list []dbkit.Record) string {
+	names := make([]string, 0, len(list))
+	for _, r := range list {
+		names = append(names, r.Str("dept"))
+	}
+	return fmt.Sprint(names)
+}
+
+func orErr(v interface{}, err error) interface{} {
+	if err != nil {
+		return err
+	}
+	return v
+}
+
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
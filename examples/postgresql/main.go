@@ -35,6 +35,7 @@ func main() {
 	demoDbModelOperations()
 	demoChainOperations()
 	demoBatchOperations() // 新增批量操作测试
+	demoColumnTypes()     // 字符串值按列类型转换
 	demoCacheOperations()
 	demoTimeoutOperations() // 新增超时测试
 	demoPoolMonitoring()    // 新增连接池监控测试
@@ -176,6 +177,38 @@ func demoChainOperations() {
 	fmt.Printf("✓ 链式分页查询: 总计 %d 行\n", page.TotalRow)
 }
 
+func demoColumnTypes() {
+	fmt.Println("\n【列类型转换测试】")
+	db := dbkit.Use("postgresql")
+
+	// 表单、CSV 等来源的值都是字符串，直接绑定到 INT / DECIMAL / BOOLEAN / DATE 列时 PostgreSQL 会报类型错误
+	db.ConfigColumnTypes("demo", map[string]dbkit.ColumnKind{
+		"age":       dbkit.ColumnInt,
+		"salary":    dbkit.ColumnFloat,
+		"is_active": dbkit.ColumnBool,
+		"birthday":  dbkit.ColumnTime,
+	})
+	defer db.RemoveColumnTypes("demo")
+
+	record := dbkit.NewRecord().
+		Set("name", "Form_User").
+		Set("age", "35").
+		Set("salary", "8500.50").
+		Set("is_active", "true").
+		Set("birthday", "1990-06-15").
+		Set("metadata", `{"source": "form"}`)
+	id, err := db.Insert("demo", record)
+	if err != nil {
+		log.Printf("  ✗ 插入失败: %v", err)
+		return
+	}
+	fmt.Printf("  ✓ 字符串值已按列类型绑定，插入 ID: %d\n", id)
+
+	// 无法转换的值在发送到数据库之前返回错误
+	_, err = db.Insert("demo", dbkit.NewRecord().Set("name", "Bad_User").Set("age", "abc"))
+	fmt.Printf("  ✓ 非法值被拒绝: %v\n", err)
+}
+
 func demoBatchOperations() {
 	fmt.Println("\n【批量操作测试】")
 
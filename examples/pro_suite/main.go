@@ -180,9 +180,11 @@ func seedData() {
 	}
 	aid, _ := article.Insert()
 
-	// 4. Link Categories
-	(&models.ArticleCategory{ArticleID: aid, CategoryID: 1}).Insert()
-	(&models.ArticleCategory{ArticleID: aid, CategoryID: 3}).Insert()
+	// 4. Link Categories (re-runnable: existing links are skipped)
+	for _, cid := range []int64{1, 3} {
+		link := dbkit.NewRecord().Set("article_id", aid).Set("category_id", cid)
+		dbkit.Use("default").InsertIgnore("pro_article_categories", link, "article_id", "category_id")
+	}
 
 	// 5. Add Comments
 	comments := []*models.Comment{
@@ -205,22 +207,26 @@ func testAdvancedQueryBuilder() {
 	// - Belong to 'Tech' or 'Finance' categories
 	// - Have more than 1 comment
 	
-	subqueryCats := dbkit.NewSubquery().
-		Table("pro_categories").
-		Select("id").
-		Where("name IN (?, ?)", "Tech", "Finance")
-
-	subqueryArticleIDs := dbkit.NewSubquery().
-		Table("pro_article_categories").
-		Select("article_id").
-		Where("category_id IN (SELECT id FROM pro_categories WHERE name IN (?, ?))", "Tech", "Finance")
+	// The inner query joins categories, so it is passed as a full QueryBuilder
+	articleIDs := dbkit.Table("pro_article_categories").
+		Select("pro_article_categories.article_id").
+		InnerJoin("pro_categories", "pro_categories.id = pro_article_categories.category_id").
+		Where("pro_categories.name IN (?, ?)", "Tech", "Finance")
 
 	results, err := dbkit.Table("pro_articles").
-		Select("pro_articles.*, pro_users.username as author_name").
+		Select("pro_users.username as author_name").
+		SelectTable("pro_articles").
 		InnerJoin("pro_users", "pro_articles.author_id = pro_users.id").
-		WhereIn("pro_articles.id", subqueryArticleIDs).
+		WhereInBuilder("pro_articles.id", articleIDs).
+		// (published) OR (credits > 500)
 		WhereGroup(func(qb *dbkit.QueryBuilder) *dbkit.QueryBuilder {
-			return qb.Where("status = ?", "published").OrWhere("credits > ?", 500)
+			return qb.
+				WhereGroup(func(g *dbkit.QueryBuilder) *dbkit.QueryBuilder {
+					return g.Where("pro_articles.status = ?", "published")
+				}).
+				OrWhereGroup(func(g *dbkit.QueryBuilder) *dbkit.QueryBuilder {
+					return g.Where("pro_users.credits > ?", 500)
+				})
 		}).
 		OrderBy("created_at DESC").
 		Find()
@@ -233,7 +239,6 @@ func testAdvancedQueryBuilder() {
 			fmt.Printf("  - [%s] by %s\n", r.Str("title"), r.Str("author_name"))
 		}
 	}
-	_ = subqueryCats // Avoid unused warning
 }
 
 func testRealWorldTransactions() {
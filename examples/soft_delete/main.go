@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"log"
+	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/zzguang83325/dbkit"
@@ -21,6 +22,8 @@ import (
 //     强制删除：永久删除记录
 //   - Query control: Support querying active, deleted, or all records
 //     查询控制：支持查询活跃、已删除或所有记录
+//   - Cascade: Delete and restore child rows together with the parent
+//     级联：随父记录一起删除、恢复子表记录
 //
 // Use Cases / 使用场景:
 //   - User deactivation but retain historical data / 用户注销但保留历史数据
@@ -165,9 +168,49 @@ func main() {
 	fmt.Println("   Restored user 4 via QueryBuilder")
 	printUsers("Final state")
 
+	// ========================================================================
+	// 示例 8: 级联软删除与恢复
+	// ========================================================================
+	// 说明: 配置级联后删除文章会同时删除其评论，恢复文章只恢复与文章同一时间删除的评论
+	// 效果: 文章删除前已单独删除的评论保持删除状态
+	fmt.Println("\n8. Cascade soft delete and restore...")
+	cascadeDemo()
+
 	fmt.Println("\n=== Demo Complete ===")
 }
 
+// ============================================================================
+// 辅助函数：级联软删除演示
+// ============================================================================
+func cascadeDemo() {
+	dbkit.Exec("CREATE TABLE posts (id INTEGER PRIMARY KEY, title TEXT, deleted_at DATETIME)")
+	dbkit.Exec("CREATE TABLE comments (id INTEGER PRIMARY KEY, post_id INTEGER, body TEXT, deleted_at DATETIME)")
+	dbkit.ConfigSoftDelete("posts", "deleted_at")
+	dbkit.ConfigSoftDelete("comments", "deleted_at")
+	dbkit.ConfigSoftDeleteCascade("posts", []dbkit.CascadeRule{
+		{Table: "comments", ForeignKey: "post_id"},
+	})
+
+	dbkit.Exec("INSERT INTO posts (id, title) VALUES (1, 'Hello')")
+	dbkit.Exec("INSERT INTO comments (id, post_id, body) VALUES (1, 1, 'first'), (2, 1, 'spam')")
+
+	// 先单独删除评论 2，删除时间精确到秒，间隔一秒以区分两次删除
+	dbkit.Delete("comments", "id = ?", 2)
+	time.Sleep(1100 * time.Millisecond)
+
+	dbkit.Delete("posts", "id = ?", 1)
+	count, _ := dbkit.Table("comments").Count()
+	fmt.Printf("   After deleting post 1: %d active comments\n", count)
+
+	dbkit.Restore("posts", "id = ?", 1)
+	records, _ := dbkit.Table("comments").Find()
+	fmt.Printf("   After restoring post 1: %d active comments\n", len(records))
+	if len(records) != 1 || records[0].GetInt("id") != 1 {
+		log.Fatalf("   expected only comment 1 to be restored, got %v", records)
+	}
+	fmt.Println("   Comment 2 was deleted on its own and stays deleted")
+}
+
 // ============================================================================
 // 辅助函数：打印用户列表
 // ============================================================================
@@ -17,6 +17,14 @@ func main() {
 	if err != nil {
 		log.Fatalf("SQLite数据库连接失败: %v", err)
 	}
+	// WAL 模式 + busy_timeout，减少并发写入时的 "database is locked"
+	if err := dbkit.ConfigSQLite("sqlite", dbkit.SQLiteOptions{
+		JournalMode: "WAL",
+		BusyTimeout: 5 * time.Second,
+		ForeignKeys: true,
+	}); err != nil {
+		log.Fatalf("SQLite PRAGMA 配置失败: %v", err)
+	}
 	dbkit.SetDebugMode(true)
 
 	setupTable()
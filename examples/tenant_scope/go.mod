@@ -0,0 +1,10 @@
+module tenant_scope
+
+go 1.25.5
+
+require (
+	github.com/mattn/go-sqlite3 v1.14.33
+	github.com/zzguang83325/dbkit v0.0.0-00010101000000-000000000000
+)
+
+replace github.com/zzguang83325/dbkit => ../../
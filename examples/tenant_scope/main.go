@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/zzguang83325/dbkit"
+)
+
+// ============================================================================
+// DBKit Tenant Scope Demo
+// DBKit 多租户隔离演示
+// ============================================================================
+// Features / 功能说明:
+//   - Queries, updates and deletes are limited to the tenant in the context
+//     查询、更新、删除只作用于上下文中的租户
+//   - Inserts fill the tenant column automatically
+//     插入时自动填充租户列
+//   - Updating or deleting another tenant's row by primary key affects nothing
+//     按主键更新、删除其他租户的行不会生效
+// ============================================================================
+
+func main() {
+	err := dbkit.OpenDatabaseWithDBName("tenant", dbkit.SQLite3, ":memory:", 10)
+	if err != nil {
+		log.Fatal(err)
+	}
+	_, err = dbkit.Use("tenant").Exec(`
+		CREATE TABLE orders (
+			id INTEGER PRIMARY KEY,
+			tenant_id INTEGER NOT NULL,
+			amount INTEGER
+		)
+	`)
+	if err != nil {
+		log.Fatal(err)
+	}
+	dbkit.Use("tenant").EnableTenantScope("tenant_id")
+
+	// 1. Each tenant inserts its own order / 两个租户各插入一条订单
+	tenantA := dbkit.Use("tenant").WithContext(dbkit.WithTenant(context.Background(), 1))
+	tenantB := dbkit.Use("tenant").WithContext(dbkit.WithTenant(context.Background(), 2))
+	if _, err := tenantA.Insert("orders", dbkit.NewRecord().Set("id", 1).Set("amount", 100)); err != nil {
+		log.Fatal(err)
+	}
+	if _, err := tenantB.Insert("orders", dbkit.NewRecord().Set("id", 2).Set("amount", 200)); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("1. Tenant 1 owns order 1, tenant 2 owns order 2")
+
+	// 2. Tenant 1 tries to touch order 2 by primary key / 租户 1 按主键修改、删除订单 2
+	fmt.Println("\n2. Tenant 1 updates and deletes order 2 by primary key")
+	affected, err := tenantA.Update("orders", dbkit.NewRecord().Set("amount", 0), "id = ?", 2)
+	check("Update", affected, err)
+
+	affected, err = tenantA.BatchUpdate("orders", []*dbkit.Record{dbkit.NewRecord().Set("id", 2).Set("amount", 0)}, 100)
+	check("BatchUpdate", affected, err)
+
+	rows, err := tenantA.UpdateReturning("orders", dbkit.NewRecord().Set("amount", 0), nil, "id = ?", 2)
+	check("UpdateReturning", int64(len(rows)), err)
+
+	affected, err = tenantA.BatchDeleteByIds("orders", []interface{}{2}, 100)
+	check("BatchDeleteByIds", affected, err)
+
+	affected, err = tenantA.Delete("orders", "id = ?", 2)
+	check("Delete", affected, err)
+
+	// 3. Order 2 is unchanged / 订单 2 保持不变
+	order, err := tenantB.Table("orders").Where("id = ?", 2).FindFirst()
+	if err != nil || order == nil || order.GetInt("amount") != 200 {
+		log.Fatalf("order 2 was modified by tenant 1: %v, %v", order, err)
+	}
+	fmt.Printf("\n3. Tenant 2 still sees order 2 with amount %d\n", order.GetInt("amount"))
+
+	// 4. Without a tenant in the context writes are rejected / 上下文中没有租户时拒绝写入
+	_, err = dbkit.Use("tenant").Delete("orders", "id = ?", 2)
+	fmt.Printf("\n4. Delete without tenant: %v\n", err)
+}
+
+// check 确认跨租户的写操作没有影响任何行
+func check(op string, affected int64, err error) {
+	if err != nil {
+		log.Fatalf("   %s: %v", op, err)
+	}
+	if affected != 0 {
+		log.Fatalf("   %s: cross-tenant write affected %d rows", op, affected)
+	}
+	fmt.Printf("   %s: 0 rows affected, rejected\n", op)
+}
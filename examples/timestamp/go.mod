@@ -1,6 +1,6 @@
 module timestamp_example
 
-go 1.23.0
+go 1.24.0
 
 require (
 	github.com/mattn/go-sqlite3 v1.14.33
@@ -0,0 +1,67 @@
+package dbkit
+
+import (
+	"context"
+)
+
+// ExecInfo is the outcome of ExecResult
+type ExecInfo struct {
+	RowsAffected int64 // 受影响的行数
+	LastInsertId int64 // 最后插入的自增 ID，LastInsertIdSupported 为 false 时恒为 0
+	// LastInsertIdSupported 表示当前驱动能否返回 LastInsertId：MySQL 与 SQLite 支持；
+	// PostgreSQL、SQL Server、Oracle 的驱动不支持，需要改用 RETURNING / OUTPUT 子句
+	LastInsertIdSupported bool
+}
+
+// ExecResult executes a statement and returns both the affected row count and the last insert id.
+// 与 Exec 不同，驱动不支持 LastInsertId 时不会得到一个无法区分的 0，而是 LastInsertIdSupported 为 false；
+// LastInsertId 只对 INSERT 语句有意义
+func ExecResult(querySQL string, args ...interface{}) (ExecInfo, error) {
+	db, err := defaultDB()
+	if err != nil {
+		return ExecInfo{}, err
+	}
+	return db.ExecResult(querySQL, args...)
+}
+
+// ExecResult executes a statement and returns both the affected row count and the last insert id
+func (db *DB) ExecResult(querySQL string, args ...interface{}) (ExecInfo, error) {
+	if db.lastErr != nil {
+		return ExecInfo{}, db.lastErr
+	}
+	sdb, err := db.dbMgr.getDB()
+	if err != nil {
+		return ExecInfo{}, err
+	}
+	ctx, cancel := db.getContext()
+	defer cancel()
+	return db.dbMgr.execResult(ctx, db.executor(sdb), querySQL, args...)
+}
+
+// ExecResult executes a statement within transaction and returns both the affected row count and the last insert id
+func (tx *Tx) ExecResult(querySQL string, args ...interface{}) (ExecInfo, error) {
+	ctx, cancel := tx.getContext()
+	defer cancel()
+	return tx.dbMgr.execResult(ctx, tx.executor(), querySQL, args...)
+}
+
+func (mgr *dbManager) execResult(ctx context.Context, executor sqlExecutor, querySQL string, args ...interface{}) (ExecInfo, error) {
+	result, err := mgr.execWithContext(ctx, executor, querySQL, args...)
+	if err != nil {
+		return ExecInfo{}, err
+	}
+
+	var info ExecInfo
+	if info.RowsAffected, err = result.RowsAffected(); err != nil {
+		return ExecInfo{}, err
+	}
+	// pgx、lib/pq、go-mssqldb 调用 LastInsertId 返回错误，go-ora 返回 0，只信任已知支持的驱动
+	switch mgr.config.Driver {
+	case MySQL, SQLite3:
+		if id, idErr := result.LastInsertId(); idErr == nil {
+			info.LastInsertId = id
+			info.LastInsertIdSupported = true
+		}
+	}
+	return info, nil
+}
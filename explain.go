@@ -0,0 +1,144 @@
+package dbkit
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Explain returns the execution plan of the SQL statement without executing it
+// PostgreSQL: EXPLAIN (FORMAT JSON)；MySQL: EXPLAIN FORMAT=JSON；SQLite: EXPLAIN QUERY PLAN；
+// SQL Server: SET SHOWPLAN_XML ON；Oracle: EXPLAIN PLAN FOR + DBMS_XPLAN.DISPLAY
+func Explain(querySQL string, args ...interface{}) ([]Record, error) {
+	db, err := defaultDB()
+	if err != nil {
+		return nil, err
+	}
+	return db.Explain(querySQL, args...)
+}
+
+// ExplainAnalyze executes the SQL statement and returns the actual execution plan
+// 仅支持 PostgreSQL（EXPLAIN (ANALYZE, FORMAT JSON)）和 MySQL 8.0.18+（EXPLAIN ANALYZE）
+// 注意：语句会被真正执行，对 INSERT/UPDATE/DELETE 请在事务中调用并回滚
+func ExplainAnalyze(querySQL string, args ...interface{}) ([]Record, error) {
+	db, err := defaultDB()
+	if err != nil {
+		return nil, err
+	}
+	return db.ExplainAnalyze(querySQL, args...)
+}
+
+// Explain returns the execution plan of the SQL statement without executing it
+func (db *DB) Explain(querySQL string, args ...interface{}) ([]Record, error) {
+	return db.explain(false, querySQL, args...)
+}
+
+// ExplainAnalyze executes the SQL statement and returns the actual execution plan
+func (db *DB) ExplainAnalyze(querySQL string, args ...interface{}) ([]Record, error) {
+	return db.explain(true, querySQL, args...)
+}
+
+func (db *DB) explain(analyze bool, querySQL string, args ...interface{}) ([]Record, error) {
+	if db.lastErr != nil {
+		return nil, db.lastErr
+	}
+	ctx, cancel := db.getContext()
+	defer cancel()
+	sdb, err := db.dbMgr.getDB()
+	if err != nil {
+		return nil, err
+	}
+	return db.dbMgr.explain(ctx, db.executor(sdb), analyze, querySQL, args...)
+}
+
+// Explain returns the execution plan of the SQL statement within transaction
+func (tx *Tx) Explain(querySQL string, args ...interface{}) ([]Record, error) {
+	ctx, cancel := tx.getContext()
+	defer cancel()
+	return tx.dbMgr.explain(ctx, tx.executor(), false, querySQL, args...)
+}
+
+// ExplainAnalyze executes the SQL statement within transaction and returns the actual execution plan
+func (tx *Tx) ExplainAnalyze(querySQL string, args ...interface{}) ([]Record, error) {
+	ctx, cancel := tx.getContext()
+	defer cancel()
+	return tx.dbMgr.explain(ctx, tx.executor(), true, querySQL, args...)
+}
+
+// Explain returns the execution plan of the query built by the QueryBuilder
+func (qb *QueryBuilder) Explain() ([]Record, error) {
+	if qb.lastErr != nil {
+		return nil, qb.lastErr
+	}
+	qb, err := qb.tenantScoped()
+	if err != nil {
+		return nil, err
+	}
+	querySQL, args := qb.buildSelectSql()
+	if qb.tx != nil {
+		return qb.tx.Explain(querySQL, args...)
+	}
+	return qb.db.Explain(querySQL, args...)
+}
+
+func (mgr *dbManager) explain(ctx context.Context, executor sqlExecutor, analyze bool, querySQL string, args ...interface{}) ([]Record, error) {
+	driver := mgr.config.Driver
+
+	switch driver {
+	case PostgreSQL:
+		if analyze {
+			return mgr.queryWithContext(ctx, executor, "EXPLAIN (ANALYZE, FORMAT JSON) "+querySQL, args...)
+		}
+		return mgr.queryWithContext(ctx, executor, "EXPLAIN (FORMAT JSON) "+querySQL, args...)
+	case MySQL:
+		if analyze {
+			return mgr.queryWithContext(ctx, executor, "EXPLAIN ANALYZE "+querySQL, args...)
+		}
+		return mgr.queryWithContext(ctx, executor, "EXPLAIN FORMAT=JSON "+querySQL, args...)
+	case SQLite3:
+		if analyze {
+			return nil, fmt.Errorf("dbkit: EXPLAIN ANALYZE is not supported by %s", driver)
+		}
+		return mgr.queryWithContext(ctx, executor, "EXPLAIN QUERY PLAN "+querySQL, args...)
+	case SQLServer, Oracle:
+		if analyze {
+			return nil, fmt.Errorf("dbkit: EXPLAIN ANALYZE is not supported by %s", driver)
+		}
+		// SHOWPLAN 与 PLAN_TABLE 都是会话级的，需要在同一个连接上执行多条语句
+		// 非事务时开启一个只用于读取计划的事务，结束后回滚
+		if sdb, ok := unwrapExecutor(executor).(*sql.DB); ok {
+			tx, err := sdb.BeginTx(ctx, nil)
+			if err != nil {
+				return nil, err
+			}
+			defer tx.Rollback()
+			executor = rewrapExecutor(executor, tx)
+		}
+		if driver == SQLServer {
+			return mgr.explainSQLServer(ctx, executor, querySQL, args...)
+		}
+		return mgr.explainOracle(ctx, executor, querySQL, args...)
+	default:
+		return nil, fmt.Errorf("dbkit: EXPLAIN is not supported by driver %s", driver)
+	}
+}
+
+// explainSQLServer 通过 SET SHOWPLAN_XML ON 获取估算执行计划，语句不会被执行
+func (mgr *dbManager) explainSQLServer(ctx context.Context, executor sqlExecutor, querySQL string, args ...interface{}) ([]Record, error) {
+	if _, err := mgr.execWithContext(ctx, executor, "SET SHOWPLAN_XML ON"); err != nil {
+		return nil, err
+	}
+	defer mgr.execWithContext(context.Background(), executor, "SET SHOWPLAN_XML OFF")
+	return mgr.queryWithContext(ctx, executor, querySQL, args...)
+}
+
+// explainOracle 通过 EXPLAIN PLAN FOR 写入 PLAN_TABLE，再用 DBMS_XPLAN.DISPLAY 读取
+// 参数随语句一起绑定，驱动会校验绑定变量的个数，缺少参数时 EXPLAIN PLAN 会报错
+func (mgr *dbManager) explainOracle(ctx context.Context, executor sqlExecutor, querySQL string, args ...interface{}) ([]Record, error) {
+	// 与普通查询一致地处理 LIMIT 改写
+	querySQL, args = mgr.prepareQuerySQL(querySQL, args...)
+	if _, err := mgr.execWithContext(ctx, executor, "EXPLAIN PLAN FOR "+querySQL, args...); err != nil {
+		return nil, err
+	}
+	return mgr.queryWithContext(ctx, executor, "SELECT PLAN_TABLE_OUTPUT FROM TABLE(DBMS_XPLAN.DISPLAY())")
+}
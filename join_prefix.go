@@ -0,0 +1,206 @@
+package dbkit
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// joinColumnPrefix 控制多表 JOIN 查询是否为重名列加上表名前缀
+var joinColumnPrefix atomic.Bool
+
+// joinColumnsCache 数据库名 + "\x00" + 表名 -> 列名，展开 SELECT * 时使用
+var joinColumnsCache sync.Map
+
+var (
+	selectStarPattern      = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)\.\*$`)
+	selectQualifiedPattern = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)\.([A-Za-z_][A-Za-z0-9_]*)$`)
+	selectBarePattern      = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	selectAliasPattern     = regexp.MustCompile(`(?i)\s(?:as\s+)?([A-Za-z_][A-Za-z0-9_]*)$`)
+)
+
+// SetJoinColumnPrefix makes QueryBuilder queries with joins return columns whose names collide
+// under "table.column" keys (e.g. users.name and departments.name) instead of letting the last one win.
+// 只处理 SELECT 中的 *、table.* 和 table.column 形式，不重名的列以及表达式、已指定别名的列保持原样；
+// 展开 * 时需要查询一次表结构，结果会被缓存
+func SetJoinColumnPrefix(enabled bool) {
+	joinColumnPrefix.Store(enabled)
+}
+
+// selectItem SELECT 列表中的一项
+type selectItem struct {
+	sql    string // 原样输出的 SQL
+	name   string // 结果集中的列名（小写），未知时为空
+	ref    string // table.column 形式中的表名
+	column string
+}
+
+// prefixJoinColumns 展开 SELECT 列表并为重名列加上 "ref.column" 别名，无法解析时原样返回
+func (qb *QueryBuilder) prefixJoinColumns(selectPart string, driver DriverType) string {
+	mgr := qb.getDbManager()
+	if mgr == nil {
+		return selectPart
+	}
+
+	// 列前缀（不含 schema 的表名）-> 表名，按 FROM/JOIN 顺序
+	refs := []string{unqualifiedName(qb.table)}
+	tables := map[string]string{strings.ToLower(refs[0]): qb.table}
+	for _, join := range qb.joins {
+		ref := unqualifiedName(join.table)
+		if _, ok := tables[strings.ToLower(ref)]; !ok {
+			refs = append(refs, ref)
+			tables[strings.ToLower(ref)] = join.table
+		}
+	}
+
+	expandRef := func(ref string) ([]selectItem, bool) {
+		table, ok := tables[strings.ToLower(ref)]
+		if !ok {
+			return nil, false
+		}
+		columns, err := mgr.joinTableColumns(table)
+		if err != nil || len(columns) == 0 {
+			return nil, false
+		}
+		items := make([]selectItem, 0, len(columns))
+		for _, col := range columns {
+			items = append(items, selectItem{sql: ref + "." + col, name: strings.ToLower(col), ref: ref, column: col})
+		}
+		return items, true
+	}
+
+	var items []selectItem
+	for _, raw := range splitSelectList(selectPart) {
+		item := strings.TrimSpace(raw)
+		switch {
+		case item == "*":
+			for _, ref := range refs {
+				expanded, ok := expandRef(ref)
+				if !ok {
+					return selectPart
+				}
+				items = append(items, expanded...)
+			}
+		case selectStarPattern.MatchString(item):
+			expanded, ok := expandRef(selectStarPattern.FindStringSubmatch(item)[1])
+			if !ok {
+				return selectPart
+			}
+			items = append(items, expanded...)
+		case selectQualifiedPattern.MatchString(item):
+			m := selectQualifiedPattern.FindStringSubmatch(item)
+			items = append(items, selectItem{sql: item, name: strings.ToLower(m[2]), ref: m[1], column: m[2]})
+		case selectBarePattern.MatchString(item):
+			items = append(items, selectItem{sql: item, name: strings.ToLower(item)})
+		default:
+			name := ""
+			if m := selectAliasPattern.FindStringSubmatch(item); m != nil {
+				name = strings.ToLower(m[1])
+			}
+			items = append(items, selectItem{sql: item, name: name})
+		}
+	}
+
+	counts := make(map[string]int, len(items))
+	for _, item := range items {
+		if item.name != "" {
+			counts[item.name]++
+		}
+	}
+	parts := make([]string, 0, len(items))
+	for _, item := range items {
+		if item.ref != "" && counts[item.name] > 1 {
+			parts = append(parts, item.sql+" AS "+quoteAlias(driver, item.ref+"."+item.column))
+		} else {
+			parts = append(parts, item.sql)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// joinTableColumns 返回表的列名（带缓存）
+func (mgr *dbManager) joinTableColumns(table string) ([]string, error) {
+	key := mgr.name + "\x00" + strings.ToLower(table)
+	if cached, ok := joinColumnsCache.Load(key); ok {
+		return cached.([]string), nil
+	}
+	infos, err := mgr.getTableColumns(table)
+	if err != nil {
+		return nil, err
+	}
+	columns := make([]string, 0, len(infos))
+	for _, info := range infos {
+		columns = append(columns, info.Name)
+	}
+	joinColumnsCache.Store(key, columns)
+	return columns, nil
+}
+
+// clearJoinColumns 删除本数据库中 tables 的列名缓存，tables 为空时删除全部
+func (mgr *dbManager) clearJoinColumns(tables []string) {
+	prefix := mgr.name + "\x00"
+	joinColumnsCache.Range(func(key, _ interface{}) bool {
+		cacheKey, _ := key.(string)
+		if !strings.HasPrefix(cacheKey, prefix) {
+			return true
+		}
+		if len(tables) == 0 || containsFold(tables, unqualifiedName(cacheKey[len(prefix):])) {
+			joinColumnsCache.Delete(key)
+		}
+		return true
+	})
+}
+
+// splitSelectList 按顶层逗号拆分 SELECT 列表，忽略括号和引号内的逗号
+func splitSelectList(s string) []string {
+	var items []string
+	depth := 0
+	var quote byte
+	start := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if quote != 0 {
+			if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '\'', '"', '`':
+			quote = c
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				items = append(items, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(items, s[start:])
+}
+
+// unqualifiedName 去掉 schema 前缀
+func unqualifiedName(name string) string {
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
+
+// quoteAlias 按方言为包含特殊字符（如 "."）的列别名加引号
+func quoteAlias(driver DriverType, alias string) string {
+	if selectBarePattern.MatchString(alias) {
+		return alias
+	}
+	switch driver {
+	case MySQL:
+		return "`" + alias + "`"
+	case SQLServer:
+		return "[" + alias + "]"
+	}
+	return `"` + alias + `"`
+}
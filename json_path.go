@@ -0,0 +1,138 @@
+package dbkit
+
+import (
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"strings"
+)
+
+// jsonPathPattern 支持的 JSON 路径：$.a.b、$.items[0].name，不带前导 $ 时自动补全
+var (
+	jsonPathPattern        = regexp.MustCompile(`^\$(?:\.[A-Za-z_][A-Za-z0-9_]*|\[[0-9]+\])+$`)
+	jsonPathSegmentPattern = regexp.MustCompile(`\.([A-Za-z_][A-Za-z0-9_]*)|\[([0-9]+)\]`)
+)
+
+// maxJSONIndexNameLength 生成的索引名长度上限，兼容 Oracle 12.1 及更早版本的 30 字符限制
+const maxJSONIndexNameLength = 30
+
+// normalizeJSONPath 校验并规范化 JSON 路径，返回以 $ 开头的形式
+func normalizeJSONPath(path string) (string, error) {
+	p := strings.TrimSpace(path)
+	if !strings.HasPrefix(p, "$") {
+		if !strings.HasPrefix(p, "[") {
+			p = "." + p
+		}
+		p = "$" + p
+	}
+	if !jsonPathPattern.MatchString(p) {
+		return "", fmt.Errorf("dbkit: invalid JSON path '%s', only object keys and array indexes are supported (e.g. $.profile.tags[0])", path)
+	}
+	return p, nil
+}
+
+// jsonExtractExpr 返回按方言从 column 中提取 path 对应值的 SQL 表达式，path 须已规范化
+func jsonExtractExpr(driver DriverType, column, path string) string {
+	switch driver {
+	case MySQL:
+		return fmt.Sprintf("%s->>'%s'", column, path)
+	case PostgreSQL:
+		var keys []string
+		for _, m := range jsonPathSegmentPattern.FindAllStringSubmatch(path, -1) {
+			keys = append(keys, m[1]+m[2])
+		}
+		return fmt.Sprintf("%s #>> '{%s}'", column, strings.Join(keys, ","))
+	case SQLServer, Oracle:
+		return fmt.Sprintf("JSON_VALUE(%s, '%s')", column, path)
+	default:
+		return fmt.Sprintf("json_extract(%s, '%s')", column, path)
+	}
+}
+
+// OrderByJSON appends the value at path inside the JSON column to the ORDER BY clause.
+// 按方言生成提取表达式：MySQL column->>'$.a'、PostgreSQL column #>> '{a}'、SQL Server / Oracle JSON_VALUE、SQLite json_extract；
+// 除 SQLite 外按提取出的文本排序。可多次调用累加排序列，与 OrderByColumn 相同
+func (qb *QueryBuilder) OrderByJSON(column, path string, dir OrderDir) *QueryBuilder {
+	if qb.lastErr != nil {
+		return qb
+	}
+	jsonPath, err := normalizeJSONPath(path)
+	if err != nil {
+		qb.lastErr = err
+		return qb
+	}
+	return qb.addOrderColumn(column, dir, "", func(driver DriverType, column string) string {
+		return jsonExtractExpr(driver, column, jsonPath)
+	})
+}
+
+// CreateJSONIndex creates an index on the value at path inside a JSON column on the default database
+func CreateJSONIndex(table, column, path string) error {
+	db, err := defaultDB()
+	if err != nil {
+		return err
+	}
+	return db.CreateJSONIndex(table, column, path)
+}
+
+// CreateJSONIndex creates an index on the value at path inside a JSON column, so OrderByJSON and
+// lookups on the same path can use it.
+// PostgreSQL、SQLite、Oracle 创建表达式（函数）索引；MySQL 添加 VIRTUAL 生成列并为其建索引，
+// SQL Server 添加计算列并为其建索引，优化器会把相同的表达式匹配到该列。
+// 生成列、计算列命名为 column_路径，索引名为 idx_表名_column_路径，超长时截断并加哈希后缀
+func (db *DB) CreateJSONIndex(table, column, path string) error {
+	if db.lastErr != nil {
+		return db.lastErr
+	}
+	if err := validateIdentifier(table); err != nil {
+		return err
+	}
+	if err := validateIdentifier(column); err != nil {
+		return err
+	}
+	jsonPath, err := normalizeJSONPath(path)
+	if err != nil {
+		return err
+	}
+
+	var slug []string
+	for _, m := range jsonPathSegmentPattern.FindAllStringSubmatch(jsonPath, -1) {
+		slug = append(slug, m[1]+m[2])
+	}
+	derived := column + "_" + strings.Join(slug, "_")
+	indexName := jsonIndexName("idx_" + unqualifiedName(table) + "_" + derived)
+	expr := jsonExtractExpr(db.dbMgr.config.Driver, column, jsonPath)
+
+	var statements []string
+	switch db.dbMgr.config.Driver {
+	case MySQL:
+		statements = []string{fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s VARCHAR(255) GENERATED ALWAYS AS (%s) VIRTUAL, ADD INDEX %s (%s)",
+			table, derived, expr, indexName, derived)}
+	case SQLServer:
+		statements = []string{
+			fmt.Sprintf("ALTER TABLE %s ADD %s AS %s", table, derived, expr),
+			fmt.Sprintf("CREATE INDEX %s ON %s (%s)", indexName, table, derived),
+		}
+	case PostgreSQL:
+		statements = []string{fmt.Sprintf("CREATE INDEX %s ON %s ((%s))", indexName, table, expr)}
+	default:
+		statements = []string{fmt.Sprintf("CREATE INDEX %s ON %s (%s)", indexName, table, expr)}
+	}
+	for _, stmt := range statements {
+		if _, err := db.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// jsonIndexName 超过长度上限时截断并追加名称的哈希，保证不同路径生成的索引名不冲突
+func jsonIndexName(name string) string {
+	if len(name) <= maxJSONIndexNameLength {
+		return name
+	}
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	suffix := fmt.Sprintf("_%08x", h.Sum32())
+	return name[:maxJSONIndexNameLength-len(suffix)] + suffix
+}
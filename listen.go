@@ -0,0 +1,126 @@
+package dbkit
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+)
+
+// Notification is a PostgreSQL NOTIFY message received by Listen
+type Notification struct {
+	Channel string // 通知所在的频道
+	Payload string // NOTIFY 携带的内容（可能为空）
+	PID     uint32 // 发送通知的后端进程 ID
+}
+
+// listenBufferSize 通知通道的缓冲大小，消费者处理较慢时服务端通知会在连接上排队
+const listenBufferSize = 64
+
+// Listen subscribes to a PostgreSQL channel on the default database.
+// 从连接池中取出一个专用连接执行 LISTEN，并把收到的通知发送到返回的通道，
+// ctx 取消或连接出错时通道关闭，该连接被丢弃而不会归还连接池。
+// 需要使用 pgx 驱动（github.com/zzguang83325/dbkit/drivers/postgres）
+func Listen(ctx context.Context, channel string) (<-chan Notification, error) {
+	db, err := defaultDB()
+	if err != nil {
+		return nil, err
+	}
+	return db.Listen(ctx, channel)
+}
+
+// Listen subscribes to a PostgreSQL channel on this database
+func (db *DB) Listen(ctx context.Context, channel string) (<-chan Notification, error) {
+	if db.lastErr != nil {
+		return nil, db.lastErr
+	}
+	if db.dbMgr.config.Driver != PostgreSQL {
+		return nil, fmt.Errorf("dbkit: Listen is only supported on PostgreSQL, got %s", db.dbMgr.config.Driver)
+	}
+	if err := validateIdentifier(channel); err != nil {
+		return nil, err
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// 先确认驱动支持，再执行 LISTEN 并启动接收 goroutine
+	var waitErr error
+	conn.conn.Raw(func(driverConn interface{}) error {
+		_, waitErr = notificationWaiter(driverConn)
+		return nil
+	})
+	if waitErr != nil {
+		conn.Close()
+		return nil, waitErr
+	}
+	if _, err := conn.Exec("LISTEN " + channel); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	ch := make(chan Notification, listenBufferSize)
+	go func() {
+		defer close(ch)
+		defer conn.Close()
+		// 返回 ErrBadConn 让 database/sql 关闭该连接，避免仍处于 LISTEN 状态的连接回到连接池
+		conn.conn.Raw(func(driverConn interface{}) error {
+			wait, _ := notificationWaiter(driverConn)
+			for {
+				n, err := wait(ctx)
+				if err != nil {
+					if ctx.Err() == nil {
+						LogError("LISTEN 连接接收通知失败", map[string]interface{}{
+							"database": db.dbMgr.name,
+							"channel":  channel,
+							"error":    err.Error(),
+						})
+					}
+					return driver.ErrBadConn
+				}
+				select {
+				case ch <- *n:
+				case <-ctx.Done():
+					return driver.ErrBadConn
+				}
+			}
+		})
+	}()
+	return ch, nil
+}
+
+// notificationWaiter 通过反射调用 pgx 的 (*stdlib.Conn).Conn().WaitForNotification(ctx)，
+// 使 dbkit 本身不依赖 pgx
+func notificationWaiter(driverConn interface{}) (func(ctx context.Context) (*Notification, error), error) {
+	unsupported := fmt.Errorf("dbkit: Listen requires the pgx driver (github.com/zzguang83325/dbkit/drivers/postgres), got %T", driverConn)
+
+	connMethod := reflect.ValueOf(driverConn).MethodByName("Conn")
+	if !connMethod.IsValid() || connMethod.Type().NumIn() != 0 || connMethod.Type().NumOut() != 1 {
+		return nil, unsupported
+	}
+	pgxConn := connMethod.Call(nil)[0]
+	wait := pgxConn.MethodByName("WaitForNotification")
+	if !wait.IsValid() || wait.Type().NumIn() != 1 || wait.Type().NumOut() != 2 {
+		return nil, unsupported
+	}
+
+	return func(ctx context.Context) (*Notification, error) {
+		out := wait.Call([]reflect.Value{reflect.ValueOf(ctx)})
+		if err, _ := out[1].Interface().(error); err != nil {
+			return nil, err
+		}
+		n := reflect.Indirect(out[0])
+		if !n.IsValid() {
+			return nil, fmt.Errorf("dbkit: received empty notification")
+		}
+		return &Notification{
+			Channel: n.FieldByName("Channel").String(),
+			Payload: n.FieldByName("Payload").String(),
+			PID:     uint32(n.FieldByName("PID").Uint()),
+		}, nil
+	}, nil
+}
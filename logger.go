@@ -190,6 +190,22 @@ func LogSQLError(dbName string, sql string, args []interface{}, duration time.Du
 	currentLogger.Log(LevelError, "SQL failed log", fields)
 }
 
+// logDebugSQL logs a statement of a QueryBuilder.Debug chain at info level, independent of debug mode
+func logDebugSQL(dbName string, sql string, args []interface{}, duration time.Duration, err error) {
+	fields := map[string]interface{}{
+		"db":       dbName,
+		"sql":      cleanSQL(sql),
+		"duration": duration.String(),
+	}
+	if len(args) > 0 {
+		fields["args"] = args
+	}
+	if err != nil {
+		fields["error"] = err.Error()
+	}
+	currentLogger.Log(LevelInfo, "SQL debug log", fields)
+}
+
 // LogInfo logs info message
 func LogInfo(msg string, fields ...map[string]interface{}) {
 	var f map[string]interface{}
@@ -0,0 +1,168 @@
+package dbkit
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+)
+
+// 中间件收到的操作类型
+const (
+	OpQuery = "query" // 返回结果集的语句（Query、QueryFirst、Paginate、Count 等）
+	OpExec  = "exec"  // 不返回结果集的语句（Exec、Insert、Update、Delete 等）
+)
+
+// Middleware rewrites or rejects a statement before it is executed.
+// op 为 OpQuery 或 OpExec；sql 为最终发送给数据库的语句（占位符已转换为当前方言，如 PostgreSQL 的 $1），
+// args 为对应参数。返回新的 sql 与 args 继续执行，返回 error 则不执行该语句并把错误返回给调用方
+type Middleware func(ctx context.Context, op string, sql string, args []interface{}) (string, []interface{}, error)
+
+var (
+	middlewares   []Middleware
+	middlewaresMu sync.RWMutex
+)
+
+// UseMiddleware registers a middleware invoked before every statement executed through dbkit on any database.
+// 多个中间件按注册顺序串联，前一个的输出作为后一个的输入。注册后创建的 DB、Tx、Conn 操作才会使用新的中间件；
+// 连接初始化（OnConnect）、健康检查等内部语句不经过中间件。
+// 按 ? 编写 SQL 时注意：修改 PostgreSQL/SQL Server/Oracle 语句的参数个数需要同时调整占位符编号
+func UseMiddleware(fn Middleware) {
+	if fn == nil {
+		return
+	}
+	middlewaresMu.Lock()
+	defer middlewaresMu.Unlock()
+	// 复制后追加，已取得旧切片的 executor 不受影响
+	chain := make([]Middleware, len(middlewares), len(middlewares)+1)
+	copy(chain, middlewares)
+	middlewares = append(chain, fn)
+}
+
+// ClearMiddlewares removes all middlewares registered with UseMiddleware
+func ClearMiddlewares() {
+	middlewaresMu.Lock()
+	defer middlewaresMu.Unlock()
+	middlewares = nil
+}
+
+func currentMiddlewares() []Middleware {
+	middlewaresMu.RLock()
+	defer middlewaresMu.RUnlock()
+	return middlewares
+}
+
+// middlewareExecutor 包装 executor，在每条语句执行前依次调用中间件
+type middlewareExecutor struct {
+	sqlExecutor
+	chain []Middleware
+	ctx   context.Context // 创建 executor 的 DB / Tx / Conn 的上下文，不带 context 的调用传给中间件
+}
+
+// wrapMiddleware 在注册了中间件时为 executor 加上中间件包装，ctx 为 DB / Tx / Conn 的上下文
+func wrapMiddleware(ctx context.Context, executor sqlExecutor) sqlExecutor {
+	chain := currentMiddlewares()
+	if len(chain) == 0 {
+		return executor
+	}
+	return &middlewareExecutor{sqlExecutor: executor, chain: chain, ctx: ctx}
+}
+
+// baseContext 返回不带 context 的调用使用的上下文，未设置时为 context.Background()
+func (e *middlewareExecutor) baseContext() context.Context {
+	if e.ctx == nil {
+		return context.Background()
+	}
+	return e.ctx
+}
+
+// executorContext 返回 executor 上中间件包装保存的上下文，没有中间件时为 context.Background()
+func executorContext(executor sqlExecutor) context.Context {
+	if m, ok := executor.(*middlewareExecutor); ok {
+		return m.baseContext()
+	}
+	return context.Background()
+}
+
+func (e *middlewareExecutor) apply(ctx context.Context, op, query string, args []interface{}) (string, []interface{}, error) {
+	var err error
+	for _, fn := range e.chain {
+		query, args, err = fn(ctx, op, query, args)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+	return query, args, nil
+}
+
+// runMiddlewares 对 executor 上的中间件执行一次，并返回去掉中间件包装的 executor，
+// 使后续的预编译语句缓存、日志与 DDL 缓存失效都基于改写后的 SQL
+func runMiddlewares(ctx context.Context, executor sqlExecutor, op, query string, args []interface{}) (sqlExecutor, string, []interface{}, error) {
+	m, ok := executor.(*middlewareExecutor)
+	if !ok {
+		return executor, query, args, nil
+	}
+	query, args, err := m.apply(ctx, op, query, args)
+	return m.sqlExecutor, query, args, err
+}
+
+// queryRowScan 执行单行查询并扫描到 dest，中间件拒绝执行时返回中间件的错误
+func queryRowScan(executor sqlExecutor, query string, args []interface{}, dest ...interface{}) error {
+	executor, query, args, err := runMiddlewares(executorContext(executor), executor, OpQuery, query, args)
+	if err != nil {
+		return err
+	}
+	return executor.QueryRow(query, args...).Scan(dest...)
+}
+
+func (e *middlewareExecutor) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return e.QueryContext(e.baseContext(), query, args...)
+}
+
+func (e *middlewareExecutor) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return e.ExecContext(e.baseContext(), query, args...)
+}
+
+func (e *middlewareExecutor) QueryRow(query string, args ...interface{}) *sql.Row {
+	return e.QueryRowContext(e.baseContext(), query, args...)
+}
+
+func (e *middlewareExecutor) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	query, args, err := e.apply(ctx, OpQuery, query, args)
+	if err != nil {
+		return nil, err
+	}
+	if execCtx, ok := e.sqlExecutor.(sqlExecutorContext); ok {
+		return execCtx.QueryContext(ctx, query, args...)
+	}
+	return e.sqlExecutor.Query(query, args...)
+}
+
+func (e *middlewareExecutor) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	query, args, err := e.apply(ctx, OpExec, query, args)
+	if err != nil {
+		return nil, err
+	}
+	if execCtx, ok := e.sqlExecutor.(sqlExecutorContext); ok {
+		return execCtx.ExecContext(ctx, query, args...)
+	}
+	return e.sqlExecutor.Exec(query, args...)
+}
+
+// QueryRowContext 中间件返回错误时无法构造带该错误的 *sql.Row，改为在已取消的 context 上执行，
+// Scan 返回 context.Canceled；dbkit 内部通过 queryRowScan 执行单行查询以返回原始错误
+func (e *middlewareExecutor) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	rewritten, rewrittenArgs, err := e.apply(ctx, OpQuery, query, args)
+	execCtx, ok := e.sqlExecutor.(sqlExecutorContext)
+	if err != nil {
+		if ok {
+			canceled, cancel := context.WithCancel(ctx)
+			cancel()
+			return execCtx.QueryRowContext(canceled, query, args...)
+		}
+		return e.sqlExecutor.QueryRow(query, args...)
+	}
+	if ok {
+		return execCtx.QueryRowContext(ctx, rewritten, rewrittenArgs...)
+	}
+	return e.sqlExecutor.QueryRow(rewritten, rewrittenArgs...)
+}
@@ -2,6 +2,7 @@ package dbkit
 
 import (
 	"fmt"
+	"reflect"
 	"strings"
 	"time"
 )
@@ -96,6 +97,94 @@ func PaginateModel_FullSql[T IDbModel](model T, cache *ModelCache, page, pageSiz
 	return RecordPageToDbModelPage[T](recordsPage)
 }
 
+// saveModel 根据主键是否为零值决定插入或更新 DbModel
+// 主键为零值时执行插入，并将生成的自增 ID（以及乐观锁初始版本号）回写到结构体；
+// 否则按主键更新，启用乐观锁时带版本检查，成功后结构体中的版本号同步加 1
+func (mgr *dbManager) saveModel(executor sqlExecutor, model IDbModel) (int64, error) {
+	table := model.TableName()
+	record := ToRecord(model)
+	pks, err := mgr.getPrimaryKeys(executor, table)
+	if err != nil {
+		return 0, err
+	}
+	if len(pks) == 0 {
+		return mgr.insert(executor, table, record)
+	}
+
+	isNew := false
+	for _, pk := range pks {
+		if isZeroValue(record.Get(pk)) {
+			isNew = true
+			record.Remove(pk)
+		}
+	}
+
+	lock := mgr.getOptimisticLockConfig(table)
+	if isNew {
+		// 版本字段为零值时交给 applyVersionInit 初始化为 1
+		if lock != nil && lock.VersionField != "" && isZeroValue(record.Get(lock.VersionField)) {
+			record.Remove(lock.VersionField)
+		}
+		id, err := mgr.insert(executor, table, record)
+		if err != nil {
+			return 0, err
+		}
+		if len(pks) == 1 && id > 0 {
+			if err := setModelColumn(model, pks[0], id); err != nil {
+				return id, err
+			}
+		}
+		if lock != nil && lock.VersionField != "" && record.Has(lock.VersionField) {
+			if err := setModelColumn(model, lock.VersionField, record.Get(lock.VersionField)); err != nil {
+				return id, err
+			}
+		}
+		return id, nil
+	}
+
+	version, versionChecked := int64(0), false
+	if mgr.enableOptimisticLockCheck {
+		version, versionChecked = mgr.getVersionFromRecord(table, record)
+	}
+	affected, err := mgr.updateRecord(executor, table, record)
+	if err != nil {
+		return 0, err
+	}
+	if versionChecked && affected > 0 {
+		if err := setModelColumn(model, lock.VersionField, version+1); err != nil {
+			return affected, err
+		}
+	}
+	return affected, nil
+}
+
+// isZeroValue 判断主键值是否为零值（nil、0、空字符串等）
+func isZeroValue(val interface{}) bool {
+	if val == nil {
+		return true
+	}
+	return reflect.ValueOf(val).IsZero()
+}
+
+// setModelColumn 将值写回 DbModel 中列名匹配（不区分大小写）的字段
+func setModelColumn(model IDbModel, column string, value interface{}) error {
+	val := reflect.ValueOf(model)
+	if val.Kind() != reflect.Ptr || val.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	structVal := val.Elem()
+	for _, fieldInfo := range getStructCacheInfo(structVal.Type()).fields {
+		if !fieldInfo.canSet || !strings.EqualFold(fieldInfo.columnName, column) {
+			continue
+		}
+		if err := setFieldValue(structVal.Field(fieldInfo.fieldIndex), value); err != nil {
+			return fmt.Errorf("dbkit: failed to set field for column '%s': %v", column, err)
+		}
+		return nil
+	}
+	return nil
+}
+
 // --- Soft Delete Model Helpers ---
 
 // ForceDeleteModel performs a physical delete on a soft-delete enabled model
@@ -110,7 +199,7 @@ func ForceDeleteModel(model IDbModel) (int64, error) {
 		return 0, err
 	}
 
-	pks, err := db.dbMgr.getPrimaryKeys(sdb, model.TableName())
+	pks, err := db.dbMgr.getPrimaryKeys(db.executor(sdb), model.TableName())
 	if err != nil {
 		return 0, err
 	}
@@ -141,7 +230,7 @@ func RestoreModel(model IDbModel) (int64, error) {
 		return 0, err
 	}
 	// Get primary keys
-	pks, err := db.dbMgr.getPrimaryKeys(sdb, model.TableName())
+	pks, err := db.dbMgr.getPrimaryKeys(db.executor(sdb), model.TableName())
 	if err != nil {
 		return 0, err
 	}
@@ -0,0 +1,90 @@
+package dbkit
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// offsetFetchMinVersions 支持 OFFSET ... FETCH 的最低主版本：Oracle 12c、SQL Server 2012（11.x）
+var offsetFetchMinVersions = map[DriverType]int{
+	Oracle:    12,
+	SQLServer: 11,
+}
+
+// qualifiedColumnPattern 匹配 ORDER BY 中的 table.column，用于在外层查询中改写为 column
+var qualifiedColumnPattern = regexp.MustCompile(`\b[A-Za-z_][A-Za-z0-9_]*\.([A-Za-z_][A-Za-z0-9_]*)`)
+
+// legacyPaging 判断分页是否需要使用 ROWNUM / ROW_NUMBER() 包装代替 OFFSET ... FETCH。
+// Oracle 开启 Config.OracleLegacyPaging 时总是使用；否则只读取建立连接池时的版本检测结果。
+// 未能检测时 Oracle 沿用所有版本都支持的 ROWNUM 包装，SQL Server 按新版本处理
+func (mgr *dbManager) legacyPaging() bool {
+	if mgr.config.Driver == Oracle && mgr.config.OracleLegacyPaging {
+		return true
+	}
+	mgr.mu.RLock()
+	defer mgr.mu.RUnlock()
+	if mgr.legacyPagingDetected == nil {
+		return mgr.config.Driver == Oracle
+	}
+	return *mgr.legacyPagingDetected
+}
+
+// detectLegacyPaging 在新建的连接池上查询 Oracle / SQL Server 的版本，判断是否为不支持 OFFSET ... FETCH 的旧版本，
+// 其他数据库及无法检测（如没有权限）时返回 nil
+func detectLegacyPaging(driver DriverType, db *sql.DB) *bool {
+	minVersion, ok := offsetFetchMinVersions[driver]
+	if !ok {
+		return nil
+	}
+	versionSQL := "SELECT CAST(SERVERPROPERTY('ProductVersion') AS NVARCHAR(128))"
+	if driver == Oracle {
+		versionSQL = "SELECT VERSION FROM PRODUCT_COMPONENT_VERSION WHERE PRODUCT LIKE 'Oracle%' AND ROWNUM = 1"
+	}
+	var version string
+	if db.QueryRow(versionSQL).Scan(&version) != nil {
+		return nil
+	}
+	major, _ := parseMajorMinor(version)
+	if major == 0 {
+		return nil
+	}
+	legacy := major < minVersion
+	return &legacy
+}
+
+// pageRowNumberColumn 是旧版本分页包装中的行号列，扫描结果时会被去掉
+const pageRowNumberColumn = "dbkit_rn"
+
+// isPageRowNumberColumn 判断结果列是否为分页包装添加的行号列（Oracle 返回大写的 DBKIT_RN）
+func isPageRowNumberColumn(column string) bool {
+	return strings.EqualFold(column, pageRowNumberColumn)
+}
+
+// legacyPageSQL 为不支持 OFFSET ... FETCH 的旧版本生成分页语句，limit 为 0 表示不限制行数；
+// 包装添加的 dbkit_rn 行号列由 scanRecords / scanRows 去掉，不会出现在结果中
+//   - Oracle 11g 及更早: ROWNUM 包装
+//   - SQL Server 2008 及更早: ROW_NUMBER() OVER (ORDER BY ...) 包装，外层排序中的 table.column 改写为 column，
+//     因此排序列必须出现在查询结果中
+func (mgr *dbManager) legacyPageSQL(querySQL string, offset, limit int) string {
+	if mgr.config.Driver == Oracle {
+		if limit > 0 {
+			return fmt.Sprintf("SELECT * FROM (SELECT dbkit_page.*, ROWNUM dbkit_rn FROM (%s) dbkit_page WHERE ROWNUM <= %d) WHERE dbkit_rn > %d",
+				querySQL, offset+limit, offset)
+		}
+		return fmt.Sprintf("SELECT * FROM (SELECT dbkit_page.*, ROWNUM dbkit_rn FROM (%s) dbkit_page) WHERE dbkit_rn > %d", querySQL, offset)
+	}
+
+	orderBy := "(SELECT NULL)"
+	if idx := topLevelOrderByIndex(strings.ToLower(querySQL)); idx >= 0 {
+		orderBy = qualifiedColumnPattern.ReplaceAllString(strings.TrimSpace(querySQL[idx+len(" order by "):]), "$1")
+		querySQL = querySQL[:idx]
+	}
+	where := fmt.Sprintf("dbkit_rn > %d", offset)
+	if limit > 0 {
+		where += fmt.Sprintf(" AND dbkit_rn <= %d", offset+limit)
+	}
+	return fmt.Sprintf("SELECT * FROM (SELECT ROW_NUMBER() OVER (ORDER BY %s) AS dbkit_rn, dbkit_page.* FROM (%s) dbkit_page) dbkit_numbered WHERE %s ORDER BY dbkit_rn",
+		orderBy, querySQL, where)
+}
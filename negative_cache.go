@@ -0,0 +1,178 @@
+package dbkit
+
+import (
+	"database/sql"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// negativeCacheMarker 缓存中表示"查询无结果"的占位值，使用字符串以便 Redis 缓存原样存取
+const negativeCacheMarker = "__dbkit_not_found__"
+
+// negativeCachePruneInterval 每登记多少个负缓存条目清理一次已过期的登记
+const negativeCachePruneInterval = 256
+
+// dmlTablePattern 匹配 INSERT/REPLACE/UPDATE/DELETE/MERGE 语句开头的目标表
+var dmlTablePattern = regexp.MustCompile("(?is)^\\s*(?:INSERT\\s+(?:IGNORE\\s+|OR\\s+\\w+\\s+)?INTO|REPLACE\\s+INTO|UPDATE(?:\\s+ONLY)?|DELETE\\s+FROM|MERGE\\s+INTO)\\s+([\\w.`\"\\[\\]]+)")
+
+var (
+	negativeCacheTTL time.Duration // 0 表示不缓存"未找到"结果
+	negativeIndex    = &negativeCacheIndex{tables: make(map[string]map[string]negativeCacheRef)}
+	negativePending  = &negativePendingTables{txs: make(map[*sql.Tx]map[negativeTableRef]bool)}
+)
+
+// negativeTableRef 某个数据库上的一张表
+type negativeTableRef struct {
+	mgr   *dbManager
+	table string
+}
+
+// negativePendingTables 记录事务中写过的表。提交前其他连接仍查不到新数据，
+// 可能在写入之后、提交之前重新缓存"未找到"，因此提交后需要再清除一次
+type negativePendingTables struct {
+	txs map[*sql.Tx]map[negativeTableRef]bool
+	mu  sync.Mutex
+}
+
+// negativeCacheRef 一条负缓存条目的位置
+type negativeCacheRef struct {
+	cache     CacheProvider
+	repo      string
+	key       string
+	expiresAt time.Time
+}
+
+// negativeCacheIndex 记录每张表上的负缓存条目，写入该表时据此删除
+type negativeCacheIndex struct {
+	tables map[string]map[string]negativeCacheRef // dbName + "\x00" + table -> repo + "\x00" + key -> ref
+	mu     sync.Mutex
+}
+
+// SetNegativeCacheTTL enables caching of "not found" QueryFirst results for d.
+// 开启缓存的 QueryFirst/FindFirst 查不到记录时，也会在 d 时间内缓存该结果，避免反复查询不存在的 ID；
+// 之后通过 dbkit 对相关表的 Insert/Save/Update/BatchInsert 等写操作会立即清除这些条目，
+// Exec 执行的 INSERT/UPDATE/DELETE/MERGE 语句按语句开头的目标表清除（以 WITH 开头、调用存储过程、
+// 触发器等无法确定目标表的写入不会清除，只能等待过期）。d <= 0 关闭负缓存（默认）
+func SetNegativeCacheTTL(d time.Duration) {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	if d < 0 {
+		d = 0
+	}
+	negativeCacheTTL = d
+}
+
+func getNegativeCacheTTL() time.Duration {
+	cacheMu.RLock()
+	defer cacheMu.RUnlock()
+	return negativeCacheTTL
+}
+
+// isNegativeCacheValue 判断缓存值是否为负缓存占位值（Redis 返回 []byte）
+func isNegativeCacheValue(val interface{}) bool {
+	switch v := val.(type) {
+	case string:
+		return v == negativeCacheMarker
+	case []byte:
+		return string(v) == negativeCacheMarker
+	}
+	return false
+}
+
+func negativeIndexKey(dbName, table string) string {
+	return dbName + "\x00" + strings.ToLower(table)
+}
+
+// cacheNotFound 缓存"未找到"结果，并按 querySQL 中涉及的表登记，ttl 为正常结果的 TTL
+func (mgr *dbManager) cacheNotFound(cache CacheProvider, repo, key, querySQL string, ttl time.Duration) {
+	negTTL := getNegativeCacheTTL()
+	if negTTL <= 0 {
+		return
+	}
+	if ttl > 0 && ttl < negTTL {
+		negTTL = ttl
+	}
+	tables := mgr.extractTablesFromSQL(querySQL)
+	if len(tables) == 0 {
+		// 无法确定表时不缓存，否则写入后无法失效
+		return
+	}
+
+	cache.CacheSet(repo, key, negativeCacheMarker, negTTL)
+
+	ref := negativeCacheRef{cache: cache, repo: repo, key: key, expiresAt: time.Now().Add(negTTL)}
+	negativeIndex.mu.Lock()
+	defer negativeIndex.mu.Unlock()
+	for _, table := range tables {
+		idxKey := negativeIndexKey(mgr.name, table)
+		refs := negativeIndex.tables[idxKey]
+		if refs == nil {
+			refs = make(map[string]negativeCacheRef)
+			negativeIndex.tables[idxKey] = refs
+		}
+		refs[repo+"\x00"+key] = ref
+		if len(refs)%negativeCachePruneInterval == 0 {
+			now := time.Now()
+			for k, r := range refs {
+				if now.After(r.expiresAt) {
+					delete(refs, k)
+				}
+			}
+		}
+	}
+}
+
+// dmlTable 返回写语句的目标表（去掉引号），不是 INSERT/UPDATE/DELETE/MERGE 语句时返回空字符串
+func dmlTable(querySQL string) string {
+	m := dmlTablePattern.FindStringSubmatch(querySQL)
+	if m == nil {
+		return ""
+	}
+	return strings.NewReplacer("`", "", `"`, "", "[", "", "]", "").Replace(m[1])
+}
+
+// invalidateNegativeCacheOn 写操作结束时清除表上的负缓存；executor 为事务时登记该表，提交后再清除一次
+func (mgr *dbManager) invalidateNegativeCacheOn(executor sqlExecutor, table string) {
+	mgr.invalidateNegativeCache(table)
+	tx, ok := unwrapExecutor(executor).(*sql.Tx)
+	if !ok || getNegativeCacheTTL() <= 0 {
+		return
+	}
+	negativePending.mu.Lock()
+	defer negativePending.mu.Unlock()
+	tables := negativePending.txs[tx]
+	if tables == nil {
+		tables = make(map[negativeTableRef]bool)
+		negativePending.txs[tx] = tables
+	}
+	tables[negativeTableRef{mgr: mgr, table: table}] = true
+}
+
+// finishNegativeCacheTx 在事务结束时调用：提交成功后清除事务中写过的表上的负缓存，回滚时只丢弃登记
+func finishNegativeCacheTx(tx *sql.Tx, committed bool) {
+	negativePending.mu.Lock()
+	tables := negativePending.txs[tx]
+	delete(negativePending.txs, tx)
+	negativePending.mu.Unlock()
+	if !committed {
+		return
+	}
+	for ref := range tables {
+		ref.mgr.invalidateNegativeCache(ref.table)
+	}
+}
+
+// invalidateNegativeCache 删除表上登记的所有负缓存条目
+func (mgr *dbManager) invalidateNegativeCache(table string) {
+	idxKey := negativeIndexKey(mgr.name, table)
+	negativeIndex.mu.Lock()
+	refs := negativeIndex.tables[idxKey]
+	delete(negativeIndex.tables, idxKey)
+	negativeIndex.mu.Unlock()
+
+	for _, ref := range refs {
+		ref.cache.CacheDelete(ref.repo, ref.key)
+	}
+}
@@ -0,0 +1,148 @@
+package dbkit
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// nPlusOne 当前生效的 N+1 检测器，nil 表示未启用
+var nPlusOne atomic.Pointer[nPlusOneDetector]
+
+var (
+	sqlStringLiteralPattern = regexp.MustCompile(`'(?:[^']|'')*'`)
+	sqlPlaceholderPattern   = regexp.MustCompile(`(?:\$|:|@p)\d+\b`)
+	sqlNumberPattern        = regexp.MustCompile(`\b\d+(?:\.\d+)?\b`)
+	sqlValueListPattern     = regexp.MustCompile(`\(\s*\?(?:\s*,\s*\?)*\s*\)`)
+)
+
+// dbkitFuncPrefix dbkit 包内函数名的前缀，用于在调用栈中找到业务代码
+var dbkitFuncPrefix = func() string {
+	name := runtime.FuncForPC(reflect.ValueOf(EnableNPlusOneDetection).Pointer()).Name()
+	return name[:strings.LastIndex(name, ".")+1]
+}()
+
+type nPlusOneDetector struct {
+	threshold int
+	window    time.Duration
+
+	mu        sync.Mutex
+	counters  map[string]*nPlusOneCounter // goroutine ID + "\x00" + 库名 + "\x00" + SQL 模板 -> 计数
+	lastSweep time.Time
+}
+
+type nPlusOneCounter struct {
+	start  time.Time
+	count  int
+	warned bool
+}
+
+// EnableNPlusOneDetection warns about likely N+1 query patterns while debug mode is on:
+// when the same SQL template is executed more than threshold times by one goroutine within window,
+// a warning with the template and the calling location is logged once per window.
+// SQL 模板由语句归一化得到：字面量和各方言占位符统一为 ?，IN 列表折叠为 (?)。
+// threshold <= 0 或 window <= 0 时关闭检测；仅在 SetDebugMode(true) 时统计，不影响生产环境性能
+func EnableNPlusOneDetection(threshold int, window time.Duration) {
+	if threshold <= 0 || window <= 0 {
+		nPlusOne.Store(nil)
+		return
+	}
+	nPlusOne.Store(&nPlusOneDetector{
+		threshold: threshold,
+		window:    window,
+		counters:  make(map[string]*nPlusOneCounter),
+		lastSweep: time.Now(),
+	})
+}
+
+// checkNPlusOne 记录一次成功执行的语句，超过阈值时输出警告
+func checkNPlusOne(dbName, sql string) {
+	if !debug {
+		return
+	}
+	d := nPlusOne.Load()
+	if d == nil {
+		return
+	}
+	template := normalizeSQLTemplate(sql)
+	key := strconv.FormatUint(currentGoroutineID(), 10) + "\x00" + dbName + "\x00" + template
+	now := time.Now()
+
+	d.mu.Lock()
+	if now.Sub(d.lastSweep) > d.window {
+		for k, c := range d.counters {
+			if now.Sub(c.start) > d.window {
+				delete(d.counters, k)
+			}
+		}
+		d.lastSweep = now
+	}
+	c, ok := d.counters[key]
+	if !ok || now.Sub(c.start) > d.window {
+		c = &nPlusOneCounter{start: now}
+		d.counters[key] = c
+	}
+	c.count++
+	warn := c.count > d.threshold && !c.warned
+	if warn {
+		c.warned = true
+	}
+	count := c.count
+	d.mu.Unlock()
+
+	if warn {
+		LogWarn("Possible N+1 query detected", map[string]interface{}{
+			"db":     dbName,
+			"sql":    template,
+			"count":  count,
+			"window": d.window.String(),
+			"caller": nPlusOneCaller(),
+		})
+	}
+}
+
+// normalizeSQLTemplate 把语句归一化为模板，只有参数不同的语句得到相同结果
+func normalizeSQLTemplate(sql string) string {
+	s := sqlStringLiteralPattern.ReplaceAllString(sql, "?")
+	s = sqlPlaceholderPattern.ReplaceAllString(s, "?")
+	s = sqlNumberPattern.ReplaceAllString(s, "?")
+	s = sqlValueListPattern.ReplaceAllString(s, "(?)")
+	return strings.ToLower(cleanSQL(s))
+}
+
+// currentGoroutineID 从调用栈首行 "goroutine N [running]:" 解析当前 goroutine ID
+func currentGoroutineID() uint64 {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i > 0 {
+		b = b[:i]
+	}
+	id, _ := strconv.ParseUint(string(b), 10, 64)
+	return id
+}
+
+// nPlusOneCaller 返回调用栈中第一个 dbkit 和标准库之外的位置
+func nPlusOneCaller() string {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, dbkitFuncPrefix) &&
+			!strings.HasPrefix(frame.Function, "database/sql.") &&
+			!strings.HasPrefix(frame.Function, "runtime.") {
+			return fmt.Sprintf("%s (%s:%d)", frame.Function, frame.File, frame.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
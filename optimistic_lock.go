@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 )
 
 // ErrVersionMismatch is returned when an optimistic lock conflict is detected
@@ -141,6 +142,35 @@ func (db *DB) HasOptimisticLock(table string) bool {
 	return db.dbMgr.hasOptimisticLock(table)
 }
 
+// UpdateOptimistic updates the rows matching where using the version held by record, typically loaded by a prior query.
+// 自动追加 version = 当前版本 条件并把版本加 1，无需手动 Set 版本字段；没有行被更新时返回 ErrVersionMismatch，
+// 成功后 record 中的版本字段更新为新版本。版本字段取 ConfigOptimisticLockWithField 的配置，未配置时为 "version"，
+// 不需要 EnableOptimisticLockCheck；record 中的主键列不会出现在 SET 中
+func UpdateOptimistic(table string, record *Record, whereSql string, whereArgs ...interface{}) (int64, error) {
+	db, err := defaultDB()
+	if err != nil {
+		return 0, err
+	}
+	return db.UpdateOptimistic(table, record, whereSql, whereArgs...)
+}
+
+// UpdateOptimistic updates the rows matching where with an automatic version check and increment
+func (db *DB) UpdateOptimistic(table string, record *Record, whereSql string, whereArgs ...interface{}) (int64, error) {
+	if db.lastErr != nil {
+		return 0, db.lastErr
+	}
+	sdb, err := db.dbMgr.getDB()
+	if err != nil {
+		return 0, err
+	}
+	return db.dbMgr.updateOptimistic(db.executor(sdb), table, record, whereSql, whereArgs...)
+}
+
+// UpdateOptimistic updates the rows matching where within transaction with an automatic version check and increment
+func (tx *Tx) UpdateOptimistic(table string, record *Record, whereSql string, whereArgs ...interface{}) (int64, error) {
+	return tx.dbMgr.updateOptimistic(tx.executor(), table, record, whereSql, whereArgs...)
+}
+
 // --- dbManager Methods ---
 
 // setOptimisticLockConfig sets optimistic lock config for a table
@@ -188,6 +218,94 @@ func (mgr *dbManager) applyVersionInit(table string, record *Record) {
 	}
 }
 
+// updateOptimistic 以 record 中的版本为条件执行更新：SET ..., version = 当前版本 + 1 WHERE (where) AND version = 当前版本
+func (mgr *dbManager) updateOptimistic(executor sqlExecutor, table string, record *Record, where string, whereArgs ...interface{}) (int64, error) {
+	defer mgr.invalidateNegativeCacheOn(executor, table)
+	if err := validateIdentifier(table); err != nil {
+		return 0, err
+	}
+	if record == nil || len(record.columns) == 0 {
+		return 0, fmt.Errorf("record is empty")
+	}
+	where, whereArgs, err := mgr.scopeTenant(executor, table, where, whereArgs)
+	if err != nil {
+		return 0, err
+	}
+
+	versionField := "version"
+	if config := mgr.getOptimisticLockConfig(table); config != nil && config.VersionField != "" {
+		versionField = config.VersionField
+	}
+	if !record.Has(versionField) {
+		return 0, fmt.Errorf("dbkit: UpdateOptimistic requires version field %s in the record", versionField)
+	}
+	currentVersion, ok := parseVersion(record.Get(versionField))
+	if !ok {
+		return 0, fmt.Errorf("dbkit: invalid version value %v in field %s", record.Get(versionField), versionField)
+	}
+
+	// 复制要更新的列，排除版本字段与主键，不修改调用方的 record
+	pks, _ := mgr.getPrimaryKeys(executor, table)
+	updateRecord := NewRecord()
+	for _, col := range record.Keys() {
+		if strings.EqualFold(col, versionField) {
+			continue
+		}
+		isPK := false
+		for _, pk := range pks {
+			if strings.EqualFold(col, pk) {
+				isPK = true
+				break
+			}
+		}
+		if !isPK {
+			updateRecord.Set(col, record.Get(col))
+		}
+	}
+	if mgr.enableTimestampCheck {
+		mgr.applyUpdatedAtTimestamp(table, updateRecord, false)
+	}
+
+	columns, values := mgr.getOrderedColumns(updateRecord)
+	if err := mgr.coerceColumnValues(table, columns, values); err != nil {
+		return 0, err
+	}
+	setClauses := make([]string, 0, len(columns)+1)
+	for _, col := range columns {
+		setClauses = append(setClauses, fmt.Sprintf("%s = ?", col))
+	}
+	setClauses = append(setClauses, fmt.Sprintf("%s = ?", versionField))
+	values = append(values, currentVersion+1)
+
+	if where != "" {
+		where = fmt.Sprintf("(%s) AND %s = ?", where, versionField)
+	} else {
+		where = fmt.Sprintf("%s = ?", versionField)
+	}
+	values = append(values, whereArgs...)
+	values = append(values, currentVersion)
+
+	querySQL := fmt.Sprintf("UPDATE %s SET %s WHERE %s", table, joinStrings(setClauses), where)
+	querySQL = mgr.convertPlaceholder(querySQL, mgr.config.Driver)
+	values = mgr.sanitizeArgs(querySQL, values)
+	start := time.Now()
+	result, err := executor.Exec(querySQL, values...)
+	err = mgr.logTrace(start, querySQL, values, err)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if rowsAffected == 0 {
+		return 0, ErrVersionMismatch
+	}
+	record.Set(versionField, currentVersion+1)
+	return rowsAffected, nil
+}
+
 // getVersionFromRecord extracts the version value from a record
 // Returns the version value and true if found, 0 and false otherwise
 // Treats nil, empty string "", and non-numeric values as "no version"
@@ -201,7 +319,12 @@ func (mgr *dbManager) getVersionFromRecord(table string, record *Record) (int64,
 		return 0, false
 	}
 
-	val := record.Get(config.VersionField)
+	return parseVersion(record.Get(config.VersionField))
+}
+
+// parseVersion converts a version value to int64
+// Treats nil, empty string "", and non-numeric values as "no version"
+func parseVersion(val interface{}) (int64, bool) {
 	if val == nil {
 		return 0, false
 	}
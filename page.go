@@ -1,6 +1,59 @@
 package dbkit
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"sync"
+)
+
+// 分页大小配置，可通过 SetDefaultPageSize / SetMaxPageSize 调整
+var (
+	pageSizeMu      sync.RWMutex
+	pageSizeDefault = DefaultPageSize
+	pageSizeMax     = MaxPageSize
+)
+
+// SetDefaultPageSize sets the page size used when a non-positive pageSize is requested
+func SetDefaultPageSize(size int) {
+	if size < MinPageSize {
+		return
+	}
+	pageSizeMu.Lock()
+	defer pageSizeMu.Unlock()
+	pageSizeDefault = size
+}
+
+// SetMaxPageSize sets the upper bound of pageSize; larger requests are capped with a warning
+func SetMaxPageSize(size int) {
+	if size < MinPageSize {
+		return
+	}
+	pageSizeMu.Lock()
+	defer pageSizeMu.Unlock()
+	pageSizeMax = size
+}
+
+// normalizePageParams 规范化分页参数：页码最小为 1，pageSize 非正数时使用默认值，
+// 超过最大值时截断并记录警告，防止客户端传入超大 pageSize 导致一次查询全表
+func normalizePageParams(page, pageSize int) (int, int) {
+	pageSizeMu.RLock()
+	defaultSize, maxSize := pageSizeDefault, pageSizeMax
+	pageSizeMu.RUnlock()
+
+	if page < DefaultPage {
+		page = DefaultPage
+	}
+	if pageSize < MinPageSize {
+		pageSize = defaultSize
+	}
+	if pageSize > maxSize {
+		LogWarn("分页大小超过上限，已截断", map[string]interface{}{
+			"requested": pageSize,
+			"max":       maxSize,
+		})
+		pageSize = maxSize
+	}
+	return page, pageSize
+}
 
 // Page represents a paginated result, similar to the Java Page class.
 // It uses generics to support different types of data in the List.
@@ -10,6 +63,8 @@ type Page[T any] struct {
 	TotalPage  int   `json:"totalPage"`  // total page
 	TotalRow   int64 `json:"totalRow"`   // total row
 	List       []T   `json:"list"`       // list result of this page
+	// SnapshotToken is set by QueryBuilder.Snapshot, pass it back to fetch later pages of the same snapshot
+	SnapshotToken string `json:"snapshotToken,omitempty"`
 }
 
 // NewPage creates a new Page instance and calculates the total pages.
@@ -53,10 +108,11 @@ func RecordPageToDbModelPage[T any](p *Page[Record]) (*Page[T], error) {
 		return nil, err
 	}
 	return &Page[T]{
-		PageNumber: p.PageNumber,
-		PageSize:   p.PageSize,
-		TotalPage:  p.TotalPage,
-		TotalRow:   p.TotalRow,
-		List:       list,
+		PageNumber:    p.PageNumber,
+		PageSize:      p.PageSize,
+		TotalPage:     p.TotalPage,
+		TotalRow:      p.TotalRow,
+		List:          list,
+		SnapshotToken: p.SnapshotToken,
 	}, nil
 }
@@ -0,0 +1,165 @@
+package dbkit
+
+import (
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// snapshotToken Page.SnapshotToken 的内容：快照列与截取时该列的最大值
+type snapshotToken struct {
+	Column string `json:"c"`
+	Kind   string `json:"k"` // i 整数、f 浮点、t 时间、s 字符串
+	Value  string `json:"v"`
+}
+
+// snapshotTime 以 time.Time 原样绑定时间类型的截取值。sanitizeArgs 会把 time.Time 格式化到秒，
+// 小数秒被截掉后，截取时最大值所在那一秒内的行会被排除在快照之外
+type snapshotTime time.Time
+
+func (t snapshotTime) Value() (driver.Value, error) {
+	return time.Time(t), nil
+}
+
+func (t snapshotTime) String() string {
+	return time.Time(t).Format(time.RFC3339Nano)
+}
+
+// Snapshot keeps Paginate results stable while rows are being inserted.
+// token 为空时先查询匹配行中 column 的最大值，作为 Page.SnapshotToken 返回；后续翻页传回该 token，
+// 每一页都追加 column <= 截取值 的条件，新插入的行不会挤动已返回的分页。
+// column 应随插入单调递增，如自增主键或 created_at；对已有行的更新和删除不在快照范围内
+func (qb *QueryBuilder) Snapshot(column string, token string) *QueryBuilder {
+	if qb.lastErr != nil {
+		return qb
+	}
+	if err := validateIdentifier(column); err != nil {
+		qb.lastErr = err
+		return qb
+	}
+	qb.snapshotColumn = column
+	qb.snapshotToken = token
+	return qb
+}
+
+// paginateSnapshot 解析或截取快照值，以 column <= 值 为条件分页
+func (qb *QueryBuilder) paginateSnapshot(pageNumber, pageSize int) (*Page[Record], error) {
+	var value interface{}
+	token := qb.snapshotToken
+	var err error
+	if token == "" {
+		if value, err = qb.snapshotMax(); err != nil {
+			return nil, err
+		}
+		if value != nil {
+			if token, err = encodeSnapshotToken(qb.snapshotColumn, value); err != nil {
+				return nil, err
+			}
+		}
+	} else if value, err = decodeSnapshotToken(qb.snapshotColumn, token); err != nil {
+		return nil, err
+	}
+
+	base := *qb
+	if t, ok := value.(time.Time); ok {
+		value = snapshotTime(t)
+	}
+	if value != nil {
+		base = *qb.andCondition(qb.snapshotColumn+" <= ?", value)
+	}
+	base.snapshotColumn, base.snapshotToken = "", ""
+
+	page, err := base.Paginate(pageNumber, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	// 分页结果可能来自缓存，复制后再设置 token
+	result := *page
+	result.SnapshotToken = token
+	return &result, nil
+}
+
+// snapshotMax 查询匹配行中快照列的最大值，没有匹配行时返回 nil
+func (qb *QueryBuilder) snapshotMax() (interface{}, error) {
+	if qb.groupBy != "" {
+		return nil, fmt.Errorf("dbkit: Snapshot cannot be used with GroupBy")
+	}
+	base := *qb
+	base.selectSql = fmt.Sprintf("MAX(%s) AS snapshot_max", qb.snapshotColumn)
+	base.selectArgs = nil
+	base.selectSubqueries = nil
+	base.orderBy = ""
+	base.limit, base.offset = 0, 0
+	base.lock = false
+	sql, args := base.buildSelectSql()
+
+	var record *Record
+	var err error
+	if qb.tx != nil {
+		tx := qb.tx
+		if qb.timeout > 0 {
+			tx = tx.Timeout(qb.timeout)
+		}
+		record, err = tx.queryFirst(sql, args...)
+	} else {
+		db := &DB{dbMgr: qb.db.dbMgr, timeout: qb.timeout, debug: qb.db.debug, ctx: qb.db.ctx}
+		record, err = db.queryFirst(sql, args...)
+	}
+	if err != nil || record == nil {
+		return nil, err
+	}
+	return record.Get("snapshot_max"), nil
+}
+
+func encodeSnapshotToken(column string, value interface{}) (string, error) {
+	t := snapshotToken{Column: column}
+	switch v := value.(type) {
+	case int64:
+		t.Kind, t.Value = "i", strconv.FormatInt(v, 10)
+	case int:
+		t.Kind, t.Value = "i", strconv.Itoa(v)
+	case float64:
+		t.Kind, t.Value = "f", strconv.FormatFloat(v, 'g', -1, 64)
+	case time.Time:
+		t.Kind, t.Value = "t", v.Format(time.RFC3339Nano)
+	case string:
+		t.Kind, t.Value = "s", v
+	case []byte:
+		t.Kind, t.Value = "s", string(v)
+	default:
+		return "", fmt.Errorf("dbkit: unsupported snapshot column value type %T", value)
+	}
+	b, err := json.Marshal(t)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func decodeSnapshotToken(column string, token string) (interface{}, error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("dbkit: invalid snapshot token")
+	}
+	var t snapshotToken
+	if err := json.Unmarshal(b, &t); err != nil {
+		return nil, fmt.Errorf("dbkit: invalid snapshot token")
+	}
+	if t.Column != column {
+		return nil, fmt.Errorf("dbkit: snapshot token was issued for column %s, not %s", t.Column, column)
+	}
+	switch t.Kind {
+	case "i":
+		return strconv.ParseInt(t.Value, 10, 64)
+	case "f":
+		return strconv.ParseFloat(t.Value, 64)
+	case "t":
+		return time.Parse(time.RFC3339Nano, t.Value)
+	case "s":
+		return t.Value, nil
+	}
+	return nil, fmt.Errorf("dbkit: invalid snapshot token")
+}
@@ -0,0 +1,73 @@
+package dbkit
+
+import (
+	"fmt"
+)
+
+// PaginateWithTotal returns the requested page like Paginate but uses knownTotal instead of running the COUNT query.
+// 适用于“加载更多”等场景：第一页用 Paginate 或 CountOnly 取得总数，之后的页复用该总数计算 TotalPage
+func (qb *QueryBuilder) PaginateWithTotal(pageNumber, pageSize int, knownTotal int64) (*Page[Record], error) {
+	if knownTotal < 0 {
+		return nil, fmt.Errorf("dbkit: PaginateWithTotal total must not be negative")
+	}
+	pageNumber, pageSize = normalizePageParams(pageNumber, pageSize)
+	list, err := qb.ListOnly(pageNumber, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	return NewPage(list, pageNumber, pageSize, knownTotal), nil
+}
+
+// ListOnly returns the rows of one page without counting the total
+func (qb *QueryBuilder) ListOnly(pageNumber, pageSize int) ([]Record, error) {
+	if qb.lock {
+		return nil, errPaginateLock
+	}
+	mgr, executor, sql, args, err := qb.pageQuery()
+	if err != nil {
+		return nil, err
+	}
+	return mgr.paginateList(executor, sql, pageNumber, pageSize, args...)
+}
+
+// CountOnly returns the total row count Paginate would report, without fetching any rows.
+// 与 Count 不同，计数基于完整的 SELECT（包括 Join、GroupBy、Distinct），WithCountCache 同样生效
+func (qb *QueryBuilder) CountOnly() (int64, error) {
+	mgr, executor, sql, args, err := qb.pageQuery()
+	if err != nil {
+		return 0, err
+	}
+	return mgr.paginateCount(executor, sql, qb.countCacheTTL, args...)
+}
+
+// pageQuery 构建与 Paginate 相同的不含 LIMIT/OFFSET 的查询语句，并返回执行它的 executor
+func (qb *QueryBuilder) pageQuery() (*dbManager, sqlExecutor, string, []interface{}, error) {
+	if qb.lastErr != nil {
+		return nil, nil, "", nil, qb.lastErr
+	}
+	if qb.snapshotColumn != "" {
+		return nil, nil, "", nil, fmt.Errorf("dbkit: Snapshot pagination requires Paginate")
+	}
+	qb, err := qb.tenantScoped()
+	if err != nil {
+		return nil, nil, "", nil, err
+	}
+	mgr := qb.getDbManager()
+	if mgr == nil {
+		return nil, nil, "", nil, fmt.Errorf("dbkit: database not initialized")
+	}
+
+	base := *qb
+	base.limit, base.offset = 0, 0
+	base.lock = false
+	sql, args := base.buildSelectSql()
+
+	if qb.tx != nil {
+		return mgr, qb.tx.executor(), sql, args, nil
+	}
+	sdb, err := mgr.getDB()
+	if err != nil {
+		return nil, nil, "", nil, err
+	}
+	return mgr, qb.db.executor(sdb), sql, args, nil
+}
@@ -192,7 +192,7 @@ func (mgr *ConcurrentPaginationManager) executePaginationQuery(
 				// 缓存命中，继续执行分页查询
 			} else {
 				// 缓存值转换失败，重新查询
-				countRecord, err := db.QueryFirst(countSQL, args...)
+				countRecord, err := db.queryFirst(countSQL, args...)
 				if err != nil {
 					return nil, fmt.Errorf("count query failed: %w", err)
 				}
@@ -219,7 +219,7 @@ func (mgr *ConcurrentPaginationManager) executePaginationQuery(
 			}
 		} else {
 			// 缓存未命中，执行查询
-			countRecord, err := db.QueryFirst(countSQL, args...)
+			countRecord, err := db.queryFirst(countSQL, args...)
 			if err != nil {
 				return nil, fmt.Errorf("count query failed: %w", err)
 			}
@@ -246,7 +246,7 @@ func (mgr *ConcurrentPaginationManager) executePaginationQuery(
 		}
 	} else {
 		// 不使用缓存
-		countRecord, err := db.QueryFirst(countSQL, args...)
+		countRecord, err := db.queryFirst(countSQL, args...)
 		if err != nil {
 			return nil, fmt.Errorf("count query failed: %w", err)
 		}
@@ -296,7 +296,7 @@ func (mgr *ConcurrentPaginationManager) executePaginationQuery(
 		if err != nil {
 			return nil, err
 		}
-		list, err := db.dbMgr.queryWithContext(ctx, sdb, paginationSQL, args...)
+		list, err := db.dbMgr.queryWithContext(ctx, db.executor(sdb), paginationSQL, args...)
 		if err != nil {
 			return nil, fmt.Errorf("pagination query failed: %w", err)
 		}
@@ -310,7 +310,7 @@ func (mgr *ConcurrentPaginationManager) executePaginationQuery(
 		if err != nil {
 			return nil, err
 		}
-		list, err := db.dbMgr.queryWithContext(ctx, sdb, paginationSQL, args...)
+		list, err := db.dbMgr.queryWithContext(ctx, db.executor(sdb), paginationSQL, args...)
 		if err != nil {
 			return nil, fmt.Errorf("pagination query failed: %w", err)
 		}
@@ -342,7 +342,7 @@ func (mgr *ConcurrentPaginationManager) executePaginationQueryTx(
 				// 缓存命中，继续执行分页查询
 			} else {
 				// 缓存值转换失败，重新查询
-				countRecord, err := tx.QueryFirst(countSQL, args...)
+				countRecord, err := tx.queryFirst(countSQL, args...)
 				if err != nil {
 					return nil, fmt.Errorf("transaction count query failed: %w", err)
 				}
@@ -369,7 +369,7 @@ func (mgr *ConcurrentPaginationManager) executePaginationQueryTx(
 			}
 		} else {
 			// 缓存未命中，执行查询
-			countRecord, err := tx.QueryFirst(countSQL, args...)
+			countRecord, err := tx.queryFirst(countSQL, args...)
 			if err != nil {
 				return nil, fmt.Errorf("transaction count query failed: %w", err)
 			}
@@ -396,7 +396,7 @@ func (mgr *ConcurrentPaginationManager) executePaginationQueryTx(
 		}
 	} else {
 		// 不使用缓存
-		countRecord, err := tx.QueryFirst(countSQL, args...)
+		countRecord, err := tx.queryFirst(countSQL, args...)
 		if err != nil {
 			return nil, fmt.Errorf("transaction count query failed: %w", err)
 		}
@@ -442,7 +442,7 @@ func (mgr *ConcurrentPaginationManager) executePaginationQueryTx(
 		}
 
 		// 缓存未命中或转换失败，执行查询
-		list, err := tx.dbMgr.queryWithContext(ctx, tx.tx, paginationSQL, args...)
+		list, err := tx.dbMgr.queryWithContext(ctx, tx.executor(), paginationSQL, args...)
 		if err != nil {
 			return nil, fmt.Errorf("transaction pagination query failed: %w", err)
 		}
@@ -452,7 +452,7 @@ func (mgr *ConcurrentPaginationManager) executePaginationQueryTx(
 		return NewPage(list, page, pageSize, totalRow), nil
 	} else {
 		// 不使用缓存
-		list, err := tx.dbMgr.queryWithContext(ctx, tx.tx, paginationSQL, args...)
+		list, err := tx.dbMgr.queryWithContext(ctx, tx.executor(), paginationSQL, args...)
 		if err != nil {
 			return nil, fmt.Errorf("transaction pagination query failed: %w", err)
 		}
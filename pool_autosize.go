@@ -0,0 +1,204 @@
+package dbkit
+
+import (
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// AutoSizeOpts configures AutoSizePool
+type AutoSizeOpts struct {
+	MinOpen   int           // MaxOpen 的下限，<= 0 时为 1
+	MaxOpen   int           // MaxOpen 的上限，必须大于 0 且不小于 MinOpen
+	Window    time.Duration // 观察窗口，每个窗口结束时评估一次，<= 0 时为 10 秒
+	Step      int           // 每次调整的连接数，<= 0 时为上限的 1/10（至少 1）
+	IdleRatio float64       // 窗口内使用中连接数的峰值仍使未使用的容量超过 MaxOpen 的该比例时缩小连接池，<= 0 时为 0.5
+}
+
+// autoSizeSamples 每个窗口内采样使用中连接数的次数，缩小连接池按窗口内的峰值判断，避免单次采样恰好落在空闲时刻
+const autoSizeSamples = 10
+
+// poolAutoSizer 按窗口内的等待次数与使用中连接数的峰值调整单个数据库的 MaxOpen/MaxIdle。
+// 只修改 *sql.DB 的连接池限制，不回写调用方传入的 Config
+type poolAutoSizer struct {
+	mgr           *dbManager
+	opts          AutoSizeOpts
+	idleRatio     float64 // MaxIdle 与 MaxOpen 的比例，取自 Config
+	lastWaitCount int64
+	peakInUse     int // 当前窗口内采样到的使用中连接数峰值
+	samples       int // 当前窗口内已采样的次数
+	stopCh        chan struct{}
+	stopOnce      sync.Once
+}
+
+var (
+	// poolAutoSizers 数据库名 -> 连接池自动调整器
+	poolAutoSizers   = make(map[string]*poolAutoSizer)
+	poolAutoSizersMu sync.Mutex
+)
+
+// AutoSizePool periodically adjusts MaxOpen/MaxIdle of the named database within [MinOpen, MaxOpen].
+// 每个窗口内出现连接等待（WaitCount 增长）时增加 Step 个连接，没有等待且窗口内使用中连接数的峰值过低时减少 Step 个；
+// MaxIdle 按 Config 中 MaxIdle/MaxOpen 的比例同步调整。再次调用会以新配置重启，关闭数据库时自动停止
+func AutoSizePool(dbName string, opts AutoSizeOpts) error {
+	mgr := GetDatabase(dbName)
+	if mgr == nil {
+		return fmt.Errorf("dbkit: database '%s' not found", dbName)
+	}
+	if opts.MinOpen <= 0 {
+		opts.MinOpen = 1
+	}
+	if opts.MaxOpen <= 0 || opts.MaxOpen < opts.MinOpen {
+		return fmt.Errorf("dbkit: invalid AutoSizeOpts: MaxOpen (%d) must be > 0 and >= MinOpen (%d)", opts.MaxOpen, opts.MinOpen)
+	}
+	if opts.Window <= 0 {
+		opts.Window = 10 * time.Second
+	}
+	if opts.Step <= 0 {
+		opts.Step = opts.MaxOpen / 10
+		if opts.Step < 1 {
+			opts.Step = 1
+		}
+	}
+	if opts.IdleRatio <= 0 {
+		opts.IdleRatio = 0.5
+	}
+
+	sdb, err := mgr.getDB()
+	if err != nil {
+		return err
+	}
+
+	stats := sdb.Stats()
+	current := stats.MaxOpenConnections
+	idleRatio := 0.5
+	mgr.mu.RLock()
+	if mgr.config.MaxOpen > 0 && mgr.config.MaxIdle > 0 {
+		idleRatio = float64(mgr.config.MaxIdle) / float64(mgr.config.MaxOpen)
+	}
+	mgr.mu.RUnlock()
+
+	s := &poolAutoSizer{
+		mgr:           mgr,
+		opts:          opts,
+		idleRatio:     idleRatio,
+		lastWaitCount: stats.WaitCount,
+		stopCh:        make(chan struct{}),
+	}
+
+	// 当前值不在范围内（包括未限制的 0）时先调整到范围内
+	if current < opts.MinOpen || current > opts.MaxOpen {
+		if current < opts.MinOpen {
+			current = opts.MinOpen
+		} else {
+			current = opts.MaxOpen
+		}
+		s.resize(sdb, current)
+	}
+
+	StopAutoSizePool(dbName)
+	poolAutoSizersMu.Lock()
+	poolAutoSizers[dbName] = s
+	poolAutoSizersMu.Unlock()
+
+	go s.run()
+	return nil
+}
+
+// StopAutoSizePool stops automatic pool sizing of the named database, keeping the current limits
+func StopAutoSizePool(dbName string) {
+	poolAutoSizersMu.Lock()
+	s, ok := poolAutoSizers[dbName]
+	delete(poolAutoSizers, dbName)
+	poolAutoSizersMu.Unlock()
+	if ok {
+		s.stop()
+	}
+}
+
+func (s *poolAutoSizer) stop() {
+	s.stopOnce.Do(func() { close(s.stopCh) })
+}
+
+func (s *poolAutoSizer) run() {
+	interval := s.opts.Window / autoSizeSamples
+	if interval <= 0 {
+		interval = s.opts.Window
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+			s.sample()
+		}
+	}
+}
+
+// sample 记录一次使用中连接数，凑满一个窗口的采样后评估是否调整连接池大小
+func (s *poolAutoSizer) sample() {
+	// 不使用 getDB，避免数据库关闭后被重新打开
+	s.mgr.mu.RLock()
+	sdb := s.mgr.db
+	s.mgr.mu.RUnlock()
+	if sdb == nil {
+		return
+	}
+	stats := sdb.Stats()
+	if stats.InUse > s.peakInUse {
+		s.peakInUse = stats.InUse
+	}
+	s.samples++
+	if s.samples < autoSizeSamples {
+		return
+	}
+	s.adjust(sdb, stats)
+	s.peakInUse = 0
+	s.samples = 0
+}
+
+// adjust 根据一个窗口内的等待次数与使用中连接数的峰值决定是否调整连接池大小
+func (s *poolAutoSizer) adjust(sdb *sql.DB, stats sql.DBStats) {
+	waits := stats.WaitCount - s.lastWaitCount
+	s.lastWaitCount = stats.WaitCount
+
+	current := stats.MaxOpenConnections
+	target := current
+	if waits > 0 {
+		target = current + s.opts.Step
+	} else if float64(current-s.peakInUse) > float64(current)*s.opts.IdleRatio {
+		target = current - s.opts.Step
+	}
+	if target > s.opts.MaxOpen {
+		target = s.opts.MaxOpen
+	}
+	if target < s.opts.MinOpen {
+		target = s.opts.MinOpen
+	}
+	if target == current {
+		return
+	}
+
+	s.resize(sdb, target)
+	LogInfo("连接池大小已自动调整", map[string]interface{}{
+		"database":    s.mgr.name,
+		"max_open":    target,
+		"previous":    current,
+		"wait_count":  waits,
+		"peak_in_use": s.peakInUse,
+	})
+}
+
+// resize 设置 MaxOpen 并按比例设置 MaxIdle
+func (s *poolAutoSizer) resize(sdb *sql.DB, maxOpen int) {
+	maxIdle := int(float64(maxOpen) * s.idleRatio)
+	if maxIdle < 1 {
+		maxIdle = 1
+	}
+	// 先设置 MaxOpen：database/sql 会把超过 MaxOpen 的 MaxIdle 截断
+	sdb.SetMaxOpenConns(maxOpen)
+	sdb.SetMaxIdleConns(maxIdle)
+}
@@ -0,0 +1,314 @@
+package dbkit
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// ProcParamMode 存储过程参数的方向
+type ProcParamMode int
+
+const (
+	ProcModeIn ProcParamMode = iota
+	ProcModeOut
+	ProcModeInOut
+)
+
+// ProcParam describes a single stored procedure argument
+type ProcParam struct {
+	Name  string        // 参数名（SQL Server 必填，其他数据库用作 Out 结果中的键名）
+	Mode  ProcParamMode // 参数方向
+	Value interface{}   // In 参数的值
+	Dest  interface{}   // Out/InOut 参数的接收指针，其类型决定驱动绑定的参数类型
+}
+
+// ProcIn creates an input parameter
+func ProcIn(name string, value interface{}) ProcParam {
+	return ProcParam{Name: name, Mode: ProcModeIn, Value: value}
+}
+
+// ProcOut creates an output parameter; dest must be a non-nil pointer (e.g. new(int64))
+func ProcOut(name string, dest interface{}) ProcParam {
+	return ProcParam{Name: name, Mode: ProcModeOut, Dest: dest}
+}
+
+// ProcInOut creates an input/output parameter; the current value of *dest is sent in
+// and replaced with the value returned by the procedure
+func ProcInOut(name string, dest interface{}) ProcParam {
+	return ProcParam{Name: name, Mode: ProcModeInOut, Dest: dest}
+}
+
+// procExecutor 调用存储过程所需的执行器：*sql.Tx 或固定连接
+type procExecutor interface {
+	sqlExecutor
+	sqlExecutorContext
+}
+
+// ProcResult holds the result sets and OUT values of a stored procedure call
+type ProcResult struct {
+	ResultSets [][]Record // 过程返回的结果集（按顺序）
+	Out        *Record    // OUT / INOUT 参数值，键为参数名
+}
+
+// CallProc calls a stored procedure on the default database.
+// 各数据库的绑定方式：
+//   - SQL Server: EXEC name @p = @p OUTPUT，使用 sql.Named + sql.Out
+//   - Oracle: BEGIN name(:1, :2); END;，使用 sql.Out
+//   - MySQL: CALL name(?, @p)，随后在同一连接上 SELECT @p 读取 OUT 值
+//   - PostgreSQL: CALL name($1, NULL)，OUT / INOUT 值由 CALL 返回的结果行读取
+//   - SQLite 不支持存储过程
+func CallProc(name string, params ...ProcParam) (*ProcResult, error) {
+	db, err := defaultDB()
+	if err != nil {
+		return nil, err
+	}
+	return db.CallProc(name, params...)
+}
+
+// CallProc calls a stored procedure on this database using a pinned connection
+func (db *DB) CallProc(name string, params ...ProcParam) (*ProcResult, error) {
+	if db.lastErr != nil {
+		return nil, db.lastErr
+	}
+	ctx, cancel := db.getContext()
+	defer cancel()
+
+	// MySQL 的 OUT 值保存在会话变量中，必须在同一连接上读取
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	return db.dbMgr.callProc(ctx, conn.executor().(procExecutor), name, params)
+}
+
+// CallProc calls a stored procedure within the transaction
+func (tx *Tx) CallProc(name string, params ...ProcParam) (*ProcResult, error) {
+	ctx, cancel := tx.getContext()
+	defer cancel()
+	return tx.dbMgr.callProc(ctx, tx.executor().(procExecutor), name, params)
+}
+
+func (mgr *dbManager) callProc(ctx context.Context, executor procExecutor, name string, params []ProcParam) (*ProcResult, error) {
+	if err := validateIdentifier(name); err != nil {
+		return nil, err
+	}
+	// 复制一份再补默认参数名，不修改调用方传入的切片（CallProc(name, params...) 时与调用方共享底层数组）
+	params = append([]ProcParam(nil), params...)
+	for i := range params {
+		if params[i].Name == "" {
+			params[i].Name = fmt.Sprintf("p%d", i+1)
+		}
+		if err := validateIdentifier(params[i].Name); err != nil {
+			return nil, err
+		}
+		if params[i].Mode != ProcModeIn {
+			if params[i].Dest == nil || reflect.ValueOf(params[i].Dest).Kind() != reflect.Ptr {
+				return nil, fmt.Errorf("dbkit: procedure parameter '%s' requires a non-nil pointer Dest", params[i].Name)
+			}
+		}
+	}
+
+	switch mgr.config.Driver {
+	case SQLServer, Oracle:
+		return mgr.callProcNativeOut(ctx, executor, name, params)
+	case MySQL:
+		return mgr.callProcMySQL(ctx, executor, name, params)
+	case PostgreSQL:
+		return mgr.callProcPostgres(ctx, executor, name, params)
+	default:
+		return nil, fmt.Errorf("dbkit: stored procedures are not supported by %s", mgr.config.Driver)
+	}
+}
+
+// callProcNativeOut 使用 sql.Out 绑定 OUT 参数（SQL Server / Oracle）
+func (mgr *dbManager) callProcNativeOut(ctx context.Context, executor procExecutor, name string, params []ProcParam) (*ProcResult, error) {
+	var querySQL string
+	args := make([]interface{}, len(params))
+	if mgr.config.Driver == SQLServer {
+		parts := make([]string, len(params))
+		for i, p := range params {
+			parts[i] = fmt.Sprintf("@%s = @%s", p.Name, p.Name)
+			if p.Mode != ProcModeIn {
+				parts[i] += " OUTPUT"
+			}
+			args[i] = sql.Named(p.Name, procArg(p))
+		}
+		querySQL = fmt.Sprintf("EXEC %s %s", name, strings.Join(parts, ", "))
+	} else {
+		placeholders := make([]string, len(params))
+		for i, p := range params {
+			placeholders[i] = fmt.Sprintf(":%d", i+1)
+			args[i] = procArg(p)
+		}
+		querySQL = fmt.Sprintf("BEGIN %s(%s); END;", name, strings.Join(placeholders, ", "))
+	}
+
+	start := time.Now()
+	result := &ProcResult{Out: NewRecord()}
+	if mgr.config.Driver == Oracle {
+		// PL/SQL 匿名块不返回结果集
+		_, err := executor.ExecContext(ctx, querySQL, args...)
+		err = mgr.logTrace(start, querySQL, args, err)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		rows, err := executor.QueryContext(ctx, querySQL, args...)
+		err = mgr.logTrace(start, querySQL, args, err)
+		if err != nil {
+			return nil, err
+		}
+		// OUT 参数在读完所有结果集并关闭 rows 之后才会被填充
+		sets, err := mgr.scanResultSets(rows)
+		if err != nil {
+			return nil, err
+		}
+		result.ResultSets = sets
+	}
+
+	for _, p := range params {
+		if p.Mode != ProcModeIn {
+			result.Out.Set(p.Name, reflect.ValueOf(p.Dest).Elem().Interface())
+		}
+	}
+	return result, nil
+}
+
+// callProcMySQL 通过会话变量模拟 OUT 参数：CALL name(?, @p) 后 SELECT @p
+func (mgr *dbManager) callProcMySQL(ctx context.Context, executor procExecutor, name string, params []ProcParam) (*ProcResult, error) {
+	var placeholders, outVars []string
+	var args []interface{}
+	for _, p := range params {
+		if p.Mode == ProcModeIn {
+			placeholders = append(placeholders, "?")
+			args = append(args, p.Value)
+			continue
+		}
+		variable := "@" + p.Name
+		if p.Mode == ProcModeInOut {
+			if _, err := mgr.execWithContext(ctx, executor, "SET "+variable+" = ?", reflect.ValueOf(p.Dest).Elem().Interface()); err != nil {
+				return nil, err
+			}
+		}
+		placeholders = append(placeholders, variable)
+		outVars = append(outVars, fmt.Sprintf("%s AS %s", variable, p.Name))
+	}
+
+	querySQL := fmt.Sprintf("CALL %s(%s)", name, strings.Join(placeholders, ", "))
+	start := time.Now()
+	rows, err := executor.QueryContext(ctx, querySQL, args...)
+	err = mgr.logTrace(start, querySQL, args, err)
+	if err != nil {
+		return nil, err
+	}
+	sets, err := mgr.scanResultSets(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ProcResult{ResultSets: sets, Out: NewRecord()}
+	if len(outVars) > 0 {
+		out, err := mgr.queryFirstInternalWithContext(ctx, executor, "SELECT "+strings.Join(outVars, ", "))
+		if err != nil {
+			return nil, err
+		}
+		if err := mgr.fillProcOut(result, out, params); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// callProcPostgres 调用 PostgreSQL 过程，OUT 位置传 NULL，CALL 返回的单行即为 OUT / INOUT 值
+func (mgr *dbManager) callProcPostgres(ctx context.Context, executor procExecutor, name string, params []ProcParam) (*ProcResult, error) {
+	placeholders := make([]string, len(params))
+	var args []interface{}
+	hasOut := false
+	for i, p := range params {
+		switch p.Mode {
+		case ProcModeIn:
+			placeholders[i] = "?"
+			args = append(args, p.Value)
+		case ProcModeInOut:
+			hasOut = true
+			placeholders[i] = "?"
+			args = append(args, reflect.ValueOf(p.Dest).Elem().Interface())
+		default:
+			hasOut = true
+			placeholders[i] = "NULL"
+		}
+	}
+	querySQL := fmt.Sprintf("CALL %s(%s)", name, strings.Join(placeholders, ", "))
+	records, err := mgr.queryWithContext(ctx, executor, querySQL, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ProcResult{Out: NewRecord()}
+	if hasOut && len(records) > 0 {
+		if err := mgr.fillProcOut(result, &records[0], params); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// fillProcOut 将查询到的 OUT 值写入 ProcResult.Out 以及调用方提供的 Dest 指针
+func (mgr *dbManager) fillProcOut(result *ProcResult, out *Record, params []ProcParam) error {
+	if out == nil {
+		return nil
+	}
+	for _, p := range params {
+		if p.Mode == ProcModeIn {
+			continue
+		}
+		val := out.Get(p.Name)
+		result.Out.Set(p.Name, val)
+		if val == nil {
+			continue
+		}
+		if err := setFieldValue(reflect.ValueOf(p.Dest).Elem(), val); err != nil {
+			return fmt.Errorf("dbkit: procedure parameter '%s': %v", p.Name, err)
+		}
+	}
+	return nil
+}
+
+// scanResultSets 读取 rows 中的全部结果集并关闭 rows
+func (mgr *dbManager) scanResultSets(rows *sql.Rows) ([][]Record, error) {
+	defer rows.Close()
+	var sets [][]Record
+	for {
+		records, err := scanRecords(rows, mgr.config.Driver, mgr.getTimeLocation())
+		if err != nil {
+			return nil, err
+		}
+		sets = append(sets, records)
+		if !rows.NextResultSet() {
+			break
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	return sets, nil
+}
+
+// procArg 构造 SQL Server / Oracle 的参数值，OUT / INOUT 使用 sql.Out
+func procArg(p ProcParam) interface{} {
+	switch p.Mode {
+	case ProcModeOut:
+		return sql.Out{Dest: p.Dest}
+	case ProcModeInOut:
+		return sql.Out{Dest: p.Dest, In: true}
+	}
+	return p.Value
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -41,7 +42,7 @@ func QueryFirstToDbModel(dest interface{}, querySQL string, args ...interface{})
 		return err
 	}
 	if record == nil {
-		return fmt.Errorf("dbkit: no record found")
+		return ErrRecordNotFound
 	}
 	return ToStruct(record, dest)
 }
@@ -54,6 +55,27 @@ func QueryMap(querySQL string, args ...interface{}) ([]map[string]interface{}, e
 	return db.QueryMap(querySQL, args...)
 }
 
+// QueryMulti executes a statement that returns several result sets (e.g. a SQL Server
+// procedure or a batch of SELECTs) and returns all of them in order
+func QueryMulti(querySQL string, args ...interface{}) ([][]*Record, error) {
+	db, err := defaultDB()
+	if err != nil {
+		return nil, err
+	}
+	return db.QueryMulti(querySQL, args...)
+}
+
+// QueryToNestedMap executes a (typically GROUP BY) query and reshapes the rows into nested maps keyed by
+// keyColumns in order, with valueColumn as the leaf value, e.g. keyColumns {"dept", "status"} gives
+// map[dept]map[status]value. 键按字符串取值（NULL 为空字符串），同一组键出现多次时后者覆盖前者
+func QueryToNestedMap(querySQL string, keyColumns []string, valueColumn string, args ...interface{}) (map[string]interface{}, error) {
+	db, err := defaultDB()
+	if err != nil {
+		return nil, err
+	}
+	return db.QueryToNestedMap(querySQL, keyColumns, valueColumn, args...)
+}
+
 // QueryWithOutTrashed 执行原始 SQL 查询并自动过滤软删除数据（全局函数）
 // 实现快速路径检查（软删除功能禁用、表未配置）
 // 调用 dbManager 的分析方法，错误时回退到原始 Query 方法
@@ -83,12 +105,12 @@ func Exec(querySQL string, args ...interface{}) (sql.Result, error) {
 	return db.Exec(querySQL, args...)
 }
 
-func Save(table string, record *Record) (int64, error) {
+func Save(table string, record *Record, opts ...UpsertOptions) (int64, error) {
 	db, err := defaultDB()
 	if err != nil {
 		return 0, err
 	}
-	return db.Save(table, record)
+	return db.Save(table, record, opts...)
 }
 
 func Insert(table string, record *Record) (int64, error) {
@@ -99,6 +121,17 @@ func Insert(table string, record *Record) (int64, error) {
 	return db.Insert(table, record)
 }
 
+// InsertIgnore inserts the record unless it conflicts with an existing row.
+// Returns 1 if inserted, 0 if skipped. conflictColumns 为空时：PostgreSQL/SQLite 匹配任意唯一约束，
+// SQL Server/Oracle 使用主键
+func InsertIgnore(table string, record *Record, conflictColumns ...string) (int64, error) {
+	db, err := defaultDB()
+	if err != nil {
+		return 0, err
+	}
+	return db.InsertIgnore(table, record, conflictColumns...)
+}
+
 func Update(table string, record *Record, whereSql string, whereArgs ...interface{}) (int64, error) {
 	db, err := defaultDB()
 	if err != nil {
@@ -110,9 +143,9 @@ func Update(table string, record *Record, whereSql string, whereArgs ...interfac
 	}
 	// 如果特性检查都关闭，直接使用快速路径
 	if !db.dbMgr.enableTimestampCheck && !db.dbMgr.enableOptimisticLockCheck {
-		return db.dbMgr.updateFast(sdb, table, record, whereSql, whereArgs...)
+		return db.dbMgr.updateFast(db.executor(sdb), table, record, whereSql, whereArgs...)
 	}
-	return db.dbMgr.update(sdb, table, record, whereSql, whereArgs...)
+	return db.dbMgr.update(db.executor(sdb), table, record, whereSql, whereArgs...)
 }
 
 // UpdateFast is a lightweight update that always skips timestamp and optimistic lock checks.
@@ -126,7 +159,7 @@ func UpdateFast(table string, record *Record, whereSql string, whereArgs ...inte
 	if err != nil {
 		return 0, err
 	}
-	return db.dbMgr.updateFast(sdb, table, record, whereSql, whereArgs...)
+	return db.dbMgr.updateFast(db.executor(sdb), table, record, whereSql, whereArgs...)
 }
 
 func Delete(table string, whereSql string, whereArgs ...interface{}) (int64, error) {
@@ -161,6 +194,25 @@ func BatchInsertDefault(table string, records []*Record) (int64, error) {
 	return db.BatchInsertDefault(table, records)
 }
 
+// BatchInsertPartial inserts records one by one and collects per-row failures instead of aborting
+func BatchInsertPartial(table string, records []*Record) (int64, []BatchError, error) {
+	db, err := defaultDB()
+	if err != nil {
+		return 0, nil, err
+	}
+	return db.BatchInsertPartial(table, records)
+}
+
+// BatchInsertGrouped inserts records whose column sets differ.
+// 按列集合分组，每组生成独立的多行 INSERT，避免以第一条记录的列为准导致其他记录的列被错位或丢弃
+func BatchInsertGrouped(table string, records []*Record, batchSize int) (int64, error) {
+	db, err := defaultDB()
+	if err != nil {
+		return 0, err
+	}
+	return db.BatchInsertGrouped(table, records, batchSize)
+}
+
 // BatchUpdate updates multiple records by primary key
 func BatchUpdate(table string, records []*Record, batchSize int) (int64, error) {
 	db, err := defaultDB()
@@ -246,6 +298,26 @@ func Transaction(fn func(*Tx) error) error {
 	return db.Transaction(fn)
 }
 
+// TransactionTimeout runs fn in a transaction on the default database, bounding the whole transaction by d
+func TransactionTimeout(d time.Duration, fn func(*Tx) error) error {
+	db, err := defaultDB()
+	if err != nil {
+		return err
+	}
+	return db.TransactionTimeout(d, fn)
+}
+
+// TransactionContext runs fn in a transaction on the default database bound to ctx.
+// ctx 被取消（如用户离开页面、上游请求结束）时，驱动中断正在执行的语句并立即回滚事务，
+// 返回的错误可用 errors.Is(err, context.Canceled) 判断
+func TransactionContext(ctx context.Context, fn func(*Tx) error) error {
+	db, err := defaultDB()
+	if err != nil {
+		return err
+	}
+	return db.TransactionContext(ctx, fn)
+}
+
 func Ping() error {
 	dbMgr, err := safeGetCurrentDB()
 	if err != nil {
@@ -289,8 +361,18 @@ func BeginTransaction() (*Tx, error) {
 	return &Tx{tx: tx, dbMgr: dbMgr}, nil
 }
 
+// BeginTransactionContext starts a transaction on the default database bound to ctx.
+// ctx 被取消时事务自动回滚，之后的 Commit 返回 sql.ErrTxDone
+func BeginTransactionContext(ctx context.Context) (*Tx, error) {
+	db, err := defaultDB()
+	if err != nil {
+		return nil, err
+	}
+	return db.BeginTransactionContext(ctx)
+}
+
 func ExecTx(tx *Tx, querySQL string, args ...interface{}) (sql.Result, error) {
-	return tx.dbMgr.exec(tx.tx, querySQL, args...)
+	return tx.dbMgr.exec(tx.executor(), querySQL, args...)
 }
 
 func SaveTx(tx *Tx, table string, record *Record) (int64, error) {
@@ -313,8 +395,20 @@ func FindAll(table string) ([]Record, error) {
 	return db.FindAll(table)
 }
 
+// FindWhere returns the rows of table matching all equality conditions (see QueryBuilder.WhereMap)
+func FindWhere(table string, conditions map[string]interface{}) ([]Record, error) {
+	db, err := defaultDB()
+	if err != nil {
+		return nil, err
+	}
+	return db.FindWhere(table, conditions)
+}
+
 // --- Struct Methods (Operation on models implementing IDbModel) ---
 
+// SaveDbModel inserts the model when its primary key is zero-valued and writes the
+// generated ID back to the struct; otherwise it updates the row by primary key
+// (with version checking when optimistic locking is enabled)
 func SaveDbModel(model IDbModel) (int64, error) {
 	return Use(model.DatabaseName()).SaveDbModel(model)
 }
@@ -425,16 +519,25 @@ func (db *DB) Query(querySQL string, args ...interface{}) ([]Record, error) {
 			}
 		}
 
-		results, err := db.dbMgr.queryWithContext(ctx, sdb, querySQL, args...)
-		if err == nil {
-			cache.CacheSet(db.cacheRepositoryName, key, results, getEffectiveTTL(db.cacheRepositoryName, db.cacheTTL))
-		}
-		return results, err
+		return loadOnce(db.cacheRepositoryName, key, func() ([]Record, error) {
+			results, err := db.dbMgr.queryWithContext(ctx, db.executor(sdb), querySQL, args...)
+			if err == nil {
+				cache.CacheSet(db.cacheRepositoryName, key, results, getEffectiveTTL(db.cacheRepositoryName, db.cacheTTL))
+			}
+			return results, err
+		})
 	}
-	return db.dbMgr.queryWithContext(ctx, sdb, querySQL, args...)
+	return db.dbMgr.queryWithContext(ctx, db.executor(sdb), querySQL, args...)
 }
 
+// QueryFirst executes a query and returns the first record.
+// 没有匹配的记录时默认返回 (nil, nil)，调用 SetQueryFirstNotFoundError(true) 后返回 (nil, ErrNoRows)
 func (db *DB) QueryFirst(querySQL string, args ...interface{}) (*Record, error) {
+	return queryFirstResult(db.queryFirst(querySQL, args...))
+}
+
+// queryFirst 执行 QueryFirst，没有匹配的记录时总是返回 (nil, nil)，供内部调用
+func (db *DB) queryFirst(querySQL string, args ...interface{}) (*Record, error) {
 	if db.lastErr != nil {
 		return nil, db.lastErr
 	}
@@ -449,18 +552,25 @@ func (db *DB) QueryFirst(querySQL string, args ...interface{}) (*Record, error)
 		cache := db.getEffectiveCache()
 		key := GenerateCacheKey(db.dbMgr.name, querySQL, args...)
 		if val, ok := cache.CacheGet(db.cacheRepositoryName, key); ok {
+			if isNegativeCacheValue(val) {
+				return nil, nil
+			}
 			var result *Record
 			if convertCacheValue(val, &result) {
 				return result, nil
 			}
 		}
-		result, err := db.dbMgr.queryFirstWithContext(ctx, sdb, querySQL, args...)
-		if err == nil && result != nil {
-			cache.CacheSet(db.cacheRepositoryName, key, result, getEffectiveTTL(db.cacheRepositoryName, db.cacheTTL))
-		}
-		return result, err
+		return loadOnce(db.cacheRepositoryName, key, func() (*Record, error) {
+			result, err := db.dbMgr.queryFirstWithContext(ctx, db.executor(sdb), querySQL, args...)
+			if err == nil && result != nil {
+				cache.CacheSet(db.cacheRepositoryName, key, result, getEffectiveTTL(db.cacheRepositoryName, db.cacheTTL))
+			} else if err == nil {
+				db.dbMgr.cacheNotFound(cache, db.cacheRepositoryName, key, querySQL, getEffectiveTTL(db.cacheRepositoryName, db.cacheTTL))
+			}
+			return result, err
+		})
 	}
-	return db.dbMgr.queryFirstWithContext(ctx, sdb, querySQL, args...)
+	return db.dbMgr.queryFirstWithContext(ctx, db.executor(sdb), querySQL, args...)
 }
 
 func (db *DB) QueryToDbModel(dest interface{}, querySQL string, args ...interface{}) error {
@@ -477,7 +587,7 @@ func (db *DB) QueryFirstToDbModel(dest interface{}, querySQL string, args ...int
 		return err
 	}
 	if record == nil {
-		return fmt.Errorf("dbkit: no record found")
+		return ErrRecordNotFound
 	}
 	return ToStruct(record, dest)
 }
@@ -501,13 +611,43 @@ func (db *DB) QueryMap(querySQL string, args ...interface{}) ([]map[string]inter
 				return results, nil
 			}
 		}
-		results, err := db.dbMgr.queryMapWithContext(ctx, sdb, querySQL, args...)
-		if err == nil {
-			cache.CacheSet(db.cacheRepositoryName, key, results, getEffectiveTTL(db.cacheRepositoryName, db.cacheTTL))
-		}
-		return results, err
+		return loadOnce(db.cacheRepositoryName, key, func() ([]map[string]interface{}, error) {
+			results, err := db.dbMgr.queryMapWithContext(ctx, db.executor(sdb), querySQL, args...)
+			if err == nil {
+				cache.CacheSet(db.cacheRepositoryName, key, results, getEffectiveTTL(db.cacheRepositoryName, db.cacheTTL))
+			}
+			return results, err
+		})
+	}
+	return db.dbMgr.queryMapWithContext(ctx, db.executor(sdb), querySQL, args...)
+}
+
+// QueryMulti executes a statement returning several result sets on this database.
+// 使用固定连接执行，结果不缓存
+func (db *DB) QueryMulti(querySQL string, args ...interface{}) ([][]*Record, error) {
+	if db.lastErr != nil {
+		return nil, db.lastErr
+	}
+	ctx, cancel := db.getContext()
+	defer cancel()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	return db.dbMgr.queryMultiWithContext(ctx, conn.executor(), querySQL, args...)
+}
+
+// QueryToNestedMap executes the query on this database and nests the rows by keyColumns
+func (db *DB) QueryToNestedMap(querySQL string, keyColumns []string, valueColumn string, args ...interface{}) (map[string]interface{}, error) {
+	if err := validateNestedMapColumns(keyColumns, valueColumn); err != nil {
+		return nil, err
+	}
+	records, err := db.Query(querySQL, args...)
+	if err != nil {
+		return nil, err
 	}
-	return db.dbMgr.queryMapWithContext(ctx, sdb, querySQL, args...)
+	return nestRecords(records, keyColumns, valueColumn)
 }
 
 // QueryWithOutTrashed 执行原始 SQL 查询并自动过滤软删除数据
@@ -570,10 +710,12 @@ func (db *DB) Exec(querySQL string, args ...interface{}) (sql.Result, error) {
 	}
 	ctx, cancel := db.getContext()
 	defer cancel()
-	return db.dbMgr.execWithContext(ctx, sdb, querySQL, args...)
+	return db.dbMgr.execWithContext(ctx, db.executor(sdb), querySQL, args...)
 }
 
-func (db *DB) Save(table string, record *Record) (int64, error) {
+// Save inserts the record or updates the existing row with the same primary key.
+// opts 中的 ConflictTarget 在 PostgreSQL / SQLite 上改按该冲突目标（如部分唯一索引）执行 upsert，记录中可以没有主键
+func (db *DB) Save(table string, record *Record, opts ...UpsertOptions) (int64, error) {
 	if db.lastErr != nil {
 		return 0, db.lastErr
 	}
@@ -581,7 +723,7 @@ func (db *DB) Save(table string, record *Record) (int64, error) {
 	if err != nil {
 		return 0, err
 	}
-	return db.dbMgr.save(sdb, table, record)
+	return db.dbMgr.save(db.executor(sdb), table, record, upsertOptions(opts))
 }
 
 func (db *DB) Insert(table string, record *Record) (int64, error) {
@@ -592,7 +734,7 @@ func (db *DB) Insert(table string, record *Record) (int64, error) {
 	if err != nil {
 		return 0, err
 	}
-	return db.dbMgr.insert(sdb, table, record)
+	return db.dbMgr.insert(db.executor(sdb), table, record)
 }
 
 func (db *DB) insertWithOptions(table string, record *Record, skipTimestamps bool) (int64, error) {
@@ -603,7 +745,18 @@ func (db *DB) insertWithOptions(table string, record *Record, skipTimestamps boo
 	if err != nil {
 		return 0, err
 	}
-	return db.dbMgr.insertWithOptions(sdb, table, record, skipTimestamps)
+	return db.dbMgr.insertWithOptions(db.executor(sdb), table, record, skipTimestamps)
+}
+
+func (db *DB) InsertIgnore(table string, record *Record, conflictColumns ...string) (int64, error) {
+	if db.lastErr != nil {
+		return 0, db.lastErr
+	}
+	sdb, err := db.dbMgr.getDB()
+	if err != nil {
+		return 0, err
+	}
+	return db.dbMgr.insertIgnore(db.executor(sdb), table, record, conflictColumns)
 }
 
 func (db *DB) Update(table string, record *Record, whereSql string, whereArgs ...interface{}) (int64, error) {
@@ -616,9 +769,9 @@ func (db *DB) Update(table string, record *Record, whereSql string, whereArgs ..
 	}
 	// If both feature checks are disabled, use fast path directly
 	if !db.dbMgr.enableTimestampCheck && !db.dbMgr.enableOptimisticLockCheck {
-		return db.dbMgr.updateFast(sdb, table, record, whereSql, whereArgs...)
+		return db.dbMgr.updateFast(db.executor(sdb), table, record, whereSql, whereArgs...)
 	}
-	return db.dbMgr.update(sdb, table, record, whereSql, whereArgs...)
+	return db.dbMgr.update(db.executor(sdb), table, record, whereSql, whereArgs...)
 }
 
 // UpdateFast is a lightweight update that always skips timestamp and optimistic lock checks.
@@ -630,7 +783,7 @@ func (db *DB) UpdateFast(table string, record *Record, whereSql string, whereArg
 	if err != nil {
 		return 0, err
 	}
-	return db.dbMgr.updateFast(sdb, table, record, whereSql, whereArgs...)
+	return db.dbMgr.updateFast(db.executor(sdb), table, record, whereSql, whereArgs...)
 }
 
 func (db *DB) updateWithOptions(table string, record *Record, whereSql string, skipTimestamps bool, whereArgs ...interface{}) (int64, error) {
@@ -641,7 +794,18 @@ func (db *DB) updateWithOptions(table string, record *Record, whereSql string, s
 	if err != nil {
 		return 0, err
 	}
-	return db.dbMgr.updateWithOptions(sdb, table, record, whereSql, skipTimestamps, whereArgs...)
+	return db.dbMgr.updateWithOptions(db.executor(sdb), table, record, whereSql, skipTimestamps, whereArgs...)
+}
+
+func (db *DB) increment(table string, deltas map[string]interface{}, op string, whereSql string, skipTimestamps bool, whereArgs ...interface{}) (int64, error) {
+	if db.lastErr != nil {
+		return 0, db.lastErr
+	}
+	sdb, err := db.dbMgr.getDB()
+	if err != nil {
+		return 0, err
+	}
+	return db.dbMgr.increment(db.executor(sdb), table, deltas, op, whereSql, skipTimestamps, whereArgs...)
 }
 
 func (db *DB) UpdateRecord(table string, record *Record) (int64, error) {
@@ -652,7 +816,7 @@ func (db *DB) UpdateRecord(table string, record *Record) (int64, error) {
 	if err != nil {
 		return 0, err
 	}
-	return db.dbMgr.updateRecord(sdb, table, record)
+	return db.dbMgr.updateRecord(db.executor(sdb), table, record)
 }
 
 func (db *DB) Delete(table string, whereSql string, whereArgs ...interface{}) (int64, error) {
@@ -663,7 +827,7 @@ func (db *DB) Delete(table string, whereSql string, whereArgs ...interface{}) (i
 	if err != nil {
 		return 0, err
 	}
-	return db.dbMgr.delete(sdb, table, whereSql, whereArgs...)
+	return db.dbMgr.delete(db.executor(sdb), table, whereSql, whereArgs...)
 }
 
 func (db *DB) DeleteRecord(table string, record *Record) (int64, error) {
@@ -674,7 +838,7 @@ func (db *DB) DeleteRecord(table string, record *Record) (int64, error) {
 	if err != nil {
 		return 0, err
 	}
-	return db.dbMgr.deleteRecord(sdb, table, record)
+	return db.dbMgr.deleteRecord(db.executor(sdb), table, record)
 }
 
 func (db *DB) BatchInsert(table string, records []*Record, batchSize int) (int64, error) {
@@ -685,13 +849,37 @@ func (db *DB) BatchInsert(table string, records []*Record, batchSize int) (int64
 	if err != nil {
 		return 0, err
 	}
-	return db.dbMgr.batchInsert(sdb, table, records, batchSize)
+	return db.dbMgr.batchInsert(db.executor(sdb), table, records, batchSize)
 }
 
 func (db *DB) BatchInsertDefault(table string, records []*Record) (int64, error) {
 	return db.BatchInsert(table, records, DefaultBatchSize)
 }
 
+// BatchInsertPartial inserts records one by one and collects per-row failures instead of aborting
+func (db *DB) BatchInsertPartial(table string, records []*Record) (int64, []BatchError, error) {
+	if db.lastErr != nil {
+		return 0, nil, db.lastErr
+	}
+	sdb, err := db.dbMgr.getDB()
+	if err != nil {
+		return 0, nil, err
+	}
+	return db.dbMgr.batchInsertPartial(db.executor(sdb), table, records)
+}
+
+// BatchInsertGrouped inserts records whose column sets differ, one multi-row INSERT per column set
+func (db *DB) BatchInsertGrouped(table string, records []*Record, batchSize int) (int64, error) {
+	if db.lastErr != nil {
+		return 0, db.lastErr
+	}
+	sdb, err := db.dbMgr.getDB()
+	if err != nil {
+		return 0, err
+	}
+	return db.dbMgr.batchInsertGrouped(db.executor(sdb), table, records, batchSize)
+}
+
 // BatchUpdate updates multiple records by primary key
 func (db *DB) BatchUpdate(table string, records []*Record, batchSize int) (int64, error) {
 	if db.lastErr != nil {
@@ -701,7 +889,7 @@ func (db *DB) BatchUpdate(table string, records []*Record, batchSize int) (int64
 	if err != nil {
 		return 0, err
 	}
-	return db.dbMgr.batchUpdate(sdb, table, records, batchSize)
+	return db.dbMgr.batchUpdate(db.executor(sdb), table, records, batchSize)
 }
 
 // BatchUpdateDefault updates multiple records with default batch size
@@ -718,7 +906,7 @@ func (db *DB) BatchDelete(table string, records []*Record, batchSize int) (int64
 	if err != nil {
 		return 0, err
 	}
-	return db.dbMgr.batchDelete(sdb, table, records, batchSize)
+	return db.dbMgr.batchDelete(db.executor(sdb), table, records, batchSize)
 }
 
 // BatchDeleteDefault deletes multiple records with default batch size
@@ -735,7 +923,7 @@ func (db *DB) BatchDeleteByIds(table string, ids []interface{}, batchSize int) (
 	if err != nil {
 		return 0, err
 	}
-	return db.dbMgr.batchDeleteByIds(sdb, table, ids, batchSize)
+	return db.dbMgr.batchDeleteByIds(db.executor(sdb), table, ids, batchSize)
 }
 
 // BatchDeleteByIdsDefault deletes records by IDs with default batch size
@@ -760,13 +948,15 @@ func (db *DB) Count(table string, whereSql string, whereArgs ...interface{}) (in
 				return count, nil
 			}
 		}
-		count, err := db.dbMgr.count(sdb, table, whereSql, whereArgs...)
-		if err == nil {
-			cache.CacheSet(db.cacheRepositoryName, key, count, getEffectiveTTL(db.cacheRepositoryName, db.cacheTTL))
-		}
-		return count, err
+		return loadOnce(db.cacheRepositoryName, key, func() (int64, error) {
+			count, err := db.dbMgr.count(db.executor(sdb), table, whereSql, whereArgs...)
+			if err == nil {
+				cache.CacheSet(db.cacheRepositoryName, key, count, getEffectiveTTL(db.cacheRepositoryName, db.cacheTTL))
+			}
+			return count, err
+		})
 	}
-	return db.dbMgr.count(sdb, table, whereSql, whereArgs...)
+	return db.dbMgr.count(db.executor(sdb), table, whereSql, whereArgs...)
 }
 
 func (db *DB) Ping() error {
@@ -784,13 +974,14 @@ func (db *DB) Exists(table string, whereSql string, whereArgs ...interface{}) (b
 	if err != nil {
 		return false, err
 	}
-	return db.dbMgr.exists(sdb, table, whereSql, whereArgs...)
+	return db.dbMgr.exists(db.executor(sdb), table, whereSql, whereArgs...)
 }
 
 func (db *DB) PaginateBuilder(page int, pageSize int, selectSql string, table string, whereSql string, orderBySql string, args ...interface{}) (*Page[Record], error) {
 	if db.lastErr != nil {
 		return nil, db.lastErr
 	}
+	page, pageSize = normalizePageParams(page, pageSize)
 	sdb, err := db.dbMgr.getDB()
 	if err != nil {
 		return nil, err
@@ -825,16 +1016,18 @@ func (db *DB) PaginateBuilder(page int, pageSize int, selectSql string, table st
 				return pageObj, nil
 			}
 		}
-		list, totalRow, err := db.dbMgr.paginate(sdb, querySQL, page, pageSize, db.countCacheTTL, args...)
-		if err == nil {
+		return loadOnce(db.cacheRepositoryName, key, func() (*Page[Record], error) {
+			list, totalRow, err := db.dbMgr.paginate(db.executor(sdb), querySQL, page, pageSize, db.countCacheTTL, args...)
+			if err != nil {
+				return nil, err
+			}
 			pageObj := NewPage(list, page, pageSize, totalRow)
 			cache.CacheSet(db.cacheRepositoryName, key, pageObj, getEffectiveTTL(db.cacheRepositoryName, db.cacheTTL))
 			return pageObj, nil
-		}
-		return nil, err
+		})
 	}
 
-	list, totalRow, err := db.dbMgr.paginate(sdb, querySQL, page, pageSize, db.countCacheTTL, args...)
+	list, totalRow, err := db.dbMgr.paginate(db.executor(sdb), querySQL, page, pageSize, db.countCacheTTL, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -847,6 +1040,7 @@ func (db *DB) Paginate(page int, pageSize int, querySQL string, args ...interfac
 	if db.lastErr != nil {
 		return nil, db.lastErr
 	}
+	page, pageSize = normalizePageParams(page, pageSize)
 	sdb, err := db.dbMgr.getDB()
 	if err != nil {
 		return nil, err
@@ -861,16 +1055,18 @@ func (db *DB) Paginate(page int, pageSize int, querySQL string, args ...interfac
 				return pageObj, nil
 			}
 		}
-		list, totalRow, err := db.dbMgr.paginate(sdb, querySQL, page, pageSize, db.countCacheTTL, args...)
-		if err == nil {
+		return loadOnce(db.cacheRepositoryName, key, func() (*Page[Record], error) {
+			list, totalRow, err := db.dbMgr.paginate(db.executor(sdb), querySQL, page, pageSize, db.countCacheTTL, args...)
+			if err != nil {
+				return nil, err
+			}
 			pageObj := NewPage(list, page, pageSize, totalRow)
 			cache.CacheSet(db.cacheRepositoryName, key, pageObj, getEffectiveTTL(db.cacheRepositoryName, db.cacheTTL))
 			return pageObj, nil
-		}
-		return nil, err
+		})
 	}
 
-	list, totalRow, err := db.dbMgr.paginate(sdb, querySQL, page, pageSize, db.countCacheTTL, args...)
+	list, totalRow, err := db.dbMgr.paginate(db.executor(sdb), querySQL, page, pageSize, db.countCacheTTL, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -887,37 +1083,40 @@ func (db *DB) FindAll(table string) ([]Record, error) {
 	return db.Query(fmt.Sprintf("SELECT * FROM %s", table))
 }
 
+// FindWhere returns the rows of table matching all equality conditions
+func (db *DB) FindWhere(table string, conditions map[string]interface{}) ([]Record, error) {
+	return db.Table(table).WhereMap(conditions).Find()
+}
+
 // Struct methods for DB
 func (db *DB) SaveDbModel(model IDbModel) (int64, error) {
 	if db.lastErr != nil {
 		return 0, db.lastErr
 	}
+	if err := validateModel(model); err != nil {
+		return 0, err
+	}
 	sdb, err := db.dbMgr.getDB()
 	if err != nil {
 		return 0, err
 	}
-	record := ToRecord(model)
-	// For Save, we also want to handle auto-increment PKs if they are 0
-	pks, _ := db.dbMgr.getPrimaryKeys(sdb, model.TableName())
-	for _, pk := range pks {
-		if val, ok := record.Get(pk).(int64); ok && val == 0 {
-			record.Remove(pk)
-		}
-	}
-	return db.Save(model.TableName(), record)
+	return db.dbMgr.saveModel(db.executor(sdb), model)
 }
 
 func (db *DB) InsertDbModel(model IDbModel) (int64, error) {
 	if db.lastErr != nil {
 		return 0, db.lastErr
 	}
+	if err := validateModel(model); err != nil {
+		return 0, err
+	}
 	sdb, err := db.dbMgr.getDB()
 	if err != nil {
 		return 0, err
 	}
 	record := ToRecord(model)
 	// Remove primary key if it's 0 to let DB auto-increment
-	pks, _ := db.dbMgr.getPrimaryKeys(sdb, model.TableName())
+	pks, _ := db.dbMgr.getPrimaryKeys(db.executor(sdb), model.TableName())
 	for _, pk := range pks {
 		if val, ok := record.Get(pk).(int64); ok && val == 0 {
 			record.Remove(pk)
@@ -930,6 +1129,9 @@ func (db *DB) UpdateDbModel(model IDbModel) (int64, error) {
 	if db.lastErr != nil {
 		return 0, db.lastErr
 	}
+	if err := validateModel(model); err != nil {
+		return 0, err
+	}
 	record := ToRecord(model)
 	return db.UpdateRecord(model.TableName(), record)
 }
@@ -969,6 +1171,50 @@ func (db *DB) FindToDbModel(dest interface{}, table string, whereSql string, ord
 
 // Transaction executes a function within a transaction
 func (db *DB) Transaction(fn func(*Tx) error) (err error) {
+	return db.transaction(db.parentContext(), fn)
+}
+
+// TransactionTimeout executes fn within a transaction bounded by d as a whole.
+// 超时后事务上下文被取消，后续语句失败并整体回滚，返回的错误可用 errors.Is(err, context.DeadlineExceeded) 判断；
+// 单条语句仍受 QueryTimeout / tx.Timeout() 约束
+func (db *DB) TransactionTimeout(d time.Duration, fn func(*Tx) error) error {
+	if d <= 0 {
+		return db.Transaction(fn)
+	}
+	ctx, cancel := context.WithTimeout(db.parentContext(), d)
+	defer cancel()
+	return db.transaction(ctx, fn)
+}
+
+// TransactionContext executes fn within a transaction bound to ctx.
+// 取消 ctx 会中断正在执行的语句并回滚，无需等待当前语句完成；ctx 为 nil 时等同于 Transaction
+func (db *DB) TransactionContext(ctx context.Context, fn func(*Tx) error) error {
+	if ctx == nil {
+		return db.Transaction(fn)
+	}
+	return db.transaction(ctx, fn)
+}
+
+// BeginTransactionContext starts a transaction bound to ctx, ctx 被取消时事务自动回滚
+func (db *DB) BeginTransactionContext(ctx context.Context) (*Tx, error) {
+	if db.lastErr != nil {
+		return nil, db.lastErr
+	}
+	if ctx == nil {
+		ctx = db.parentContext()
+	}
+	sdb, err := db.dbMgr.getDB()
+	if err != nil {
+		return nil, err
+	}
+	tx, err := sdb.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{tx: tx, dbMgr: db.dbMgr, ctx: ctx}, nil
+}
+
+func (db *DB) transaction(ctx context.Context, fn func(*Tx) error) (err error) {
 	if db.lastErr != nil {
 		return db.lastErr
 	}
@@ -976,16 +1222,16 @@ func (db *DB) Transaction(fn func(*Tx) error) (err error) {
 	if err != nil {
 		return err
 	}
-	tx, err := sdb.Begin()
+	tx, err := sdb.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 
-	dbtx := &Tx{tx: tx, dbMgr: db.dbMgr}
+	dbtx := &Tx{tx: tx, dbMgr: db.dbMgr, ctx: ctx}
 
 	defer func() {
 		if p := recover(); p != nil {
-			if rbErr := tx.Rollback(); rbErr != nil {
+			if rbErr := dbtx.Rollback(); rbErr != nil {
 				LogError("transaction rollback failed on panic", map[string]interface{}{
 					"rollback_error": rbErr.Error(),
 				})
@@ -995,16 +1241,25 @@ func (db *DB) Transaction(fn func(*Tx) error) (err error) {
 	}()
 
 	if err = fn(dbtx); err != nil {
-		if rbErr := tx.Rollback(); rbErr != nil {
+		if rbErr := dbtx.Rollback(); rbErr != nil && !errors.Is(rbErr, sql.ErrTxDone) {
 			LogError("transaction rollback failed", map[string]interface{}{
 				"original_error": err.Error(),
 				"rollback_error": rbErr.Error(),
 			})
 		}
+		if ctxErr := ctx.Err(); ctxErr != nil && !errors.Is(err, ctxErr) {
+			return fmt.Errorf("dbkit: transaction aborted: %w: %v", ctxErr, err)
+		}
 		return err
 	}
 
-	return tx.Commit()
+	// 闭包执行完但整体已超时：database/sql 已自动回滚，不能再提交
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		_ = dbtx.Rollback()
+		return fmt.Errorf("dbkit: transaction aborted: %w", ctxErr)
+	}
+
+	return dbtx.Commit()
 }
 
 // --- Tx Methods (Operation within a transaction) ---
@@ -1082,11 +1337,15 @@ func (tx *Tx) getTimeout() time.Duration {
 
 // getContext returns a context with timeout if configured
 func (tx *Tx) getContext() (context.Context, context.CancelFunc) {
+	parent := tx.ctx
+	if parent == nil {
+		parent = context.Background()
+	}
 	timeout := tx.getTimeout()
 	if timeout > 0 {
-		return context.WithTimeout(context.Background(), timeout)
+		return context.WithTimeout(parent, timeout)
 	}
-	return context.Background(), func() {}
+	return parent, func() {}
 }
 
 func (tx *Tx) Query(querySQL string, args ...interface{}) ([]Record, error) {
@@ -1102,16 +1361,23 @@ func (tx *Tx) Query(querySQL string, args ...interface{}) ([]Record, error) {
 				return results, nil
 			}
 		}
-		results, err := tx.dbMgr.queryWithContext(ctx, tx.tx, querySQL, args...)
+		results, err := tx.dbMgr.queryWithContext(ctx, tx.executor(), querySQL, args...)
 		if err == nil {
 			cache.CacheSet(tx.cacheRepositoryName, key, results, getEffectiveTTL(tx.cacheRepositoryName, tx.cacheTTL))
 		}
 		return results, err
 	}
-	return tx.dbMgr.queryWithContext(ctx, tx.tx, querySQL, args...)
+	return tx.dbMgr.queryWithContext(ctx, tx.executor(), querySQL, args...)
 }
 
+// QueryFirst executes a query within the transaction and returns the first record.
+// 没有匹配的记录时默认返回 (nil, nil)，调用 SetQueryFirstNotFoundError(true) 后返回 (nil, ErrNoRows)
 func (tx *Tx) QueryFirst(querySQL string, args ...interface{}) (*Record, error) {
+	return queryFirstResult(tx.queryFirst(querySQL, args...))
+}
+
+// queryFirst 执行 QueryFirst，没有匹配的记录时总是返回 (nil, nil)，供内部调用
+func (tx *Tx) queryFirst(querySQL string, args ...interface{}) (*Record, error) {
 	ctx, cancel := tx.getContext()
 	defer cancel()
 
@@ -1124,13 +1390,13 @@ func (tx *Tx) QueryFirst(querySQL string, args ...interface{}) (*Record, error)
 				return result, nil
 			}
 		}
-		result, err := tx.dbMgr.queryFirstWithContext(ctx, tx.tx, querySQL, args...)
+		result, err := tx.dbMgr.queryFirstWithContext(ctx, tx.executor(), querySQL, args...)
 		if err == nil && result != nil {
 			cache.CacheSet(tx.cacheRepositoryName, key, result, getEffectiveTTL(tx.cacheRepositoryName, tx.cacheTTL))
 		}
 		return result, err
 	}
-	return tx.dbMgr.queryFirstWithContext(ctx, tx.tx, querySQL, args...)
+	return tx.dbMgr.queryFirstWithContext(ctx, tx.executor(), querySQL, args...)
 }
 
 func (tx *Tx) QueryToDbModel(dest interface{}, querySQL string, args ...interface{}) error {
@@ -1147,7 +1413,7 @@ func (tx *Tx) QueryFirstToDbModel(dest interface{}, querySQL string, args ...int
 		return err
 	}
 	if record == nil {
-		return fmt.Errorf("dbkit: no record found")
+		return ErrRecordNotFound
 	}
 	return ToStruct(record, dest)
 }
@@ -1165,13 +1431,76 @@ func (tx *Tx) QueryMap(querySQL string, args ...interface{}) ([]map[string]inter
 				return results, nil
 			}
 		}
-		results, err := tx.dbMgr.queryMapWithContext(ctx, tx.tx, querySQL, args...)
+		results, err := tx.dbMgr.queryMapWithContext(ctx, tx.executor(), querySQL, args...)
 		if err == nil {
 			cache.CacheSet(tx.cacheRepositoryName, key, results, getEffectiveTTL(tx.cacheRepositoryName, tx.cacheTTL))
 		}
 		return results, err
 	}
-	return tx.dbMgr.queryMapWithContext(ctx, tx.tx, querySQL, args...)
+	return tx.dbMgr.queryMapWithContext(ctx, tx.executor(), querySQL, args...)
+}
+
+// QueryMulti executes a statement returning several result sets within the transaction
+func (tx *Tx) QueryMulti(querySQL string, args ...interface{}) ([][]*Record, error) {
+	ctx, cancel := tx.getContext()
+	defer cancel()
+	return tx.dbMgr.queryMultiWithContext(ctx, tx.executor(), querySQL, args...)
+}
+
+// QueryToNestedMap executes the query within the transaction and nests the rows by keyColumns
+func (tx *Tx) QueryToNestedMap(querySQL string, keyColumns []string, valueColumn string, args ...interface{}) (map[string]interface{}, error) {
+	if err := validateNestedMapColumns(keyColumns, valueColumn); err != nil {
+		return nil, err
+	}
+	records, err := tx.Query(querySQL, args...)
+	if err != nil {
+		return nil, err
+	}
+	return nestRecords(records, keyColumns, valueColumn)
+}
+
+func validateNestedMapColumns(keyColumns []string, valueColumn string) error {
+	if len(keyColumns) == 0 {
+		return fmt.Errorf("dbkit: QueryToNestedMap requires at least one key column")
+	}
+	if valueColumn == "" {
+		return fmt.Errorf("dbkit: QueryToNestedMap requires a value column")
+	}
+	return nil
+}
+
+// nestRecords 按 keyColumns 逐层建立 map[string]interface{}，最内层保存 valueColumn 的值（[]byte 转换为 string）
+func nestRecords(records []Record, keyColumns []string, valueColumn string) (map[string]interface{}, error) {
+	result := make(map[string]interface{})
+	for i := range records {
+		record := &records[i]
+		for _, col := range keyColumns {
+			if !record.Has(col) {
+				return nil, fmt.Errorf("dbkit: column '%s' not found in query results", col)
+			}
+		}
+		if !record.Has(valueColumn) {
+			return nil, fmt.Errorf("dbkit: column '%s' not found in query results", valueColumn)
+		}
+
+		level := result
+		for _, col := range keyColumns[:len(keyColumns)-1] {
+			key := record.GetString(col)
+			next, ok := level[key].(map[string]interface{})
+			if !ok {
+				next = make(map[string]interface{})
+				level[key] = next
+			}
+			level = next
+		}
+
+		value := record.Get(valueColumn)
+		if b, ok := value.([]byte); ok {
+			value = string(b)
+		}
+		level[record.GetString(keyColumns[len(keyColumns)-1])] = value
+	}
+	return result, nil
 }
 
 // QueryWithOutTrashed 在事务上下文中执行原始 SQL 查询并自动过滤软删除数据
@@ -1219,52 +1548,70 @@ func (tx *Tx) QueryFirstWithOutTrashed(querySQL string, args ...interface{}) (*R
 func (tx *Tx) Exec(querySQL string, args ...interface{}) (sql.Result, error) {
 	ctx, cancel := tx.getContext()
 	defer cancel()
-	return tx.dbMgr.execWithContext(ctx, tx.tx, querySQL, args...)
+	return tx.dbMgr.execWithContext(ctx, tx.executor(), querySQL, args...)
 }
 
-func (tx *Tx) Save(table string, record *Record) (int64, error) {
-	return tx.dbMgr.save(tx.tx, table, record)
+func (tx *Tx) Save(table string, record *Record, opts ...UpsertOptions) (int64, error) {
+	return tx.dbMgr.save(tx.executor(), table, record, upsertOptions(opts))
 }
 
 func (tx *Tx) Insert(table string, record *Record) (int64, error) {
-	return tx.dbMgr.insert(tx.tx, table, record)
+	return tx.dbMgr.insert(tx.executor(), table, record)
 }
 
 func (tx *Tx) insertWithOptions(table string, record *Record, skipTimestamps bool) (int64, error) {
-	return tx.dbMgr.insertWithOptions(tx.tx, table, record, skipTimestamps)
+	return tx.dbMgr.insertWithOptions(tx.executor(), table, record, skipTimestamps)
+}
+
+func (tx *Tx) InsertIgnore(table string, record *Record, conflictColumns ...string) (int64, error) {
+	return tx.dbMgr.insertIgnore(tx.executor(), table, record, conflictColumns)
 }
 
 func (tx *Tx) Update(table string, record *Record, whereSql string, whereArgs ...interface{}) (int64, error) {
-	return tx.dbMgr.update(tx.tx, table, record, whereSql, whereArgs...)
+	return tx.dbMgr.update(tx.executor(), table, record, whereSql, whereArgs...)
 }
 
 func (tx *Tx) updateWithOptions(table string, record *Record, whereSql string, skipTimestamps bool, whereArgs ...interface{}) (int64, error) {
-	return tx.dbMgr.updateWithOptions(tx.tx, table, record, whereSql, skipTimestamps, whereArgs...)
+	return tx.dbMgr.updateWithOptions(tx.executor(), table, record, whereSql, skipTimestamps, whereArgs...)
+}
+
+func (tx *Tx) increment(table string, deltas map[string]interface{}, op string, whereSql string, skipTimestamps bool, whereArgs ...interface{}) (int64, error) {
+	return tx.dbMgr.increment(tx.executor(), table, deltas, op, whereSql, skipTimestamps, whereArgs...)
 }
 
 func (tx *Tx) UpdateRecord(table string, record *Record) (int64, error) {
-	return tx.dbMgr.updateRecord(tx.tx, table, record)
+	return tx.dbMgr.updateRecord(tx.executor(), table, record)
 }
 
 func (tx *Tx) Delete(table string, whereSql string, whereArgs ...interface{}) (int64, error) {
-	return tx.dbMgr.delete(tx.tx, table, whereSql, whereArgs...)
+	return tx.dbMgr.delete(tx.executor(), table, whereSql, whereArgs...)
 }
 
 func (tx *Tx) DeleteRecord(table string, record *Record) (int64, error) {
-	return tx.dbMgr.deleteRecord(tx.tx, table, record)
+	return tx.dbMgr.deleteRecord(tx.executor(), table, record)
 }
 
 func (tx *Tx) BatchInsert(table string, records []*Record, batchSize int) (int64, error) {
-	return tx.dbMgr.batchInsert(tx.tx, table, records, batchSize)
+	return tx.dbMgr.batchInsert(tx.executor(), table, records, batchSize)
 }
 
 func (tx *Tx) BatchInsertDefault(table string, records []*Record) (int64, error) {
 	return tx.BatchInsert(table, records, DefaultBatchSize)
 }
 
+// BatchInsertPartial inserts records one by one within transaction, using a savepoint per row
+func (tx *Tx) BatchInsertPartial(table string, records []*Record) (int64, []BatchError, error) {
+	return tx.dbMgr.batchInsertPartial(tx.executor(), table, records)
+}
+
+// BatchInsertGrouped inserts records whose column sets differ within transaction
+func (tx *Tx) BatchInsertGrouped(table string, records []*Record, batchSize int) (int64, error) {
+	return tx.dbMgr.batchInsertGrouped(tx.executor(), table, records, batchSize)
+}
+
 // BatchUpdate updates multiple records by primary key within transaction
 func (tx *Tx) BatchUpdate(table string, records []*Record, batchSize int) (int64, error) {
-	return tx.dbMgr.batchUpdate(tx.tx, table, records, batchSize)
+	return tx.dbMgr.batchUpdate(tx.executor(), table, records, batchSize)
 }
 
 // BatchUpdateDefault updates multiple records with default batch size
@@ -1274,7 +1621,7 @@ func (tx *Tx) BatchUpdateDefault(table string, records []*Record) (int64, error)
 
 // BatchDelete deletes multiple records by primary key within transaction
 func (tx *Tx) BatchDelete(table string, records []*Record, batchSize int) (int64, error) {
-	return tx.dbMgr.batchDelete(tx.tx, table, records, batchSize)
+	return tx.dbMgr.batchDelete(tx.executor(), table, records, batchSize)
 }
 
 // BatchDeleteDefault deletes multiple records with default batch size
@@ -1284,7 +1631,7 @@ func (tx *Tx) BatchDeleteDefault(table string, records []*Record) (int64, error)
 
 // BatchDeleteByIds deletes records by primary key IDs within transaction
 func (tx *Tx) BatchDeleteByIds(table string, ids []interface{}, batchSize int) (int64, error) {
-	return tx.dbMgr.batchDeleteByIds(tx.tx, table, ids, batchSize)
+	return tx.dbMgr.batchDeleteByIds(tx.executor(), table, ids, batchSize)
 }
 
 // BatchDeleteByIdsDefault deletes records by IDs with default batch size
@@ -1302,20 +1649,21 @@ func (tx *Tx) Count(table string, whereSql string, whereArgs ...interface{}) (in
 				return count, nil
 			}
 		}
-		count, err := tx.dbMgr.count(tx.tx, table, whereSql, whereArgs...)
+		count, err := tx.dbMgr.count(tx.executor(), table, whereSql, whereArgs...)
 		if err == nil {
 			cache.CacheSet(tx.cacheRepositoryName, key, count, getEffectiveTTL(tx.cacheRepositoryName, tx.cacheTTL))
 		}
 		return count, err
 	}
-	return tx.dbMgr.count(tx.tx, table, whereSql, whereArgs...)
+	return tx.dbMgr.count(tx.executor(), table, whereSql, whereArgs...)
 }
 
 func (tx *Tx) Exists(table string, whereSql string, whereArgs ...interface{}) (bool, error) {
-	return tx.dbMgr.exists(tx.tx, table, whereSql, whereArgs...)
+	return tx.dbMgr.exists(tx.executor(), table, whereSql, whereArgs...)
 }
 
 func (tx *Tx) PaginateBuilder(page int, pageSize int, selectSql string, table string, whereSql string, orderBySql string, args ...interface{}) (*Page[Record], error) {
+	page, pageSize = normalizePageParams(page, pageSize)
 	if table != "" {
 		if err := ValidateTableName(table); err != nil {
 			return nil, err
@@ -1346,7 +1694,7 @@ func (tx *Tx) PaginateBuilder(page int, pageSize int, selectSql string, table st
 				return pageObj, nil
 			}
 		}
-		list, totalRow, err := tx.dbMgr.paginate(tx.tx, querySQL, page, pageSize, tx.countCacheTTL, args...)
+		list, totalRow, err := tx.dbMgr.paginate(tx.executor(), querySQL, page, pageSize, tx.countCacheTTL, args...)
 		if err == nil {
 			pageObj := NewPage(list, page, pageSize, totalRow)
 			cache.CacheSet(tx.cacheRepositoryName, key, pageObj, getEffectiveTTL(tx.cacheRepositoryName, tx.cacheTTL))
@@ -1355,7 +1703,7 @@ func (tx *Tx) PaginateBuilder(page int, pageSize int, selectSql string, table st
 		return nil, err
 	}
 
-	list, totalRow, err := tx.dbMgr.paginate(tx.tx, querySQL, page, pageSize, tx.countCacheTTL, args...)
+	list, totalRow, err := tx.dbMgr.paginate(tx.executor(), querySQL, page, pageSize, tx.countCacheTTL, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -1365,6 +1713,7 @@ func (tx *Tx) PaginateBuilder(page int, pageSize int, selectSql string, table st
 // Paginate 事务分页方法，使用完整SQL语句进行分页查询
 // 在事务上下文中自动解析SQL并根据数据库类型生成相应的分页语句
 func (tx *Tx) Paginate(page int, pageSize int, querySQL string, args ...interface{}) (*Page[Record], error) {
+	page, pageSize = normalizePageParams(page, pageSize)
 	if tx.cacheRepositoryName != "" {
 		cache := tx.getEffectiveCache()
 		// 缓存键包含 page 和 pageSize，确保不同页码使用不同的缓存
@@ -1375,7 +1724,7 @@ func (tx *Tx) Paginate(page int, pageSize int, querySQL string, args ...interfac
 				return pageObj, nil
 			}
 		}
-		list, totalRow, err := tx.dbMgr.paginate(tx.tx, querySQL, page, pageSize, tx.countCacheTTL, args...)
+		list, totalRow, err := tx.dbMgr.paginate(tx.executor(), querySQL, page, pageSize, tx.countCacheTTL, args...)
 		if err == nil {
 			pageObj := NewPage(list, page, pageSize, totalRow)
 			cache.CacheSet(tx.cacheRepositoryName, key, pageObj, getEffectiveTTL(tx.cacheRepositoryName, tx.cacheTTL))
@@ -1384,7 +1733,7 @@ func (tx *Tx) Paginate(page int, pageSize int, querySQL string, args ...interfac
 		return nil, err
 	}
 
-	list, totalRow, err := tx.dbMgr.paginate(tx.tx, querySQL, page, pageSize, tx.countCacheTTL, args...)
+	list, totalRow, err := tx.dbMgr.paginate(tx.executor(), querySQL, page, pageSize, tx.countCacheTTL, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -1398,18 +1747,31 @@ func (tx *Tx) FindAll(table string) ([]Record, error) {
 	return tx.Query(fmt.Sprintf("SELECT * FROM %s", table))
 }
 
+// FindWhere returns the rows of table matching all equality conditions within transaction
+func (tx *Tx) FindWhere(table string, conditions map[string]interface{}) ([]Record, error) {
+	return tx.Table(table).WhereMap(conditions).Find()
+}
+
 // Struct methods for Tx
 func (tx *Tx) SaveDbModel(model IDbModel) (int64, error) {
-	record := ToRecord(model)
-	return tx.Save(model.TableName(), record)
+	if err := validateModel(model); err != nil {
+		return 0, err
+	}
+	return tx.dbMgr.saveModel(tx.executor(), model)
 }
 
 func (tx *Tx) InsertDbModel(model IDbModel) (int64, error) {
+	if err := validateModel(model); err != nil {
+		return 0, err
+	}
 	record := ToRecord(model)
 	return tx.Insert(model.TableName(), record)
 }
 
 func (tx *Tx) UpdateDbModel(model IDbModel) (int64, error) {
+	if err := validateModel(model); err != nil {
+		return 0, err
+	}
 	record := ToRecord(model)
 	return tx.UpdateRecord(model.TableName(), record)
 }
@@ -1438,11 +1800,48 @@ func (tx *Tx) FindToDbModel(dest interface{}, table string, whereSql string, ord
 	return builder.FindToDbModel(dest)
 }
 
+// Commit commits the transaction; if it was marked rollback-only it rolls back instead
+// and returns ErrTxRollbackOnly (wrapping the recorded cause, if any)
 func (tx *Tx) Commit() error {
-	return tx.tx.Commit()
+	if tx.IsRollbackOnly() {
+		if err := tx.tx.Rollback(); err != nil {
+			return err
+		}
+		tx.stateMu.Lock()
+		cause := tx.rollbackCause
+		tx.stateMu.Unlock()
+		finishNegativeCacheTx(tx.tx, false)
+		if cause != nil {
+			return fmt.Errorf("%w: %w", ErrTxRollbackOnly, cause)
+		}
+		return ErrTxRollbackOnly
+	}
+	err := tx.tx.Commit()
+	finishNegativeCacheTx(tx.tx, err == nil)
+	return err
+}
+
+// MarkRollbackOnly marks the transaction so that it can only be rolled back.
+// 后续语句仍可继续执行（例如用于诊断的只读查询），但最终 Commit 会改为回滚；
+// 可选的 cause 记录失败原因，只保留第一次标记时的原因
+func (tx *Tx) MarkRollbackOnly(cause ...error) {
+	tx.stateMu.Lock()
+	defer tx.stateMu.Unlock()
+	tx.rollbackOnly = true
+	if tx.rollbackCause == nil && len(cause) > 0 {
+		tx.rollbackCause = cause[0]
+	}
+}
+
+// IsRollbackOnly reports whether the transaction has been marked rollback-only
+func (tx *Tx) IsRollbackOnly() bool {
+	tx.stateMu.Lock()
+	defer tx.stateMu.Unlock()
+	return tx.rollbackOnly
 }
 
 func (tx *Tx) Rollback() error {
+	finishNegativeCacheTx(tx.tx, false)
 	return tx.tx.Rollback()
 }
 
@@ -1530,10 +1929,9 @@ func convertCacheValue(val interface{}, dest interface{}) bool {
 		}
 	}
 
-	// 2. 处理 RedisCache 返回的 JSON 字节数组（优化路径）
-	if jsonBytes, ok := val.([]byte); ok {
-		// 直接从字节数组反序列化，避免字符串转换
-		return json.Unmarshal(jsonBytes, dest) == nil
+	// 2. 处理 RedisCache 返回的字节数组，按 SetRedisCodec 配置的编码反序列化
+	if data, ok := val.([]byte); ok {
+		return GetRedisCodec().Unmarshal(data, dest) == nil
 	}
 
 	// 3. 降级到 JSON 转换（用于其他复杂类型）
@@ -0,0 +1,22 @@
+package dbkit
+
+import "sync/atomic"
+
+// queryFirstNotFoundError 控制 QueryFirst 没有匹配记录时是否返回 ErrNoRows
+var queryFirstNotFoundError atomic.Bool
+
+// SetQueryFirstNotFoundError makes QueryFirst / FindFirst return (nil, ErrNoRows) instead of (nil, nil) when no row matches.
+// 默认关闭以保持兼容：此时只检查 err 而不检查 record 的代码会在未找到时拿到 nil 记录，record.GetString 等调用返回零值，
+// 容易把"不存在"误当作空数据。开启后影响全局函数以及 DB、Tx、Conn、QueryBuilder、SqlTemplateBuilder 的 QueryFirst，
+// 可用 errors.Is(err, dbkit.ErrNoRows) 判断
+func SetQueryFirstNotFoundError(enabled bool) {
+	queryFirstNotFoundError.Store(enabled)
+}
+
+// queryFirstResult 按 SetQueryFirstNotFoundError 的设置转换 QueryFirst 未找到记录时的返回值
+func queryFirstResult(record *Record, err error) (*Record, error) {
+	if err == nil && record == nil && queryFirstNotFoundError.Load() {
+		return nil, ErrNoRows
+	}
+	return record, err
+}
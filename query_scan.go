@@ -0,0 +1,78 @@
+package dbkit
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// QueryScan executes a query and calls scanFn once for every row with the raw *sql.Rows.
+// scanFn 内只需调用 rows.Scan 读取当前行，不要调用 rows.Next 或 rows.Close；
+// 占位符转换、参数上限检查、超时与 SQL 日志与 Query 相同，连接在返回前释放。
+// scanFn 返回错误时立即停止遍历并原样返回该错误
+func QueryScan(querySQL string, scanFn func(rows *sql.Rows) error, args ...interface{}) error {
+	db, err := defaultDB()
+	if err != nil {
+		return err
+	}
+	return db.QueryScan(querySQL, scanFn, args...)
+}
+
+// QueryScan executes a query and calls scanFn once for every row with the raw *sql.Rows
+func (db *DB) QueryScan(querySQL string, scanFn func(rows *sql.Rows) error, args ...interface{}) error {
+	if db.lastErr != nil {
+		return db.lastErr
+	}
+	sdb, err := db.dbMgr.getDB()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := db.getContext()
+	defer cancel()
+	return db.dbMgr.queryScan(ctx, db.executor(sdb), querySQL, scanFn, args...)
+}
+
+// QueryScan executes a query within transaction and calls scanFn once for every row with the raw *sql.Rows
+func (tx *Tx) QueryScan(querySQL string, scanFn func(rows *sql.Rows) error, args ...interface{}) error {
+	ctx, cancel := tx.getContext()
+	defer cancel()
+	return tx.dbMgr.queryScan(ctx, tx.executor(), querySQL, scanFn, args...)
+}
+
+func (mgr *dbManager) queryScan(ctx context.Context, executor sqlExecutor, querySQL string, scanFn func(rows *sql.Rows) error, args ...interface{}) error {
+	if scanFn == nil {
+		return fmt.Errorf("dbkit: QueryScan requires a scan function")
+	}
+	if err := checkPlaceholderStyle(querySQL, mgr.config.Driver); err != nil {
+		return err
+	}
+	querySQL, args = mgr.prepareQuerySQL(querySQL, args...)
+	executor, querySQL, args, err := runMiddlewares(ctx, executor, OpQuery, querySQL, args)
+	if err != nil {
+		return err
+	}
+	if err := mgr.checkParamLimit(len(args)); err != nil {
+		return err
+	}
+	start := time.Now()
+
+	var rows *sql.Rows
+	if execCtx, ok := executor.(sqlExecutorContext); ok {
+		rows, err = execCtx.QueryContext(ctx, querySQL, args...)
+	} else {
+		rows, err = executor.Query(querySQL, args...)
+	}
+	err = mgr.logTrace(start, querySQL, args, err)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := scanFn(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -1,19 +1,28 @@
 package dbkit
 
 import (
+	"bytes"
+	"database/sql"
+	"encoding/base64"
+	"encoding/gob"
 	"encoding/json"
 	"fmt"
+	"io"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 // Record represents a single record in the database, similar to JFinal's ActiveRecord
-// columns 保留原始大小写用于生成 SQL，lowerKeyMap 用于大小写不敏感的快速查找
+// columns 保留原始大小写用于生成 SQL，lowerKeyMap 用于大小写不敏感的快速查找，
+// keys 记录字段的插入顺序（查询结果为列顺序），Keys() 与生成的 INSERT/UPDATE 列顺序都以此为准
 type Record struct {
 	columns     map[string]interface{} // 原始键名 -> 值
 	lowerKeyMap map[string]string      // 小写键名 -> 原始键名（用于快速查找）
+	keys        []string               // 原始键名，按插入顺序
 	mu          sync.RWMutex
 }
 
@@ -28,6 +37,11 @@ func NewRecord() *Record {
 // Set sets a column value in the Record with case-insensitive support for existing columns
 // 保留原始大小写用于 SQL 生成，同时维护小写映射用于快速查找
 func (r *Record) Set(column string, value interface{}) *Record {
+	if raw, ok := value.(sql.RawBytes); ok {
+		// RawBytes 引用驱动的缓冲区，下一次 rows.Next 后失效；复制为 []byte，按二进制参数绑定
+		value = cloneValue([]byte(raw))
+	}
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -42,6 +56,7 @@ func (r *Record) Set(column string, value interface{}) *Record {
 	// 新字段：保存原始大小写和映射关系
 	r.columns[column] = value
 	r.lowerKeyMap[lowerKey] = column
+	r.keys = append(r.keys, column)
 	return r
 }
 
@@ -196,6 +211,46 @@ func (r *Record) GetString(column string) string {
 	return fmt.Sprintf("%v", val)
 }
 
+// base64Bytes 控制 GetBytes 是否对字符串值做 base64 解码
+var base64Bytes atomic.Bool
+
+// SetBase64Bytes makes GetBytes base64-decode string values.
+// []byte 经 JSON 序列化（如 Redis 缓存的 JSONCodec）后会变为 base64 字符串，开启后 GetBytes 可还原原始字节；
+// 解码失败时仍返回字符串本身的字节
+func SetBase64Bytes(enabled bool) {
+	base64Bytes.Store(enabled)
+}
+
+// GetBytes gets a column value as raw bytes, for BLOB / BYTEA / VARBINARY / RAW columns
+// 非二进制的值按 GetString 的结果转换为字节
+func (r *Record) GetBytes(column string) []byte {
+	return toBytes(r.getValue(column))
+}
+
+// toBytes 把字段值转换为字节：[]byte 原样返回，字符串在开启 SetBase64Bytes 时先尝试 base64 解码，其他值使用其字符串形式
+func toBytes(val interface{}) []byte {
+	val = normalizeArg(val)
+	if val == nil {
+		return nil
+	}
+	switch v := val.(type) {
+	case []byte:
+		return v
+	case sql.RawBytes:
+		b := make([]byte, len(v))
+		copy(b, v)
+		return b
+	case string:
+		if base64Bytes.Load() {
+			if b, err := base64.StdEncoding.DecodeString(v); err == nil {
+				return b
+			}
+		}
+		return []byte(v)
+	}
+	return []byte(fmt.Sprint(val))
+}
+
 // GetBool gets a column value as bool
 func (r *Record) GetBool(column string) bool {
 	val := r.getValue(column)
@@ -220,6 +275,69 @@ func (r *Record) GetBool(column string) bool {
 	return false
 }
 
+// GetRecord gets a nested record, such as a BelongsTo relation loaded by QueryBuilder.With
+func (r *Record) GetRecord(column string) *Record {
+	if v, ok := r.getValue(column).(*Record); ok {
+		return v
+	}
+	return nil
+}
+
+// GetRecords gets nested records, such as a HasMany relation loaded by QueryBuilder.With
+func (r *Record) GetRecords(column string) []*Record {
+	switch v := r.getValue(column).(type) {
+	case []*Record:
+		return v
+	case []Record:
+		list := make([]*Record, len(v))
+		for i := range v {
+			list[i] = &v[i]
+		}
+		return list
+	}
+	return nil
+}
+
+// isNull 字段不存在、值为 nil 或 typed nil 指针时视为 NULL
+func (r *Record) isNull(column string) bool {
+	return normalizeArg(r.getValue(column)) == nil
+}
+
+// GetNullString gets a column value as sql.NullString, Valid is false when the column is NULL or missing
+func (r *Record) GetNullString(column string) sql.NullString {
+	if r.isNull(column) {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: r.GetString(column), Valid: true}
+}
+
+// GetNullInt64 gets a column value as sql.NullInt64, Valid is false when the column is NULL, missing or not convertible
+func (r *Record) GetNullInt64(column string) sql.NullInt64 {
+	i, err := toInt64(normalizeArg(r.getValue(column)))
+	if err != nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: i, Valid: true}
+}
+
+// GetNullFloat64 gets a column value as sql.NullFloat64, Valid is false when the column is NULL, missing or not convertible
+func (r *Record) GetNullFloat64(column string) sql.NullFloat64 {
+	f, err := toFloat64(normalizeArg(r.getValue(column)))
+	if err != nil {
+		return sql.NullFloat64{}
+	}
+	return sql.NullFloat64{Float64: f, Valid: true}
+}
+
+// GetNullTime gets a column value as sql.NullTime, Valid is false when the column is NULL, missing or not convertible
+func (r *Record) GetNullTime(column string) sql.NullTime {
+	t, err := toTime(normalizeArg(r.getValue(column)))
+	if err != nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: t, Valid: true}
+}
+
 // Has checks if a column exists in the Record
 // Has checks if a column exists in the Record
 func (r *Record) Has(column string) bool {
@@ -231,28 +349,98 @@ func (r *Record) Has(column string) bool {
 	return exists
 }
 
-// Keys returns all column names
+// Keys returns all column names in insertion order (column order for query results)
 func (r *Record) Keys() []string {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	keys := make([]string, 0, len(r.columns))
+	keys := make([]string, len(r.keys))
+	copy(keys, r.keys)
+	return keys
+}
+
+// removeKey 从 keys 中删除字段，调用方需持有写锁
+func (r *Record) removeKey(key string) {
+	for i, k := range r.keys {
+		if k == key {
+			r.keys = append(r.keys[:i], r.keys[i+1:]...)
+			return
+		}
+	}
+}
+
+// rebuildKeys 在 columns 被整体替换后重建小写映射与 keys，order 中不存在的字段被忽略，
+// 遗漏的字段按键名排序追加在后面，调用方需持有写锁
+func (r *Record) rebuildKeys(order []string) {
+	r.lowerKeyMap = make(map[string]string, len(r.columns))
+	r.keys = make([]string, 0, len(r.columns))
+	for _, k := range order {
+		if _, ok := r.columns[k]; ok {
+			if _, seen := r.lowerKeyMap[strings.ToLower(k)]; !seen {
+				r.lowerKeyMap[strings.ToLower(k)] = k
+				r.keys = append(r.keys, k)
+			}
+		}
+	}
+	if len(r.keys) == len(r.columns) {
+		return
+	}
+	var rest []string
 	for k := range r.columns {
-		keys = append(keys, k)
+		if _, seen := r.lowerKeyMap[strings.ToLower(k)]; !seen {
+			rest = append(rest, k)
+		}
+	}
+	sort.Strings(rest)
+	for _, k := range rest {
+		r.lowerKeyMap[strings.ToLower(k)] = k
+		r.keys = append(r.keys, k)
 	}
-	return keys
 }
 
-// Remove removes a column from the Record with case-insensitive support
-func (r *Record) Remove(column string) {
+// Remove removes columns from the Record with case-insensitive support and returns the Record for chaining
+func (r *Record) Remove(columns ...string) *Record {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	lowerKey := strings.ToLower(column)
-	if actualKey, exists := r.lowerKeyMap[lowerKey]; exists {
-		delete(r.columns, actualKey)
+	for _, column := range columns {
+		lowerKey := strings.ToLower(column)
+		if actualKey, exists := r.lowerKeyMap[lowerKey]; exists {
+			delete(r.columns, actualKey)
+			delete(r.lowerKeyMap, lowerKey)
+			r.removeKey(actualKey)
+		}
+	}
+	return r
+}
+
+// Except removes the listed columns, e.g. record.Except("id", "created_at") before inserting it as a new row
+func (r *Record) Except(columns ...string) *Record {
+	return r.Remove(columns...)
+}
+
+// Only keeps the listed columns (case-insensitive) and removes all others
+func (r *Record) Only(columns ...string) *Record {
+	keep := make(map[string]bool, len(columns))
+	for _, column := range columns {
+		keep[strings.ToLower(column)] = true
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	kept := r.keys[:0]
+	for _, key := range r.keys {
+		lowerKey := strings.ToLower(key)
+		if keep[lowerKey] {
+			kept = append(kept, key)
+			continue
+		}
+		delete(r.columns, key)
 		delete(r.lowerKeyMap, lowerKey)
 	}
+	r.keys = kept
+	return r
 }
 
 // Clear clears all columns
@@ -261,6 +449,7 @@ func (r *Record) Clear() {
 	defer r.mu.Unlock()
 	r.columns = make(map[string]interface{})
 	r.lowerKeyMap = make(map[string]string)
+	r.keys = nil
 }
 
 // ToMap converts the Record to a map
@@ -276,6 +465,73 @@ func (r *Record) ToMap() map[string]interface{} {
 	return newMap
 }
 
+// Clone returns a deep copy of the Record
+// 复制字段映射及其中的 []byte、map、slice、*Record 等引用类型，修改副本不会影响原记录
+func (r *Record) Clone() *Record {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	clone := &Record{
+		columns:     make(map[string]interface{}, len(r.columns)),
+		lowerKeyMap: make(map[string]string, len(r.lowerKeyMap)),
+		keys:        make([]string, len(r.keys)),
+	}
+	copy(clone.keys, r.keys)
+	for k, v := range r.columns {
+		clone.columns[k] = cloneValue(v)
+	}
+	for k, v := range r.lowerKeyMap {
+		clone.lowerKeyMap[k] = v
+	}
+	return clone
+}
+
+// cloneValue 深拷贝 Record 中常见的引用类型值
+func cloneValue(val interface{}) interface{} {
+	switch v := val.(type) {
+	case []byte:
+		if v == nil {
+			return v
+		}
+		b := make([]byte, len(v))
+		copy(b, v)
+		return b
+	case *Record:
+		if v == nil {
+			return v
+		}
+		return v.Clone()
+	case []*Record:
+		if v == nil {
+			return v
+		}
+		s := make([]*Record, len(v))
+		for i, item := range v {
+			s[i] = cloneValue(item).(*Record)
+		}
+		return s
+	case map[string]interface{}:
+		if v == nil {
+			return v
+		}
+		m := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			m[k] = cloneValue(item)
+		}
+		return m
+	case []interface{}:
+		if v == nil {
+			return v
+		}
+		s := make([]interface{}, len(v))
+		for i, item := range v {
+			s[i] = cloneValue(item)
+		}
+		return s
+	}
+	return val
+}
+
 // ToJson converts the Record to JSON string
 func (r *Record) ToJson() string {
 	data, err := r.MarshalJSON()
@@ -296,24 +552,78 @@ func (r *Record) MarshalJSON() ([]byte, error) {
 }
 
 // UnmarshalJSON implements the json.Unmarshaler interface
+// 字段顺序与 JSON 中的键顺序一致
 func (r *Record) UnmarshalJSON(data []byte) error {
+	columns := make(map[string]interface{})
+	if err := json.Unmarshal(data, &columns); err != nil {
+		return err
+	}
+	order, err := jsonObjectKeys(data)
+	if err != nil {
+		return err
+	}
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
+	r.columns = columns
+	r.rebuildKeys(order)
+	return nil
+}
 
-	// 清空现有数据
-	r.columns = make(map[string]interface{})
-	r.lowerKeyMap = make(map[string]string)
+// jsonObjectKeys 按出现顺序返回 JSON 对象的顶层键（重复的键只保留第一次出现的位置）
+func jsonObjectKeys(data []byte) ([]string, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil || tok == nil {
+		// null 解析为空记录
+		return nil, err
+	}
+	var keys []string
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := tok.(string)
+		keys = append(keys, key)
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
 
-	// 反序列化
-	if err := json.Unmarshal(data, &r.columns); err != nil {
-		return err
+// GobEncode implements gob.GobEncoder, used by GobCodec for the Redis cache
+// 先编码字段映射，再编码字段顺序，旧版本解码时会忽略后者
+func (r *Record) GobEncode() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := gob.NewEncoder(&buf)
+	if err := enc.Encode(r.ToMap()); err != nil {
+		return nil, err
 	}
+	if err := enc.Encode(r.Keys()); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
 
-	// 重建小写映射
-	for k := range r.columns {
-		r.lowerKeyMap[strings.ToLower(k)] = k
+// GobDecode implements gob.GobDecoder
+// 兼容只包含字段映射的旧数据，此时字段按键名排序
+func (r *Record) GobDecode(data []byte) error {
+	dec := gob.NewDecoder(bytes.NewReader(data))
+	columns := make(map[string]interface{})
+	if err := dec.Decode(&columns); err != nil {
+		return err
+	}
+	var order []string
+	if err := dec.Decode(&order); err != nil && err != io.EOF {
+		return err
 	}
 
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.columns = columns
+	r.rebuildKeys(order)
 	return nil
 }
 
@@ -0,0 +1,140 @@
+package dbkit
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Equal reports whether two records hold the same columns and values.
+// 列名不区分大小写，与字段顺序无关；值按以下规则比较：不同的整数、浮点类型按数值比较（int32(1)、int64(1)、1.0 相等），
+// []byte 与 string 按内容比较，time.Time 按时间点比较（忽略时区），*Record、[]*Record、map、slice 递归比较。
+// 两个 nil 记录相等
+func (r *Record) Equal(other *Record) bool {
+	if r == nil || other == nil {
+		return r == nil && other == nil
+	}
+	if r == other {
+		return true
+	}
+	return r.canonical() == other.canonical()
+}
+
+// Hash returns a stable hash of the record's columns and values, consistent with Equal.
+// Equal 的记录 Hash 一定相同，可用作 map 的键对结果去重（Hash 相同时仍应以 Equal 确认）；
+// 结果与进程无关，但不保证在 dbkit 版本之间保持不变，不要持久化
+func (r *Record) Hash() uint64 {
+	h := fnv.New64a()
+	if r != nil {
+		h.Write([]byte(r.canonical()))
+	}
+	return h.Sum64()
+}
+
+// canonical 生成按小写列名排序的规范化表示，Equal 与 Hash 都基于它
+func (r *Record) canonical() string {
+	r.mu.RLock()
+	keys := make([]string, 0, len(r.columns))
+	values := make(map[string]interface{}, len(r.columns))
+	for k, v := range r.columns {
+		lower := strings.ToLower(k)
+		keys = append(keys, lower)
+		values[lower] = v
+	}
+	r.mu.RUnlock()
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	sb.WriteByte('{')
+	for _, k := range keys {
+		sb.WriteString(strconv.Quote(k))
+		sb.WriteByte(':')
+		writeCanonicalValue(&sb, values[k])
+		sb.WriteByte(',')
+	}
+	sb.WriteByte('}')
+	return sb.String()
+}
+
+// writeCanonicalValue 写入值的规范化表示，类型前缀保证字符串 "1" 与数值 1 不相等
+func writeCanonicalValue(sb *strings.Builder, v interface{}) {
+	// *Record 须在 normalizeArg 解引用之前处理
+	switch val := v.(type) {
+	case *Record:
+		if val == nil {
+			sb.WriteString("null")
+			return
+		}
+		sb.WriteString(val.canonical())
+		return
+	case []*Record:
+		sb.WriteByte('[')
+		for _, item := range val {
+			writeCanonicalValue(sb, item)
+			sb.WriteByte(',')
+		}
+		sb.WriteByte(']')
+		return
+	}
+
+	v = normalizeArg(v)
+	if valuer, ok := v.(driver.Valuer); ok {
+		// sql.NullString 等按其数据库值比较
+		if dv, err := valuer.Value(); err == nil {
+			v = normalizeArg(dv)
+		}
+	}
+	switch val := v.(type) {
+	case nil:
+		sb.WriteString("null")
+	case []byte:
+		sb.WriteString("s" + strconv.Quote(string(val)))
+	case string:
+		sb.WriteString("s" + strconv.Quote(val))
+	case bool:
+		sb.WriteString("b" + strconv.FormatBool(val))
+	case time.Time:
+		sb.WriteString("t" + val.UTC().Format(time.RFC3339Nano))
+	case int, int8, int16, int32, int64:
+		sb.WriteString("n" + strconv.FormatInt(reflect.ValueOf(val).Int(), 10))
+	case uint, uint8, uint16, uint32, uint64:
+		sb.WriteString("n" + strconv.FormatUint(reflect.ValueOf(val).Uint(), 10))
+	case float32, float64:
+		f := reflect.ValueOf(val).Float()
+		if f == math.Trunc(f) && math.Abs(f) < 1<<63 {
+			// 整数值的浮点数与整数使用相同的表示
+			sb.WriteString("n" + strconv.FormatInt(int64(f), 10))
+		} else {
+			sb.WriteString("n" + strconv.FormatFloat(f, 'g', -1, 64))
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		sb.WriteByte('{')
+		for _, k := range keys {
+			sb.WriteString(strconv.Quote(k))
+			sb.WriteByte(':')
+			writeCanonicalValue(sb, val[k])
+			sb.WriteByte(',')
+		}
+		sb.WriteByte('}')
+	case []interface{}:
+		sb.WriteByte('[')
+		for _, item := range val {
+			writeCanonicalValue(sb, item)
+			sb.WriteByte(',')
+		}
+		sb.WriteByte(']')
+	default:
+		sb.WriteString(fmt.Sprintf("%T:%v", val, val))
+	}
+}
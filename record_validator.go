@@ -0,0 +1,237 @@
+package dbkit
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// emailPattern 用于 email 规则的宽松邮箱格式校验
+var emailPattern = regexp.MustCompile(`^[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}$`)
+
+// FieldError 表示单个字段未通过某条校验规则
+type FieldError struct {
+	Column string
+	Rule   string
+	Reason string
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Column, e.Reason)
+}
+
+// ValidationErrors 汇总一次校验中的所有字段错误
+// 可以通过 errors.As 取出并逐个处理
+type ValidationErrors []*FieldError
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Error()
+	}
+	return "dbkit: validation failed: " + strings.Join(msgs, "; ")
+}
+
+// ValidateRecord validates record columns against rules, e.g.
+// {"email": "required|email", "age": "min=0|max=150", "name": "required|max=50"}.
+// Supported rules: required, email, numeric, min=N, max=N, len=N, in=a,b,c.
+// For strings min/max/len apply to the character count, for numbers to the value.
+// 除 required 外，字段为空时其余规则不做检查；所有字段错误合并为 ValidationErrors 返回
+func ValidateRecord(record *Record, rules map[string]string) error {
+	if record == nil {
+		return fmt.Errorf("dbkit: record is nil")
+	}
+	columns := make([]string, 0, len(rules))
+	for column := range rules {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns) // 保证错误顺序稳定
+
+	var errs ValidationErrors
+	for _, column := range columns {
+		fieldErrs, err := validateValue(column, record.Get(column), rules[column])
+		if err != nil {
+			return err
+		}
+		errs = append(errs, fieldErrs...)
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// validateModel 根据 DbModel 结构体字段上的 validate 标签校验模型
+func validateModel(model interface{}) error {
+	val := reflect.ValueOf(model)
+	if val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return nil
+		}
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var errs ValidationErrors
+	for _, fieldInfo := range getStructCacheInfo(val.Type()).fields {
+		if fieldInfo.validate == "" || !fieldInfo.canSet {
+			continue
+		}
+		fieldErrs, err := validateValue(fieldInfo.columnName, val.Field(fieldInfo.fieldIndex).Interface(), fieldInfo.validate)
+		if err != nil {
+			return err
+		}
+		errs = append(errs, fieldErrs...)
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// validateValue 对单个值执行以 | 分隔的规则列表
+// 规则书写错误（未知规则、参数非法）直接返回 error，而不是当作校验失败
+func validateValue(column string, value interface{}, ruleStr string) ([]*FieldError, error) {
+	value = derefValue(value)
+	empty := isBlankValue(value)
+
+	var errs []*FieldError
+	for _, rule := range strings.Split(ruleStr, "|") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+		name, param := rule, ""
+		if idx := strings.Index(rule, "="); idx != -1 {
+			name, param = strings.TrimSpace(rule[:idx]), strings.TrimSpace(rule[idx+1:])
+		}
+
+		if name == "required" {
+			if empty {
+				errs = append(errs, &FieldError{Column: column, Rule: rule, Reason: "is required"})
+			}
+			continue
+		}
+		if empty {
+			continue
+		}
+
+		reason, err := checkRule(name, param, value)
+		if err != nil {
+			return nil, fmt.Errorf("dbkit: invalid validation rule '%s' for column '%s': %v", rule, column, err)
+		}
+		if reason != "" {
+			errs = append(errs, &FieldError{Column: column, Rule: rule, Reason: reason})
+		}
+	}
+	return errs, nil
+}
+
+// checkRule 执行单条规则，返回非空 reason 表示校验不通过
+func checkRule(name, param string, value interface{}) (string, error) {
+	switch name {
+	case "email":
+		if !emailPattern.MatchString(fmt.Sprint(value)) {
+			return "must be a valid email address", nil
+		}
+	case "numeric":
+		if _, err := toFloat64(value); err != nil {
+			return "must be numeric", nil
+		}
+	case "min", "max", "len":
+		limit, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return "", fmt.Errorf("expects a number")
+		}
+		size, isLength := measureValue(value)
+		unit := ""
+		if isLength {
+			unit = " characters"
+		}
+		switch {
+		case name == "min" && size < limit:
+			if isLength {
+				return fmt.Sprintf("must be at least %s%s", param, unit), nil
+			}
+			return fmt.Sprintf("must be >= %s", param), nil
+		case name == "max" && size > limit:
+			if isLength {
+				return fmt.Sprintf("must be at most %s%s", param, unit), nil
+			}
+			return fmt.Sprintf("must be <= %s", param), nil
+		case name == "len" && size != limit:
+			return fmt.Sprintf("must be exactly %s%s", param, unit), nil
+		}
+	case "in":
+		if param == "" {
+			return "", fmt.Errorf("expects a comma separated list")
+		}
+		str := fmt.Sprint(value)
+		for _, opt := range strings.Split(param, ",") {
+			if strings.TrimSpace(opt) == str {
+				return "", nil
+			}
+		}
+		return fmt.Sprintf("must be one of [%s]", param), nil
+	default:
+		return "", fmt.Errorf("unknown rule")
+	}
+	return "", nil
+}
+
+// measureValue 返回用于 min/max/len 比较的量：字符串/[]byte 取字符数，数值取其值
+func measureValue(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case string:
+		return float64(utf8.RuneCountInString(v)), true
+	case []byte:
+		return float64(utf8.RuneCount(v)), true
+	}
+	if f, err := toFloat64(value); err == nil {
+		return f, false
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(rv.Len()), true
+	}
+	return 0, false
+}
+
+// derefValue 解引用指针值，便于对 *string、*int 等字段统一校验
+func derefValue(value interface{}) interface{} {
+	for value != nil {
+		rv := reflect.ValueOf(value)
+		if rv.Kind() != reflect.Ptr {
+			break
+		}
+		if rv.IsNil() {
+			return nil
+		}
+		value = rv.Elem().Interface()
+	}
+	return value
+}
+
+// isBlankValue 判断 required 规则意义上的"空"：nil、空白字符串、空切片/映射
+// 数值 0 和 false 视为已提供的值
+func isBlankValue(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	if s, ok := value.(string); ok {
+		return strings.TrimSpace(s) == ""
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return rv.Len() == 0
+	}
+	return false
+}
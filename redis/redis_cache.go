@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/go-redis/redis/v8"
@@ -11,8 +12,10 @@ import (
 
 // redisCache implements CacheProvider using Redis
 type redisCache struct {
-	client *redis.Client
-	ctx    context.Context
+	client  *redis.Client
+	ctx     context.Context
+	mu      sync.RWMutex
+	marshal func(v interface{}) ([]byte, error) // 值序列化函数，由 dbkit.SetRedisCodec 下发，默认 JSON
 }
 
 // NewRedisCache creates a new Redis-backed cache provider
@@ -25,8 +28,9 @@ func NewRedisCache(addr, username, password string, db int) (*redisCache, error)
 	})
 
 	rc := &redisCache{
-		client: client,
-		ctx:    context.Background(),
+		client:  client,
+		ctx:     context.Background(),
+		marshal: json.Marshal,
 	}
 
 	// 测试连接
@@ -63,18 +67,31 @@ func (r *redisCache) CacheSet(cacheRepositoryName, key string, value interface{}
 	case string, []byte:
 		data = value
 	default:
-		jsonData, err := json.Marshal(value)
+		r.mu.RLock()
+		marshal := r.marshal
+		r.mu.RUnlock()
+		encoded, err := marshal(value)
 		if err != nil {
 			// 序列化失败，记录日志并跳过存储
 			fmt.Printf("dbkit: redis cache marshal failed, key=%s, error=%v\n", fullKey, err)
 			return
 		}
-		data = jsonData
+		data = encoded
 	}
 
 	r.client.Set(r.ctx, fullKey, data, ttl)
 }
 
+// SetMarshaler 设置值的序列化函数（由 dbkit.SetRedisCodec / dbkit.InitRedisCache 调用）
+func (r *redisCache) SetMarshaler(marshal func(v interface{}) ([]byte, error)) {
+	if marshal == nil {
+		marshal = json.Marshal
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.marshal = marshal
+}
+
 func (r *redisCache) CacheDelete(cacheRepositoryName, key string) {
 	if cacheRepositoryName == "" || key == "" {
 		return // 忽略空参数
@@ -0,0 +1,305 @@
+package dbkit
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// RelationType is the kind of a relation configured with ConfigRelation
+type RelationType string
+
+const (
+	HasMany   RelationType = "has_many"   // 父表一行对应关联表多行，关联表的 ForeignKey 引用父表的 LocalKey
+	BelongsTo RelationType = "belongs_to" // 父表一行对应关联表一行，父表的 ForeignKey 引用关联表的 OwnerKey
+)
+
+// relationChunkSize 预加载时每条 IN 查询最多携带的键数量（Oracle 的 IN 列表上限为 1000）
+const relationChunkSize = 1000
+
+// Relation describes how a related table is loaded by QueryBuilder.With
+type Relation struct {
+	Type       RelationType
+	Table      string // 关联表
+	ForeignKey string // HasMany：关联表中引用父表的列；BelongsTo：父表中引用关联表的列
+	LocalKey   string // HasMany：父表中被引用的列，默认 "id"
+	OwnerKey   string // BelongsTo：关联表中被引用的列，默认 "id"
+}
+
+// eagerRelation 一个待预加载的关联
+type eagerRelation struct {
+	name string
+	rel  Relation
+}
+
+// relationRegistry stores relations per parent table
+type relationRegistry struct {
+	relations map[string]map[string]Relation // parent table（小写）-> name（小写）-> relation
+	mu        sync.RWMutex
+}
+
+// newRelationRegistry creates a new relation registry
+func newRelationRegistry() *relationRegistry {
+	return &relationRegistry{
+		relations: make(map[string]map[string]Relation),
+	}
+}
+
+// set configures a relation of a table
+func (r *relationRegistry) set(table, name string, rel Relation) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := strings.ToLower(table)
+	if r.relations[key] == nil {
+		r.relations[key] = make(map[string]Relation)
+	}
+	r.relations[key][strings.ToLower(name)] = rel
+}
+
+// get returns a relation of a table
+func (r *relationRegistry) get(table, name string) (Relation, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rel, ok := r.relations[strings.ToLower(table)][strings.ToLower(name)]
+	return rel, ok
+}
+
+// remove removes a relation of a table
+func (r *relationRegistry) remove(table, name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.relations[strings.ToLower(table)], strings.ToLower(name))
+}
+
+// --- Global Functions (for default database) ---
+
+// ConfigRelation declares a relation of parentTable on the default database, loaded by QueryBuilder.With(name).
+// 同名关联再次配置时替换之前的配置
+func ConfigRelation(parentTable, name string, rel Relation) {
+	db, err := defaultDB()
+	if err != nil {
+		return
+	}
+	db.ConfigRelation(parentTable, name, rel)
+}
+
+// RemoveRelation removes a relation of parentTable
+func RemoveRelation(parentTable, name string) {
+	db, err := defaultDB()
+	if err != nil {
+		return
+	}
+	db.RemoveRelation(parentTable, name)
+}
+
+// --- DB Methods ---
+
+// ConfigRelation declares a relation of parentTable, loaded by QueryBuilder.With(name)
+func (db *DB) ConfigRelation(parentTable, name string, rel Relation) *DB {
+	if db.lastErr != nil || db.dbMgr == nil {
+		return db
+	}
+	if rel.LocalKey == "" {
+		rel.LocalKey = "id"
+	}
+	if rel.OwnerKey == "" {
+		rel.OwnerKey = "id"
+	}
+	if rel.Type != HasMany && rel.Type != BelongsTo {
+		db.lastErr = fmt.Errorf("dbkit: invalid relation type '%s' for %s.%s", rel.Type, parentTable, name)
+		return db
+	}
+	if strings.TrimSpace(name) == "" {
+		db.lastErr = fmt.Errorf("dbkit: relation name is required for %s", parentTable)
+		return db
+	}
+	for _, ident := range []string{parentTable, rel.Table, rel.ForeignKey, rel.LocalKey, rel.OwnerKey} {
+		if err := validateIdentifier(ident); err != nil {
+			db.lastErr = fmt.Errorf("dbkit: invalid relation %s.%s: %v", parentTable, name, err)
+			return db
+		}
+	}
+	db.dbMgr.setRelation(parentTable, name, rel)
+	return db
+}
+
+// RemoveRelation removes a relation of parentTable
+func (db *DB) RemoveRelation(parentTable, name string) *DB {
+	if db.lastErr != nil || db.dbMgr == nil {
+		return db
+	}
+	db.dbMgr.removeRelation(parentTable, name)
+	return db
+}
+
+// --- dbManager Methods ---
+
+func (mgr *dbManager) setRelation(table, name string, rel Relation) {
+	mgr.mu.Lock()
+	if mgr.relations == nil {
+		mgr.relations = newRelationRegistry()
+	}
+	registry := mgr.relations
+	mgr.mu.Unlock()
+	registry.set(table, name, rel)
+}
+
+func (mgr *dbManager) removeRelation(table, name string) {
+	mgr.mu.RLock()
+	registry := mgr.relations
+	mgr.mu.RUnlock()
+	if registry != nil {
+		registry.remove(table, name)
+	}
+}
+
+func (mgr *dbManager) getRelation(table, name string) (Relation, bool) {
+	mgr.mu.RLock()
+	registry := mgr.relations
+	mgr.mu.RUnlock()
+	if registry == nil {
+		return Relation{}, false
+	}
+	return registry.get(table, name)
+}
+
+// --- QueryBuilder ---
+
+// With eager loads the named relations (see ConfigRelation) after the main query.
+// Query/Find、QueryFirst/FindFirst、Paginate 返回结果后，每个关联额外执行一条（键较多时按 1000 个分批）IN 查询，
+// 并以关联名写入每条父记录：HasMany 为 []*Record（没有子记录时为空切片），用 GetRecords 读取；
+// BelongsTo 为 *Record（不存在时为 nil），用 GetRecord 读取。关联表的软删除与租户过滤照常生效
+func (qb *QueryBuilder) With(relations ...string) *QueryBuilder {
+	if qb.lastErr != nil {
+		return qb
+	}
+	mgr := qb.getDbManager()
+	if mgr == nil {
+		qb.lastErr = fmt.Errorf("dbkit: database not initialized")
+		return qb
+	}
+	for _, name := range relations {
+		rel, ok := mgr.getRelation(qb.table, name)
+		if !ok {
+			qb.lastErr = fmt.Errorf("dbkit: relation '%s' is not configured for table '%s'", name, qb.table)
+			return qb
+		}
+		qb.with = append(qb.with, eagerRelation{name: name, rel: rel})
+	}
+	return qb
+}
+
+// loadRelations 为查询结果加载 With 指定的关联
+func (qb *QueryBuilder) loadRelations(records []Record) error {
+	ptrs := make([]*Record, len(records))
+	for i := range records {
+		ptrs[i] = &records[i]
+	}
+	return qb.loadRelationsFor(ptrs)
+}
+
+func (qb *QueryBuilder) loadRelationsFor(records []*Record) error {
+	if len(records) == 0 {
+		return nil
+	}
+	for _, er := range qb.with {
+		parentKey, relatedKey := er.rel.LocalKey, er.rel.ForeignKey
+		if er.rel.Type == BelongsTo {
+			parentKey, relatedKey = er.rel.ForeignKey, er.rel.OwnerKey
+		}
+
+		var keys []interface{}
+		seen := make(map[string]bool)
+		for _, r := range records {
+			if !r.Has(parentKey) {
+				return fmt.Errorf("dbkit: relation '%s' requires column '%s' in the query result", er.name, parentKey)
+			}
+			v := r.Get(parentKey)
+			if normalizeArg(v) == nil {
+				continue
+			}
+			if k := relationKey(v); !seen[k] {
+				seen[k] = true
+				keys = append(keys, v)
+			}
+		}
+
+		var related []Record
+		for start := 0; start < len(keys); start += relationChunkSize {
+			end := start + relationChunkSize
+			if end > len(keys) {
+				end = len(keys)
+			}
+			rows, err := qb.relationBuilder(er.rel.Table).WhereInValues(relatedKey, keys[start:end]).Find()
+			if err != nil {
+				return fmt.Errorf("dbkit: load relation '%s': %w", er.name, err)
+			}
+			related = append(related, rows...)
+		}
+
+		if er.rel.Type == BelongsTo {
+			owners := make(map[string]*Record, len(related))
+			for i := range related {
+				owners[relationKey(related[i].Get(relatedKey))] = &related[i]
+			}
+			for _, r := range records {
+				if owner, ok := owners[relationKey(r.Get(parentKey))]; ok && normalizeArg(r.Get(parentKey)) != nil {
+					r.Set(er.name, owner)
+				} else {
+					r.Set(er.name, nil)
+				}
+			}
+			continue
+		}
+
+		children := make(map[string][]*Record)
+		for i := range related {
+			k := relationKey(related[i].Get(relatedKey))
+			children[k] = append(children[k], &related[i])
+		}
+		for _, r := range records {
+			list := []*Record{}
+			if normalizeArg(r.Get(parentKey)) != nil {
+				if c := children[relationKey(r.Get(parentKey))]; c != nil {
+					list = c
+				}
+			}
+			r.Set(er.name, list)
+		}
+	}
+	return nil
+}
+
+// relationBuilder 在与主查询相同的数据库或事务上创建关联表的查询
+func (qb *QueryBuilder) relationBuilder(table string) *QueryBuilder {
+	var child *QueryBuilder
+	if qb.tx != nil {
+		child = qb.tx.Table(table)
+	} else {
+		child = qb.db.Table(table)
+	}
+	child.timeout = qb.timeout
+	// 主查询使用 IgnoreTenantScope 时关联查询同样跨租户；db / tx 已带有 skipTenant
+	child.ignoreTenantScope = qb.ignoreTenantScope
+	return child
+}
+
+// relationKey 把键值转换为比较用的字符串，驱动返回的数值类型不同（int32 与 int64）时结果相同
+func relationKey(v interface{}) string {
+	switch val := normalizeArg(v).(type) {
+	case []byte:
+		return string(val)
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+// cloneRecordSlice 深拷贝查询结果，避免加载关联时修改缓存中的记录
+func cloneRecordSlice(records []Record) []Record {
+	out := make([]Record, len(records))
+	for i := range records {
+		c := records[i].Clone()
+		out[i].columns, out[i].lowerKeyMap, out[i].keys = c.columns, c.lowerKeyMap, c.keys
+	}
+	return out
+}
@@ -0,0 +1,191 @@
+package dbkit
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// UpdateReturning updates records and returns the updated rows
+// PostgreSQL/SQLite 使用 RETURNING，SQL Server 使用 OUTPUT INSERTED.*，
+// MySQL/Oracle 在同一事务中先锁定匹配行的主键，更新后再按主键查询，避免读写之间的竞争
+// returningColumns 为空时返回所有列；没有匹配的行时返回 ErrRecordNotFound，带版本检查时返回 ErrVersionMismatch
+func UpdateReturning(table string, record *Record, returningColumns []string, where string, whereArgs ...interface{}) ([]Record, error) {
+	db, err := defaultDB()
+	if err != nil {
+		return nil, err
+	}
+	return db.UpdateReturning(table, record, returningColumns, where, whereArgs...)
+}
+
+// UpdateReturning updates records and returns the updated rows
+func (db *DB) UpdateReturning(table string, record *Record, returningColumns []string, where string, whereArgs ...interface{}) ([]Record, error) {
+	if db.lastErr != nil {
+		return nil, db.lastErr
+	}
+	ctx, cancel := db.getContext()
+	defer cancel()
+	sdb, err := db.dbMgr.getDB()
+	if err != nil {
+		return nil, err
+	}
+	return db.dbMgr.updateReturning(ctx, db.executor(sdb), table, record, returningColumns, where, whereArgs...)
+}
+
+// UpdateReturning updates records within transaction and returns the updated rows
+func (tx *Tx) UpdateReturning(table string, record *Record, returningColumns []string, where string, whereArgs ...interface{}) ([]Record, error) {
+	ctx, cancel := tx.getContext()
+	defer cancel()
+	return tx.dbMgr.updateReturning(ctx, tx.executor(), table, record, returningColumns, where, whereArgs...)
+}
+
+func (mgr *dbManager) updateReturning(ctx context.Context, executor sqlExecutor, table string, record *Record, returningColumns []string, where string, whereArgs ...interface{}) ([]Record, error) {
+	defer mgr.invalidateNegativeCacheOn(executor, table)
+	if err := validateIdentifier(table); err != nil {
+		return nil, err
+	}
+	if record == nil || len(record.columns) == 0 {
+		return nil, fmt.Errorf("record is empty")
+	}
+	for _, col := range returningColumns {
+		if err := validateIdentifier(col); err != nil {
+			return nil, err
+		}
+	}
+
+	driver := mgr.config.Driver
+	if driver == MySQL || driver == Oracle {
+		return mgr.updateThenSelect(ctx, executor, table, record, returningColumns, where, whereArgs...)
+	}
+
+	where, whereArgs, err := mgr.scopeTenant(executor, table, where, whereArgs)
+	if err != nil {
+		return nil, err
+	}
+	if mgr.enableTimestampCheck {
+		mgr.applyUpdatedAtTimestamp(table, record, false)
+	}
+	setSQL, where, values, versionChecked, err := mgr.buildUpdateParts(table, record, where, whereArgs)
+	if err != nil {
+		return nil, err
+	}
+
+	var querySQL string
+	if driver == SQLServer {
+		outputCols := []string{"INSERTED.*"}
+		if len(returningColumns) > 0 {
+			outputCols = make([]string, len(returningColumns))
+			for i, col := range returningColumns {
+				outputCols[i] = "INSERTED." + col
+			}
+		}
+		querySQL = fmt.Sprintf("UPDATE %s SET %s OUTPUT %s", table, setSQL, joinStrings(outputCols))
+		if where != "" {
+			querySQL += " WHERE " + where
+		}
+	} else {
+		querySQL = fmt.Sprintf("UPDATE %s SET %s", table, setSQL)
+		if where != "" {
+			querySQL += " WHERE " + where
+		}
+		querySQL += " RETURNING " + returningList(returningColumns)
+	}
+
+	records, err := mgr.queryWithContext(ctx, executor, querySQL, values...)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		if versionChecked {
+			return nil, ErrVersionMismatch
+		}
+		return nil, ErrRecordNotFound
+	}
+	return records, nil
+}
+
+// updateThenSelect 为不支持 RETURNING 的数据库模拟返回更新后的行：
+// 事务内先 SELECT ... FOR UPDATE 锁定匹配行的主键，执行 UPDATE，再按主键查询最新数据
+func (mgr *dbManager) updateThenSelect(ctx context.Context, executor sqlExecutor, table string, record *Record, returningColumns []string, where string, whereArgs ...interface{}) (result []Record, err error) {
+	if sdb, ok := unwrapExecutor(executor).(*sql.DB); ok {
+		tx, beginErr := sdb.BeginTx(ctx, nil)
+		if beginErr != nil {
+			return nil, beginErr
+		}
+		defer func() {
+			if err != nil {
+				finishNegativeCacheTx(tx, false)
+				tx.Rollback()
+				return
+			}
+			err = tx.Commit()
+			finishNegativeCacheTx(tx, err == nil)
+		}()
+		executor = rewrapExecutor(executor, tx)
+	}
+
+	pks, err := mgr.getPrimaryKeys(executor, table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get primary keys: %v", err)
+	}
+	if len(pks) == 0 {
+		return nil, fmt.Errorf("table %s has no primary key, cannot use UpdateReturning", table)
+	}
+
+	// UPDATE 由 updateWithOptions 自行追加租户条件，这里只需限定锁定的行
+	lockWhere, lockArgs, err := mgr.scopeTenant(executor, table, where, whereArgs)
+	if err != nil {
+		return nil, err
+	}
+	lockSQL := fmt.Sprintf("SELECT %s FROM %s", joinStrings(pks), table)
+	if lockWhere != "" {
+		lockSQL += " WHERE " + lockWhere
+	}
+	lockSQL += " FOR UPDATE"
+	locked, err := mgr.queryWithContext(ctx, executor, lockSQL, lockArgs...)
+	if err != nil {
+		return nil, err
+	}
+	if len(locked) == 0 {
+		// 与 RETURNING 路径一致：带版本检查时无法区分行不存在与版本已变化
+		if mgr.enableOptimisticLockCheck {
+			if config := mgr.getOptimisticLockConfig(table); config != nil && config.VersionField != "" {
+				if _, ok := mgr.getVersionFromRecord(table, record); ok {
+					return nil, ErrVersionMismatch
+				}
+			}
+		}
+		return nil, ErrRecordNotFound
+	}
+
+	// SET 中包含主键列时，更新后按新的主键值查询
+	var conditions []string
+	var pkArgs []interface{}
+	for i := range locked {
+		parts := make([]string, len(pks))
+		for j, pk := range pks {
+			parts[j] = pk + " = ?"
+			if record.Has(pk) {
+				pkArgs = append(pkArgs, record.Get(pk))
+			} else {
+				pkArgs = append(pkArgs, locked[i].Get(pk))
+			}
+		}
+		conditions = append(conditions, "("+strings.Join(parts, " AND ")+")")
+	}
+
+	if _, err = mgr.updateWithOptions(executor, table, record, where, false, whereArgs...); err != nil {
+		return nil, err
+	}
+	selectSQL := fmt.Sprintf("SELECT %s FROM %s WHERE %s", returningList(returningColumns), table, strings.Join(conditions, " OR "))
+	return mgr.queryWithContext(ctx, executor, selectSQL, pkArgs...)
+}
+
+// returningList 返回 RETURNING/SELECT 使用的列列表，空时为 *
+func returningList(columns []string) string {
+	if len(columns) == 0 {
+		return "*"
+	}
+	return joinStrings(columns)
+}
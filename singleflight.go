@@ -0,0 +1,94 @@
+package dbkit
+
+import "sync"
+
+// flightCall 表示一次正在进行中的加载
+type flightCall struct {
+	wg  sync.WaitGroup
+	val interface{}
+	err error
+}
+
+// flightGroup 合并相同 key 的并发加载（singleflight），防止缓存击穿：
+// 热点 key 失效瞬间的大量并发未命中只会触发一次数据库查询，其余请求等待并共享结果
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+// do 执行 fn 或等待正在进行的同 key 调用，shared 为 true 表示结果来自其他调用方的 fn
+func (g *flightGroup) do(key string, fn func() (interface{}, error)) (val interface{}, err error, shared bool) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*flightCall)
+	}
+	if c, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		c.wg.Wait()
+		return c.val, c.err, true
+	}
+	c := &flightCall{}
+	c.wg.Add(1)
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		c.wg.Done()
+	}()
+	c.val, c.err = fn()
+	return c.val, c.err, false
+}
+
+// cacheFlight 供 Cache()/LocalCache()/RedisCache() 读路径共用
+var cacheFlight flightGroup
+
+// loadOnce 在缓存未命中时执行 load，同一缓存仓库、同一 key 的并发调用只执行一次 load
+// load 内部负责写入缓存，等待者共享其结果（包括错误），但各自拿到一份副本，修改结果不会互相影响
+func loadOnce[T any](cacheRepositoryName, key string, load func() (T, error)) (T, error) {
+	val, err, shared := cacheFlight.do(cacheRepositoryName+"\x00"+key, func() (interface{}, error) {
+		return load()
+	})
+	if shared {
+		val = cloneLoaded(val)
+	}
+	result, _ := val.(T)
+	return result, err
+}
+
+// cloneLoaded 深拷贝 loadOnce 加载的查询结果，计数等值类型原样返回
+func cloneLoaded(val interface{}) interface{} {
+	switch v := val.(type) {
+	case []Record:
+		if v == nil {
+			return v
+		}
+		return cloneRecordSlice(v)
+	case *Record:
+		if v == nil {
+			return v
+		}
+		return v.Clone()
+	case []map[string]interface{}:
+		if v == nil {
+			return v
+		}
+		list := make([]map[string]interface{}, len(v))
+		for i, m := range v {
+			list[i] = cloneValue(m).(map[string]interface{})
+		}
+		return list
+	case *Page[Record]:
+		if v == nil {
+			return v
+		}
+		page := *v
+		if v.List != nil {
+			page.List = cloneRecordSlice(v.List)
+		}
+		return &page
+	}
+	return val
+}
@@ -1,6 +1,7 @@
 package dbkit
 
 import (
+	"database/sql"
 	"fmt"
 	"regexp"
 	"strings"
@@ -66,19 +67,46 @@ type SoftDeleteConfig struct {
 	Type  SoftDeleteType // Field type: timestamp or boolean
 }
 
+// CascadeRule describes a child table whose rows are soft-deleted and restored together with the parent
+type CascadeRule struct {
+	Table      string // 子表名，子表需已配置软删除
+	ForeignKey string // 子表中引用父表的列
+	ParentKey  string // 父表中被引用的列，为空时使用父表的单列主键
+}
+
 // softDeleteRegistry stores soft delete configurations per database
 type softDeleteRegistry struct {
-	configs map[string]*SoftDeleteConfig // table -> config
-	mu      sync.RWMutex
+	configs  map[string]*SoftDeleteConfig // table -> config
+	cascades map[string][]CascadeRule     // parent table -> child rules
+	mu       sync.RWMutex
 }
 
 // newSoftDeleteRegistry creates a new soft delete registry
 func newSoftDeleteRegistry() *softDeleteRegistry {
 	return &softDeleteRegistry{
-		configs: make(map[string]*SoftDeleteConfig),
+		configs:  make(map[string]*SoftDeleteConfig),
+		cascades: make(map[string][]CascadeRule),
 	}
 }
 
+// setCascade configures the cascade rules of a parent table, empty rules remove them
+func (r *softDeleteRegistry) setCascade(table string, rules []CascadeRule) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(rules) == 0 {
+		delete(r.cascades, strings.ToLower(table))
+		return
+	}
+	r.cascades[strings.ToLower(table)] = rules
+}
+
+// getCascade returns the cascade rules of a parent table
+func (r *softDeleteRegistry) getCascade(table string) []CascadeRule {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cascades[strings.ToLower(table)]
+}
+
 // set configures soft delete for a table
 func (r *softDeleteRegistry) set(table string, config *SoftDeleteConfig) {
 	r.mu.Lock()
@@ -135,6 +163,17 @@ func ConfigSoftDeleteWithType(table, field string, deleteType SoftDeleteType) {
 	db.ConfigSoftDeleteWithType(table, field, deleteType)
 }
 
+// ConfigSoftDeleteCascade makes soft-deleting parentTable also soft-delete the child rows referencing it,
+// and Restore on parentTable restore them, in the same transaction.
+// 子表本身配置了级联时逐级向下传递；传入空切片取消级联
+func ConfigSoftDeleteCascade(parentTable string, children []CascadeRule) {
+	db, err := defaultDB()
+	if err != nil {
+		return
+	}
+	db.ConfigSoftDeleteCascade(parentTable, children)
+}
+
 // RemoveSoftDelete removes soft delete configuration for a table
 func RemoveSoftDelete(table string) {
 	db, err := defaultDB()
@@ -204,6 +243,36 @@ func (db *DB) ConfigSoftDeleteWithType(table, field string, deleteType SoftDelet
 	return db
 }
 
+// ConfigSoftDeleteCascade configures the child tables soft-deleted and restored together with parentTable
+func (db *DB) ConfigSoftDeleteCascade(parentTable string, children []CascadeRule) *DB {
+	if db.lastErr != nil || db.dbMgr == nil {
+		return db
+	}
+
+	names := []string{parentTable}
+	for _, rule := range children {
+		names = append(names, rule.Table, rule.ForeignKey)
+		if rule.ParentKey != "" {
+			names = append(names, rule.ParentKey)
+		}
+	}
+	for _, name := range names {
+		if err := validateIdentifier(name); err != nil {
+			LogError("软删除级联配置无效", map[string]interface{}{
+				"db":    db.dbMgr.name,
+				"table": parentTable,
+				"error": err.Error(),
+			})
+			return db
+		}
+	}
+
+	rules := make([]CascadeRule, len(children))
+	copy(rules, children)
+	db.dbMgr.setSoftDeleteCascade(parentTable, rules)
+	return db
+}
+
 // RemoveSoftDelete removes soft delete configuration for a table
 func (db *DB) RemoveSoftDelete(table string) *DB {
 	if db.lastErr != nil || db.dbMgr == nil {
@@ -230,7 +299,7 @@ func (db *DB) ForceDelete(table string, whereSql string, whereArgs ...interface{
 	if err != nil {
 		return 0, err
 	}
-	return db.dbMgr.forceDelete(sdb, table, whereSql, whereArgs...)
+	return db.dbMgr.forceDelete(db.executor(sdb), table, whereSql, whereArgs...)
 }
 
 // Restore restores soft-deleted records
@@ -242,19 +311,19 @@ func (db *DB) Restore(table string, whereSql string, whereArgs ...interface{}) (
 	if err != nil {
 		return 0, err
 	}
-	return db.dbMgr.restore(sdb, table, whereSql, whereArgs...)
+	return db.dbMgr.restore(db.executor(sdb), table, whereSql, whereArgs...)
 }
 
 // --- Tx Methods ---
 
 // ForceDelete performs a physical delete within a transaction
 func (tx *Tx) ForceDelete(table string, whereSql string, whereArgs ...interface{}) (int64, error) {
-	return tx.dbMgr.forceDelete(tx.tx, table, whereSql, whereArgs...)
+	return tx.dbMgr.forceDelete(tx.executor(), table, whereSql, whereArgs...)
 }
 
 // Restore restores soft-deleted records within a transaction
 func (tx *Tx) Restore(table string, whereSql string, whereArgs ...interface{}) (int64, error) {
-	return tx.dbMgr.restore(tx.tx, table, whereSql, whereArgs...)
+	return tx.dbMgr.restore(tx.executor(), table, whereSql, whereArgs...)
 }
 
 // --- dbManager Methods ---
@@ -291,8 +360,119 @@ func (mgr *dbManager) hasSoftDelete(table string) bool {
 	return mgr.softDeletes.has(table)
 }
 
-// softDelete performs a soft delete (UPDATE instead of DELETE)
-func (mgr *dbManager) softDelete(executor sqlExecutor, table string, where string, whereArgs ...interface{}) (int64, error) {
+// setSoftDeleteCascade sets the cascade rules of a parent table
+func (mgr *dbManager) setSoftDeleteCascade(table string, rules []CascadeRule) {
+	if mgr.softDeletes == nil {
+		mgr.softDeletes = newSoftDeleteRegistry()
+	}
+	mgr.softDeletes.setCascade(table, rules)
+}
+
+// getSoftDeleteCascade gets the cascade rules of a parent table
+func (mgr *dbManager) getSoftDeleteCascade(table string) []CascadeRule {
+	if mgr.softDeletes == nil {
+		return nil
+	}
+	return mgr.softDeletes.getCascade(table)
+}
+
+// softDelete performs a soft delete (UPDATE instead of DELETE)，配置了级联时在同一事务中先软删除子表
+func (mgr *dbManager) softDelete(executor sqlExecutor, table string, where string, whereArgs ...interface{}) (affected int64, err error) {
+	if len(mgr.getSoftDeleteCascade(table)) == 0 {
+		return mgr.softDeleteRows(executor, table, mgr.timestampNow(), where, whereArgs...)
+	}
+	executor, finish, err := cascadeTx(executor)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { err = finish(err) }()
+	return mgr.softDeleteTree(executor, table, mgr.timestampNow(), where, whereArgs, map[string]bool{})
+}
+
+// softDeleteTree 先按级联规则递归软删除子表中引用匹配行且未删除的行，再软删除本表；
+// 同一次操作使用相同的删除时间，path 记录当前路径上的表，自引用或循环的规则不再递归
+func (mgr *dbManager) softDeleteTree(executor sqlExecutor, table string, now time.Time, where string, whereArgs []interface{}, path map[string]bool) (int64, error) {
+	path[strings.ToLower(table)] = true
+	defer delete(path, strings.ToLower(table))
+
+	for _, rule := range mgr.getSoftDeleteCascade(table) {
+		if path[strings.ToLower(rule.Table)] {
+			continue
+		}
+		childConfig := mgr.getSoftDeleteConfig(rule.Table)
+		if childConfig == nil {
+			return 0, fmt.Errorf("dbkit: soft delete not configured for cascade child table %s", rule.Table)
+		}
+		childWhere, childArgs, err := mgr.cascadeWhere(executor, table, rule, where, whereArgs)
+		if err != nil {
+			return 0, err
+		}
+		active, activeArgs := softDeleteActiveCondition(childConfig)
+		childWhere += " AND " + active
+		childArgs = append(childArgs, activeArgs...)
+		if _, err := mgr.softDeleteTree(executor, rule.Table, now, childWhere, childArgs, path); err != nil {
+			return 0, err
+		}
+	}
+	return mgr.softDeleteRows(executor, table, now, where, whereArgs...)
+}
+
+// softDeleteActiveCondition 返回选取未删除行的条件及其参数
+func softDeleteActiveCondition(config *SoftDeleteConfig) (string, []interface{}) {
+	if config.Type == SoftDeleteBool {
+		return fmt.Sprintf("%s = ?", config.Field), []interface{}{false}
+	}
+	return fmt.Sprintf("%s IS NULL", config.Field), nil
+}
+
+// cascadeWhere 生成子表条件：foreign_key IN (SELECT parent_key FROM parent WHERE where)，参数沿用父表条件的参数
+func (mgr *dbManager) cascadeWhere(executor sqlExecutor, parent string, rule CascadeRule, where string, whereArgs []interface{}) (string, []interface{}, error) {
+	parentKey := rule.ParentKey
+	if parentKey == "" {
+		pks, err := mgr.getPrimaryKeys(executor, parent)
+		if err != nil {
+			return "", nil, err
+		}
+		if len(pks) != 1 {
+			return "", nil, fmt.Errorf("dbkit: soft delete cascade from table %s requires CascadeRule.ParentKey (table has %d primary key columns)", parent, len(pks))
+		}
+		parentKey = pks[0]
+	}
+
+	sub := fmt.Sprintf("SELECT %s FROM %s", parentKey, parent)
+	if where != "" {
+		sub += " WHERE " + where
+	}
+	args := make([]interface{}, len(whereArgs))
+	copy(args, whereArgs)
+	return fmt.Sprintf("%s IN (%s)", rule.ForeignKey, sub), args, nil
+}
+
+// cascadeTx 级联操作需在同一事务中完成：executor 不在事务中时开启事务，finish 根据错误提交或回滚
+func cascadeTx(executor sqlExecutor) (sqlExecutor, func(error) error, error) {
+	sdb, ok := unwrapExecutor(executor).(*sql.DB)
+	if !ok {
+		return executor, func(err error) error { return err }, nil
+	}
+	tx, err := sdb.Begin()
+	if err != nil {
+		return nil, nil, err
+	}
+	finish := func(err error) error {
+		if err != nil {
+			finishNegativeCacheTx(tx, false)
+			tx.Rollback()
+			return err
+		}
+		err = tx.Commit()
+		finishNegativeCacheTx(tx, err == nil)
+		return err
+	}
+	return rewrapExecutor(executor, tx), finish, nil
+}
+
+// softDeleteRows 软删除本表中匹配的行，时间戳类型写入 now
+func (mgr *dbManager) softDeleteRows(executor sqlExecutor, table string, now time.Time, where string, whereArgs ...interface{}) (int64, error) {
 	config := mgr.getSoftDeleteConfig(table)
 	if config == nil {
 		return 0, fmt.Errorf("soft delete not configured for table %s", table)
@@ -304,7 +484,7 @@ func (mgr *dbManager) softDelete(executor sqlExecutor, table string, where strin
 	switch config.Type {
 	case SoftDeleteTimestamp:
 		setValue = fmt.Sprintf("%s = ?", config.Field)
-		setArgs = append(setArgs, time.Now())
+		setArgs = append(setArgs, now)
 	case SoftDeleteBool:
 		setValue = fmt.Sprintf("%s = ?", config.Field)
 		setArgs = append(setArgs, true)
@@ -319,7 +499,7 @@ func (mgr *dbManager) softDelete(executor sqlExecutor, table string, where strin
 
 	start := time.Now()
 	result, err := executor.Exec(querySQL, allArgs...)
-	mgr.logTrace(start, querySQL, allArgs, err)
+	err = mgr.logTrace(start, querySQL, allArgs, err)
 	if err != nil {
 		return 0, err
 	}
@@ -334,24 +514,81 @@ func (mgr *dbManager) forceDelete(executor sqlExecutor, table string, where stri
 	if where == "" {
 		return 0, fmt.Errorf("where condition is required for delete")
 	}
+	where, whereArgs, err := mgr.scopeTenant(executor, table, where, whereArgs)
+	if err != nil {
+		return 0, err
+	}
 
 	querySQL := fmt.Sprintf("DELETE FROM %s WHERE %s", table, where)
 	querySQL, whereArgs = mgr.prepareQuerySQL(querySQL, whereArgs...)
 
 	start := time.Now()
 	result, err := executor.Exec(querySQL, whereArgs...)
-	mgr.logTrace(start, querySQL, whereArgs, err)
+	err = mgr.logTrace(start, querySQL, whereArgs, err)
 	if err != nil {
 		return 0, err
 	}
 	return result.RowsAffected()
 }
 
-// restore restores soft-deleted records
-func (mgr *dbManager) restore(executor sqlExecutor, table string, where string, whereArgs ...interface{}) (int64, error) {
+// restore restores soft-deleted records，配置了级联时在同一事务中同时恢复子表
+func (mgr *dbManager) restore(executor sqlExecutor, table string, where string, whereArgs ...interface{}) (affected int64, err error) {
 	if err := validateIdentifier(table); err != nil {
 		return 0, err
 	}
+	where, whereArgs, err = mgr.scopeTenant(executor, table, where, whereArgs)
+	if err != nil {
+		return 0, err
+	}
+	if len(mgr.getSoftDeleteCascade(table)) == 0 {
+		return mgr.restoreRows(executor, table, where, whereArgs...)
+	}
+	executor, finish, err := cascadeTx(executor)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { err = finish(err) }()
+	return mgr.restoreTree(executor, table, where, whereArgs, map[string]bool{})
+}
+
+// restoreTree 先按级联规则递归恢复子表中随匹配行一起删除的行，再恢复本表；
+// 子表行的删除时间须与其父行相同，父行删除前已单独删除的子行保持删除状态
+func (mgr *dbManager) restoreTree(executor sqlExecutor, table string, where string, whereArgs []interface{}, path map[string]bool) (int64, error) {
+	path[strings.ToLower(table)] = true
+	defer delete(path, strings.ToLower(table))
+
+	config := mgr.getSoftDeleteConfig(table)
+	for _, rule := range mgr.getSoftDeleteCascade(table) {
+		if path[strings.ToLower(rule.Table)] {
+			continue
+		}
+		childConfig := mgr.getSoftDeleteConfig(rule.Table)
+		if childConfig == nil {
+			return 0, fmt.Errorf("dbkit: soft delete not configured for cascade child table %s", rule.Table)
+		}
+		if config == nil || config.Type != SoftDeleteTimestamp || childConfig.Type != SoftDeleteTimestamp {
+			return 0, fmt.Errorf("dbkit: cascade restore from table %s to %s requires timestamp soft delete, boolean soft delete cannot tell cascaded rows from independently deleted ones", table, rule.Table)
+		}
+		// 子查询中比较父行与子行的删除时间，子行引用的是其外层 UPDATE 的表
+		sameStamp := fmt.Sprintf("%s.%s = %s.%s", table, config.Field, rule.Table, childConfig.Field)
+		parentWhere := sameStamp
+		if where != "" {
+			parentWhere = "(" + where + ") AND " + sameStamp
+		}
+		childWhere, childArgs, err := mgr.cascadeWhere(executor, table, rule, parentWhere, whereArgs)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := mgr.restoreTree(executor, rule.Table, childWhere, childArgs, path); err != nil {
+			return 0, err
+		}
+	}
+	return mgr.restoreRows(executor, table, where, whereArgs...)
+}
+
+// restoreRows 恢复本表中匹配的行
+func (mgr *dbManager) restoreRows(executor sqlExecutor, table string, where string, whereArgs ...interface{}) (int64, error) {
+	defer mgr.invalidateNegativeCacheOn(executor, table)
 
 	config := mgr.getSoftDeleteConfig(table)
 	if config == nil {
@@ -381,7 +618,7 @@ func (mgr *dbManager) restore(executor sqlExecutor, table string, where string,
 
 	start := time.Now()
 	result, err := executor.Exec(querySQL, allArgs...)
-	mgr.logTrace(start, querySQL, allArgs, err)
+	err = mgr.logTrace(start, querySQL, allArgs, err)
 	if err != nil {
 		return 0, err
 	}
@@ -2,11 +2,14 @@ package dbkit
 
 import (
 	"database/sql"
+	"database/sql/driver"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -71,11 +74,13 @@ type SqlTemplateBuilder struct {
 	cacheTTL            time.Duration // 缓存过期时间
 	cacheProvider       CacheProvider // 指定的缓存提供者（nil 表示使用默认缓存）
 	countCacheTTL       time.Duration // 分页计数缓存时间
+
+	transforms []func([]*Record) ([]*Record, error) // 结果后处理函数，按添加顺序执行
 }
 
 // SqlTemplateEngine handles SQL template processing and parameter substitution
 type SqlTemplateEngine struct {
-	namedParamPattern *regexp.Regexp // 匹配 :paramName 格式的参数
+	namedParamPattern *regexp.Regexp // 匹配 :paramName 与 :address.city 格式的参数
 }
 
 // TemplateContext holds the context for SQL template processing
@@ -147,7 +152,7 @@ func getGlobalConfigManager() *SqlConfigManager {
 func getGlobalTemplateEngine() *SqlTemplateEngine {
 	templateEngineOnce.Do(func() {
 		globalTemplateEngine = &SqlTemplateEngine{
-			namedParamPattern: regexp.MustCompile(`:(\w+)`),
+			namedParamPattern: regexp.MustCompile(`:(\w+(?:\.\w+)*)`),
 		}
 	})
 	return globalTemplateEngine
@@ -165,7 +170,7 @@ func NewSqlConfigManager() *SqlConfigManager {
 // NewSqlTemplateEngine creates a new SQL template engine
 func NewSqlTemplateEngine() *SqlTemplateEngine {
 	return &SqlTemplateEngine{
-		namedParamPattern: regexp.MustCompile(`:(\w+)`),
+		namedParamPattern: regexp.MustCompile(`:(\w+(?:\.\w+)*)`),
 	}
 }
 
@@ -249,7 +254,9 @@ func (mgr *SqlConfigManager) LoadConfig(configPath string) (*SqlConfig, error) {
 }
 
 // processSqlItems processes and validates SQL items in a configuration
+// 先校验全部条目再写入索引，出现重复名称时整个文件都不会被注册
 func (mgr *SqlConfigManager) processSqlItems(config *SqlConfig) error {
+	seen := make(map[string]bool, len(config.Sqls))
 	for i := range config.Sqls {
 		item := &config.Sqls[i]
 
@@ -270,22 +277,41 @@ func (mgr *SqlConfigManager) processSqlItems(config *SqlConfig) error {
 		}
 
 		// Check for duplicate SQL identifiers
+		previous := ""
 		if existingItem, exists := mgr.sqlItems[item.FullName]; exists {
+			previous = existingItem.FilePath
+		} else if seen[item.FullName] {
+			previous = config.FilePath
+		}
+		if previous != "" {
 			return &SqlConfigError{
 				Type: "DuplicateError",
 				Message: fmt.Sprintf("duplicate SQL identifier '%s' found in %s (previously defined in %s)",
-					item.FullName, config.FilePath, existingItem.FilePath),
+					item.FullName, config.FilePath, previous),
 				SqlName: item.FullName,
 			}
 		}
+		seen[item.FullName] = true
+	}
+
+	for i := range config.Sqls {
+		item := &config.Sqls[i]
 
 		// Store in global index
 		mgr.sqlItems[item.FullName] = item
 
 		// Also store with simple name if no namespace conflict
-		if namespace != "" {
-			if _, exists := mgr.sqlItems[item.Name]; !exists {
+		if item.FullName != item.Name {
+			if existingItem, exists := mgr.sqlItems[item.Name]; !exists {
 				mgr.sqlItems[item.Name] = item
+			} else {
+				LogWarn("SQL template short name is ambiguous, use the full name", map[string]interface{}{
+					"name":       item.Name,
+					"fullName":   item.FullName,
+					"configPath": config.FilePath,
+					"resolvesTo": existingItem.FullName,
+					"definedIn":  existingItem.FilePath,
+				})
 			}
 		}
 	}
@@ -321,6 +347,32 @@ func (mgr *SqlConfigManager) ListSqlItems() map[string]*SqlItem {
 	return result
 }
 
+// ListSqlTemplates returns the full names (namespace.name) of all registered templates, sorted
+func (mgr *SqlConfigManager) ListSqlTemplates() []string {
+	mgr.mu.RLock()
+	defer mgr.mu.RUnlock()
+
+	var names []string
+	for name, item := range mgr.sqlItems {
+		if name == item.FullName {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetSqlTemplate returns the raw SQL of a template by full name or unambiguous short name
+func (mgr *SqlConfigManager) GetSqlTemplate(name string) (string, bool) {
+	mgr.mu.RLock()
+	defer mgr.mu.RUnlock()
+
+	if item, exists := mgr.sqlItems[name]; exists {
+		return item.SQL, true
+	}
+	return "", false
+}
+
 // GetConfigInfo returns information about all loaded configurations
 func (mgr *SqlConfigManager) GetConfigInfo() []ConfigInfo {
 	mgr.mu.RLock()
@@ -377,9 +429,10 @@ func (mgr *SqlConfigManager) ReloadConfig(configPath string) error {
 	// Remove existing configuration
 	if config, exists := mgr.configs[configPath]; exists {
 		// Remove SQL items from global index
-		for _, item := range config.Sqls {
+		for i := range config.Sqls {
+			item := &config.Sqls[i]
 			delete(mgr.sqlItems, item.FullName)
-			if item.Namespace != "" {
+			if alias, ok := mgr.sqlItems[item.Name]; ok && alias == item {
 				delete(mgr.sqlItems, item.Name)
 			}
 		}
@@ -450,9 +503,20 @@ func ListSqlItems() map[string]*SqlItem {
 	return getGlobalConfigManager().ListSqlItems()
 }
 
+// ListSqlTemplates returns the full names of all registered templates globally, for debugging
+func ListSqlTemplates() []string {
+	return getGlobalConfigManager().ListSqlTemplates()
+}
+
+// GetSqlTemplate returns the raw SQL of a registered template globally
+func GetSqlTemplate(name string) (string, bool) {
+	return getGlobalConfigManager().GetSqlTemplate(name)
+}
+
 // SqlTemplate creates a new SQL template builder for executing configured SQL statements
 // 支持多种参数格式:
 // - SqlTemplate(name, map[string]interface{}{...}) - 命名参数
+// - SqlTemplate(name, structOrPtr) - 命名参数，按 column/db/json 标签取值，嵌套结构体或 map 用 :address.city 引用
 // - SqlTemplate(name, []interface{}{...}) - 位置参数数组
 // - SqlTemplate(name, singleValue) - 单个简单参数
 // - SqlTemplate(name, param1, param2, ...) - 可变参数
@@ -479,6 +543,7 @@ func SqlTemplate(name string, params ...interface{}) *SqlTemplateBuilder {
 // SqlTemplate method for DB to support multi-database execution
 // 支持多种参数格式:
 // - db.SqlTemplate(name, map[string]interface{}{...}) - 命名参数
+// - db.SqlTemplate(name, structOrPtr) - 结构体命名参数，嵌套字段用 :address.city 引用
 // - db.SqlTemplate(name, []interface{}{...}) - 位置参数数组
 // - db.SqlTemplate(name, singleValue) - 单个简单参数
 // - db.SqlTemplate(name, param1, param2, ...) - 可变参数
@@ -511,6 +576,7 @@ func (db *DB) SqlTemplate(name string, params ...interface{}) *SqlTemplateBuilde
 // SqlTemplate method for Tx to support transaction execution
 // 支持多种参数格式:
 // - tx.SqlTemplate(name, map[string]interface{}{...}) - 命名参数
+// - tx.SqlTemplate(name, structOrPtr) - 结构体命名参数，嵌套字段用 :address.city 引用
 // - tx.SqlTemplate(name, []interface{}{...}) - 位置参数数组
 // - tx.SqlTemplate(name, singleValue) - 单个简单参数
 // - tx.SqlTemplate(name, param1, param2, ...) - 可变参数
@@ -624,8 +690,75 @@ func (b *SqlTemplateBuilder) getDbName() string {
 	return ""
 }
 
+// Transform adds a post-processing step applied to the query results before they are returned.
+// 可添加多个，按添加顺序执行；对 Query、QueryFirst、Paginate（作用于当前页的 List）生效，Exec 不受影响。
+// fn 收到的是结果的副本，修改记录不会影响缓存中的数据
+func (b *SqlTemplateBuilder) Transform(fn func([]*Record) ([]*Record, error)) *SqlTemplateBuilder {
+	if fn != nil {
+		b.transforms = append(b.transforms, fn)
+	}
+	return b
+}
+
+// Map adds a post-processing step called for each result record, e.g. to compute or mask fields
+func (b *SqlTemplateBuilder) Map(fn func(*Record) error) *SqlTemplateBuilder {
+	if fn == nil {
+		return b
+	}
+	return b.Transform(func(records []*Record) ([]*Record, error) {
+		for _, record := range records {
+			if err := fn(record); err != nil {
+				return nil, err
+			}
+		}
+		return records, nil
+	})
+}
+
+// applyTransforms 复制结果后依次执行 Transform/Map 添加的处理函数
+func (b *SqlTemplateBuilder) applyTransforms(records []*Record) ([]*Record, error) {
+	list := make([]*Record, len(records))
+	for i, record := range records {
+		list[i] = record.Clone()
+	}
+	for _, fn := range b.transforms {
+		var err error
+		if list, err = fn(list); err != nil {
+			return nil, fmt.Errorf("dbkit: transform of SQL template %s failed: %w", b.sqlName, err)
+		}
+	}
+	return list, nil
+}
+
+// transformRecords 对 []Record 结果执行处理函数，返回的切片中不包含被处理函数置为 nil 的记录
+func (b *SqlTemplateBuilder) transformRecords(records []Record) ([]Record, error) {
+	ptrs := make([]*Record, len(records))
+	for i := range records {
+		ptrs[i] = &records[i]
+	}
+	list, err := b.applyTransforms(ptrs)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]Record, 0, len(list))
+	for _, record := range list {
+		if record != nil {
+			results = append(results, *record.Clone())
+		}
+	}
+	return results, nil
+}
+
 // Query executes the SQL template and returns multiple records
 func (b *SqlTemplateBuilder) Query() ([]Record, error) {
+	results, err := b.query()
+	if err != nil || len(b.transforms) == 0 {
+		return results, err
+	}
+	return b.transformRecords(results)
+}
+
+func (b *SqlTemplateBuilder) query() ([]Record, error) {
 	finalSQL, args, err := b.buildFinalSQL()
 	if err != nil {
 		return nil, err
@@ -709,6 +842,21 @@ func (b *SqlTemplateBuilder) Query() ([]Record, error) {
 
 // Paginate executes the SQL template and return page Object
 func (b *SqlTemplateBuilder) Paginate(page int, pageSize int) (*Page[Record], error) {
+	pageObj, err := b.paginate(page, pageSize)
+	if err != nil || pageObj == nil || len(b.transforms) == 0 {
+		return pageObj, err
+	}
+	list, err := b.transformRecords(pageObj.List)
+	if err != nil {
+		return nil, err
+	}
+	// 返回新的 Page，避免修改缓存中的分页对象
+	result := *pageObj
+	result.List = list
+	return &result, nil
+}
+
+func (b *SqlTemplateBuilder) paginate(page int, pageSize int) (*Page[Record], error) {
 	finalSQL, args, err := b.buildFinalSQL()
 	if err != nil {
 		return nil, err
@@ -830,7 +978,20 @@ func (b *SqlTemplateBuilder) Paginate(page int, pageSize int) (*Page[Record], er
 }
 
 // QueryFirst executes the SQL template and returns a single record
+// 没有匹配的记录时默认返回 (nil, nil)，调用 SetQueryFirstNotFoundError(true) 后返回 (nil, ErrNoRows)
 func (b *SqlTemplateBuilder) QueryFirst() (*Record, error) {
+	record, err := b.queryFirst()
+	if err != nil || record == nil || len(b.transforms) == 0 {
+		return queryFirstResult(record, err)
+	}
+	list, err := b.applyTransforms([]*Record{record})
+	if err != nil || len(list) == 0 {
+		return queryFirstResult(nil, err)
+	}
+	return list[0], nil
+}
+
+func (b *SqlTemplateBuilder) queryFirst() (*Record, error) {
 	finalSQL, args, err := b.buildFinalSQL()
 	if err != nil {
 		return nil, err
@@ -912,6 +1073,29 @@ func (b *SqlTemplateBuilder) QueryFirst() (*Record, error) {
 	}
 }
 
+// QueryTo executes the SQL template and scans the results into dest, a pointer to a slice of structs or struct pointers.
+// 列与字段的映射规则与 ToStructs 相同，Transform/Map 在转换前执行
+func (b *SqlTemplateBuilder) QueryTo(dest interface{}) error {
+	records, err := b.Query()
+	if err != nil {
+		return err
+	}
+	return ToStructs(records, dest)
+}
+
+// QueryFirstTo executes the SQL template and scans the first row into the struct pointer dest.
+// 没有匹配的记录时返回 ErrRecordNotFound
+func (b *SqlTemplateBuilder) QueryFirstTo(dest interface{}) error {
+	record, err := b.QueryFirst()
+	if err != nil {
+		return err
+	}
+	if record == nil {
+		return ErrRecordNotFound
+	}
+	return ToStruct(record, dest)
+}
+
 // Exec executes the SQL template and returns the result
 func (b *SqlTemplateBuilder) Exec() (sql.Result, error) {
 	finalSQL, args, err := b.buildFinalSQL()
@@ -969,6 +1153,9 @@ func (b *SqlTemplateBuilder) buildFinalSQL() (string, []interface{}, error) {
 
 // ProcessTemplate processes a SQL template with parameters
 func (engine *SqlTemplateEngine) ProcessTemplate(sqlItem *SqlItem, params interface{}) (string, []interface{}, error) {
+	// 结构体与嵌套 map 展开为带点路径的命名参数
+	params = flattenTemplateParams(params)
+
 	// First, validate parameter type against SQL format
 	if err := engine.validateParameterTypeMatch(sqlItem.SQL, params); err != nil {
 		// Log parameter validation error
@@ -1079,6 +1266,149 @@ func (engine *SqlTemplateEngine) normalizeParameters(params interface{}) (map[st
 	}
 }
 
+// flattenTemplateParams 将结构体（或其指针）转换为命名参数 map，并把嵌套的结构体与 map 展开为
+// "address.city" 形式的键；其他类型原样返回。传入的 map 不会被修改
+func flattenTemplateParams(params interface{}) interface{} {
+	if params == nil {
+		return nil
+	}
+	if m, ok := params.(map[string]interface{}); ok {
+		nested := false
+		for _, v := range m {
+			if isNestedParam(reflect.ValueOf(v)) {
+				nested = true
+				break
+			}
+		}
+		if !nested {
+			return m
+		}
+		result := make(map[string]interface{}, len(m))
+		for k, v := range m {
+			result[k] = v
+			flattenParamValue(k, reflect.ValueOf(v), result)
+		}
+		return result
+	}
+
+	v := reflect.ValueOf(params)
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct || !isNestedParam(v) {
+		return params
+	}
+	result := make(map[string]interface{})
+	flattenParamValue("", v, result)
+	return result
+}
+
+// isNestedParam 判断值是否需要展开：结构体（time.Time 与 driver.Valuer 除外）或以字符串为键的 map
+func isNestedParam(v reflect.Value) bool {
+	for v.IsValid() && v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return false
+		}
+		if _, ok := v.Interface().(driver.Valuer); ok {
+			return false
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return false
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		if v.Type() == reflect.TypeOf(time.Time{}) {
+			return false
+		}
+		if _, ok := v.Interface().(driver.Valuer); ok {
+			return false
+		}
+		if v.CanAddr() {
+			if _, ok := v.Addr().Interface().(driver.Valuer); ok {
+				return false
+			}
+		}
+		return true
+	case reflect.Map:
+		return v.Type().Key().Kind() == reflect.String
+	}
+	return false
+}
+
+// flattenParamValue 把结构体字段或 map 元素以 prefix.name 为键写入 result，叶子值原样保存
+func flattenParamValue(prefix string, v reflect.Value, result map[string]interface{}) {
+	if !isNestedParam(v) {
+		return
+	}
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	join := func(name string) string {
+		if prefix == "" {
+			return name
+		}
+		return prefix + "." + name
+	}
+
+	if v.Kind() == reflect.Map {
+		for _, key := range v.MapKeys() {
+			name := join(key.String())
+			item := v.MapIndex(key)
+			if item.Kind() == reflect.Interface {
+				item = item.Elem()
+			}
+			setFlattenedParam(name, item, result)
+		}
+		return
+	}
+
+	for _, field := range getStructCacheInfo(v.Type()).fields {
+		if !field.canSet {
+			continue
+		}
+		setFlattenedParam(join(field.columnName), v.Field(field.fieldIndex), result)
+	}
+}
+
+// setFlattenedParam 保存叶子值（nil 指针为 nil），嵌套值继续展开
+func setFlattenedParam(name string, v reflect.Value, result map[string]interface{}) {
+	if isNestedParam(v) {
+		flattenParamValue(name, v, result)
+		return
+	}
+	if !v.IsValid() || (v.Kind() == reflect.Ptr && v.IsNil()) {
+		result[name] = nil
+		if v.IsValid() {
+			flattenNilStruct(name, v.Type().Elem(), result, map[reflect.Type]bool{})
+		}
+		return
+	}
+	result[name] = v.Interface()
+}
+
+// flattenNilStruct 为 nil 结构体指针的所有字段路径写入 nil，使 :address.city 绑定为 NULL 而不是报缺少参数；
+// seen 记录当前路径上的类型，避免自引用结构体无限展开
+func flattenNilStruct(prefix string, t reflect.Type, result map[string]interface{}, seen map[reflect.Type]bool) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || seen[t] || !isNestedParam(reflect.New(t).Elem()) {
+		return
+	}
+	seen[t] = true
+	defer delete(seen, t)
+	for _, field := range getStructCacheInfo(t).fields {
+		if !field.canSet {
+			continue
+		}
+		name := prefix + "." + field.columnName
+		result[name] = nil
+		flattenNilStruct(name, t.Field(field.fieldIndex).Type, result, seen)
+	}
+}
+
 // isSingleSimpleParameter checks if the parameter is a single simple type
 func (engine *SqlTemplateEngine) isSingleSimpleParameter(param interface{}) bool {
 	if param == nil {
@@ -0,0 +1,134 @@
+package dbkit
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// debugExecutor 包装 *sql.DB / *sql.Tx，记录经它执行的每条 SQL（最终 SQL、参数与耗时），
+// 用于 QueryBuilder.Debug：只影响该查询链，与全局 SetDebugMode 无关
+type debugExecutor struct {
+	sqlExecutor
+	mgr *dbManager
+}
+
+// executor 返回 DB 执行 SQL 使用的 executor，Debug 查询链返回带日志的包装，启用租户隔离时携带上下文中的租户
+func (db *DB) executor(sdb *sql.DB) sqlExecutor {
+	var executor sqlExecutor = sdb
+	if db.debug {
+		executor = &debugExecutor{sqlExecutor: sdb, mgr: db.dbMgr}
+	}
+	return wrapMiddleware(db.ctx, db.dbMgr.wrapTenant(db.ctx, db.skipTenant, executor))
+}
+
+// executor 返回事务执行 SQL 使用的 executor，Debug 查询链返回带日志的包装，启用租户隔离时携带事务上下文中的租户
+func (tx *Tx) executor() sqlExecutor {
+	var executor sqlExecutor = tx.tx
+	if tx.debug {
+		executor = &debugExecutor{sqlExecutor: tx.tx, mgr: tx.dbMgr}
+	}
+	return wrapMiddleware(tx.ctx, tx.dbMgr.wrapTenant(tx.ctx, tx.skipTenant, executor))
+}
+
+// unwrapExecutor 返回被包装的原始 executor，用于需要判断 *sql.DB / *sql.Tx 的场景
+func unwrapExecutor(executor sqlExecutor) sqlExecutor {
+	if m, ok := executor.(*middlewareExecutor); ok {
+		executor = m.sqlExecutor
+	}
+	if t, ok := executor.(*tenantExecutor); ok {
+		executor = t.sqlExecutor
+	}
+	if d, ok := executor.(*debugExecutor); ok {
+		return d.sqlExecutor
+	}
+	return executor
+}
+
+// rewrapExecutor 用 inner（如内部开启的事务）替换 executor，保留中间件、租户与 Debug 包装
+func rewrapExecutor(executor sqlExecutor, inner sqlExecutor) sqlExecutor {
+	if m, ok := executor.(*middlewareExecutor); ok {
+		return &middlewareExecutor{sqlExecutor: rewrapExecutor(m.sqlExecutor, inner), chain: m.chain, ctx: m.ctx}
+	}
+	if t, ok := executor.(*tenantExecutor); ok {
+		return &tenantExecutor{sqlExecutor: rewrapExecutor(t.sqlExecutor, inner), tenant: t.tenant, hasTenant: t.hasTenant}
+	}
+	if d, ok := executor.(*debugExecutor); ok {
+		return &debugExecutor{sqlExecutor: inner, mgr: d.mgr}
+	}
+	return inner
+}
+
+func (e *debugExecutor) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	start := time.Now()
+	rows, err := e.sqlExecutor.Query(query, args...)
+	e.log(start, query, args, err)
+	return rows, err
+}
+
+func (e *debugExecutor) Exec(query string, args ...interface{}) (sql.Result, error) {
+	start := time.Now()
+	result, err := e.sqlExecutor.Exec(query, args...)
+	e.log(start, query, args, err)
+	return result, err
+}
+
+func (e *debugExecutor) QueryRow(query string, args ...interface{}) *sql.Row {
+	start := time.Now()
+	row := e.sqlExecutor.QueryRow(query, args...)
+	e.log(start, query, args, row.Err())
+	return row
+}
+
+func (e *debugExecutor) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	execCtx, ok := e.sqlExecutor.(sqlExecutorContext)
+	if !ok {
+		return e.Query(query, args...)
+	}
+	start := time.Now()
+	rows, err := execCtx.QueryContext(ctx, query, args...)
+	e.log(start, query, args, err)
+	return rows, err
+}
+
+func (e *debugExecutor) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	execCtx, ok := e.sqlExecutor.(sqlExecutorContext)
+	if !ok {
+		return e.Exec(query, args...)
+	}
+	start := time.Now()
+	result, err := execCtx.ExecContext(ctx, query, args...)
+	e.log(start, query, args, err)
+	return result, err
+}
+
+func (e *debugExecutor) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	execCtx, ok := e.sqlExecutor.(sqlExecutorContext)
+	if !ok {
+		return e.QueryRow(query, args...)
+	}
+	start := time.Now()
+	row := execCtx.QueryRowContext(ctx, query, args...)
+	e.log(start, query, args, row.Err())
+	return row
+}
+
+// splitDebugExecutor 去掉租户与 Debug 包装，使 *sql.DB 照常走连接获取超时与预编译语句缓存；
+// 第二个返回值为原来的 Debug 包装（非 Debug 查询链为 nil），调用方执行后用它的 log 输出日志
+func splitDebugExecutor(executor sqlExecutor) (sqlExecutor, *debugExecutor) {
+	if t, ok := executor.(*tenantExecutor); ok {
+		executor = t.sqlExecutor
+	}
+	if d, ok := executor.(*debugExecutor); ok {
+		return d.sqlExecutor, d
+	}
+	return executor, nil
+}
+
+// log 输出一条 SQL 日志；e 为 nil 或全局调试模式已开启（logTrace 会记录同一条 SQL）时不输出
+func (e *debugExecutor) log(start time.Time, query string, args []interface{}, err error) {
+	if e == nil || IsDebugEnabled() {
+		return
+	}
+	logDebugSQL(e.mgr.name, query, e.mgr.sanitizeArgs(query, args), time.Since(start), err)
+}
@@ -1,6 +1,7 @@
 package dbkit
 
 import (
+	"errors"
 	"fmt"
 	"regexp"
 	"strings"
@@ -387,3 +388,101 @@ func (v *SQLSecurityValidator) IsAllowedFunction(function string) bool {
 	}
 	return false
 }
+
+// ErrColumnNotAllowed 列名不在白名单中
+var ErrColumnNotAllowed = errors.New("dbkit: column not allowed")
+
+// SortWhitelist 排序/过滤列白名单
+// 用于 API 传入的排序字段、过滤字段，只有白名单中的列才会拼接到 SQL 中
+type SortWhitelist struct {
+	columns map[string]string // 小写列名 -> 白名单中的原始列名
+}
+
+// NewSortWhitelist 创建列白名单（大小写不敏感）
+func NewSortWhitelist(allowed ...string) *SortWhitelist {
+	w := &SortWhitelist{columns: make(map[string]string, len(allowed))}
+	return w.Allow(allowed...)
+}
+
+// Allow 向白名单追加列
+func (w *SortWhitelist) Allow(columns ...string) *SortWhitelist {
+	for _, col := range columns {
+		col = strings.TrimSpace(col)
+		if col != "" {
+			w.columns[strings.ToLower(col)] = col
+		}
+	}
+	return w
+}
+
+// Contains 检查列是否在白名单中
+func (w *SortWhitelist) Contains(column string) bool {
+	_, ok := w.resolve(column)
+	return ok
+}
+
+// resolve 返回白名单中登记的列名，SQL 中只使用该值而不使用用户输入
+func (w *SortWhitelist) resolve(column string) (string, bool) {
+	if w == nil {
+		return "", false
+	}
+	col, ok := w.columns[strings.ToLower(strings.TrimSpace(column))]
+	return col, ok
+}
+
+// safeWhereOperators SafeWhere 允许的比较运算符
+var safeWhereOperators = map[string]bool{
+	"=": true, "!=": true, "<>": true, ">": true, ">=": true, "<": true, "<=": true,
+	"LIKE": true, "NOT LIKE": true,
+}
+
+// SafeOrderBy 解析用户输入的排序（如 "age desc, name"），仅当所有列都在白名单中时才应用
+// 列不在白名单或方向不是 ASC/DESC 时返回错误（通过 lastErr 在执行时返回）
+func (qb *QueryBuilder) SafeOrderBy(userInput string, whitelist *SortWhitelist) *QueryBuilder {
+	if qb.lastErr != nil {
+		return qb
+	}
+	if strings.TrimSpace(userInput) == "" {
+		return qb
+	}
+	for _, item := range strings.Split(userInput, ",") {
+		parts := strings.Fields(item)
+		if len(parts) == 0 || len(parts) > 2 {
+			qb.lastErr = fmt.Errorf("%w: invalid order by item '%s'", ErrColumnNotAllowed, strings.TrimSpace(item))
+			return qb
+		}
+		col, ok := whitelist.resolve(parts[0])
+		if !ok {
+			qb.lastErr = fmt.Errorf("%w: '%s' is not sortable", ErrColumnNotAllowed, parts[0])
+			return qb
+		}
+		dir := Asc
+		if len(parts) == 2 {
+			dir = OrderDir(parts[1])
+		}
+		qb.OrderByColumn(col, dir)
+		if qb.lastErr != nil {
+			return qb
+		}
+	}
+	return qb
+}
+
+// SafeWhere adds "column operator ?" only when the column is in the whitelist
+// operator 支持 =, !=, <>, >, >=, <, <=, LIKE, NOT LIKE
+func (qb *QueryBuilder) SafeWhere(column, operator string, value interface{}, whitelist *SortWhitelist) *QueryBuilder {
+	if qb.lastErr != nil {
+		return qb
+	}
+	col, ok := whitelist.resolve(column)
+	if !ok {
+		qb.lastErr = fmt.Errorf("%w: '%s' is not filterable", ErrColumnNotAllowed, column)
+		return qb
+	}
+	op := strings.ToUpper(strings.Join(strings.Fields(operator), " "))
+	if !safeWhereOperators[op] {
+		qb.lastErr = fmt.Errorf("dbkit: unsupported operator '%s'", operator)
+		return qb
+	}
+	return qb.Where(col+" "+op+" ?", value)
+}
@@ -0,0 +1,89 @@
+package dbkit
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SQLiteOptions holds per-connection SQLite settings applied via PRAGMA
+type SQLiteOptions struct {
+	JournalMode string        // 日志模式：DELETE / TRUNCATE / PERSIST / MEMORY / WAL / OFF，推荐 WAL 以提升并发写入
+	Synchronous string        // 同步级别：OFF / NORMAL / FULL / EXTRA（WAL 模式下通常使用 NORMAL）
+	BusyTimeout time.Duration // 遇到锁时的等待时间，避免立即返回 "database is locked"
+	ForeignKeys bool          // 开启外键约束（SQLite 默认关闭）
+}
+
+// pragmas 校验选项并生成对应的 PRAGMA 语句
+func (o SQLiteOptions) pragmas() ([]string, error) {
+	var pragmas []string
+	if o.JournalMode != "" {
+		mode := strings.ToUpper(o.JournalMode)
+		switch mode {
+		case "DELETE", "TRUNCATE", "PERSIST", "MEMORY", "WAL", "OFF":
+		default:
+			return nil, fmt.Errorf("dbkit: invalid SQLite journal mode '%s'", o.JournalMode)
+		}
+		pragmas = append(pragmas, "PRAGMA journal_mode = "+mode)
+	}
+	if o.Synchronous != "" {
+		level := strings.ToUpper(o.Synchronous)
+		switch level {
+		case "OFF", "NORMAL", "FULL", "EXTRA":
+		default:
+			return nil, fmt.Errorf("dbkit: invalid SQLite synchronous level '%s'", o.Synchronous)
+		}
+		pragmas = append(pragmas, "PRAGMA synchronous = "+level)
+	}
+	if o.BusyTimeout < 0 {
+		return nil, fmt.Errorf("dbkit: SQLite busy timeout must not be negative")
+	}
+	if o.BusyTimeout > 0 {
+		pragmas = append(pragmas, fmt.Sprintf("PRAGMA busy_timeout = %d", o.BusyTimeout.Milliseconds()))
+	}
+	if o.ForeignKeys {
+		pragmas = append(pragmas, "PRAGMA foreign_keys = ON")
+	}
+	return pragmas, nil
+}
+
+// ConfigSQLite applies SQLite PRAGMAs to every connection of the named database.
+// busy_timeout、foreign_keys 等 PRAGMA 只对执行它的连接生效，因此这里会重建连接池，
+// 让之后新建的每个连接都先执行这些 PRAGMA。建议在 OpenDatabase 之后、开始使用前立即调用。
+func ConfigSQLite(dbName string, opts SQLiteOptions) error {
+	mgr := GetDatabase(dbName)
+	if mgr == nil {
+		return fmt.Errorf("dbkit: database '%s' not found", dbName)
+	}
+	if mgr.config.Driver != SQLite3 {
+		return fmt.Errorf("dbkit: database '%s' is not SQLite (driver: %s)", dbName, mgr.config.Driver)
+	}
+	pragmas, err := opts.pragmas()
+	if err != nil {
+		return err
+	}
+	return mgr.resetPool(pragmas)
+}
+
+// resetPool 使用新的 PRAGMA 配置重建连接池，旧连接池在替换后关闭
+func (mgr *dbManager) resetPool(pragmas []string) error {
+	mgr.mu.Lock()
+	old := mgr.db
+	prev := mgr.sqlitePragmas
+	mgr.sqlitePragmas = pragmas
+	db, err := mgr.openPool()
+	if err != nil {
+		mgr.sqlitePragmas = prev
+		mgr.mu.Unlock()
+		return err
+	}
+	mgr.db = db
+	mgr.mu.Unlock()
+
+	if old != nil {
+		// 缓存的预编译语句绑定在旧连接池上，需一并清理
+		mgr.clearStmtCache()
+		old.Close()
+	}
+	return nil
+}
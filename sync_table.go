@@ -0,0 +1,229 @@
+package dbkit
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SyncResult reports the changes SyncTable made to the table
+type SyncResult struct {
+	Inserted int64
+	Updated  int64
+	Deleted  int64
+}
+
+// SyncTable makes table match records on the default database, keyed by keyColumns.
+// 包级 Sync 已用于刷新日志，因此命名为 SyncTable
+func SyncTable(table string, records []*Record, keyColumns []string) (SyncResult, error) {
+	db, err := defaultDB()
+	if err != nil {
+		return SyncResult{}, err
+	}
+	return db.SyncTable(table, records, keyColumns)
+}
+
+// SyncTable makes table match records in one transaction, keyed by the natural key keyColumns:
+// rows whose key is missing from the table are inserted, rows whose columns differ are updated,
+// and table rows whose key is not in records are deleted.
+// 只比较、更新 records 中出现的列；已存在的行按 Table(table).Find() 读取，软删除与租户过滤同样生效，
+// 配置了软删除的表删除时为软删除。records 中键列不能缺失或为 NULL，键重复时返回错误
+func (db *DB) SyncTable(table string, records []*Record, keyColumns []string) (SyncResult, error) {
+	if db.lastErr != nil {
+		return SyncResult{}, db.lastErr
+	}
+	var result SyncResult
+	err := db.Transaction(func(tx *Tx) error {
+		var err error
+		result, err = tx.SyncTable(table, records, keyColumns)
+		return err
+	})
+	if err != nil {
+		return SyncResult{}, err
+	}
+	return result, nil
+}
+
+// SyncTable makes table match records within transaction, keyed by keyColumns
+func (tx *Tx) SyncTable(table string, records []*Record, keyColumns []string) (SyncResult, error) {
+	var result SyncResult
+	if err := validateIdentifier(table); err != nil {
+		return result, err
+	}
+	if len(keyColumns) == 0 {
+		return result, fmt.Errorf("dbkit: SyncTable requires at least one key column")
+	}
+	conds := make([]string, len(keyColumns))
+	for i, col := range keyColumns {
+		if err := validateIdentifier(col); err != nil {
+			return result, err
+		}
+		conds[i] = col + " = ?"
+	}
+	keyWhere := strings.Join(conds, " AND ")
+
+	wanted := make(map[string]*Record, len(records))
+	for i, record := range records {
+		if record == nil {
+			return result, fmt.Errorf("dbkit: SyncTable record %d is nil", i)
+		}
+		key, err := syncKey(record, keyColumns)
+		if err != nil {
+			return result, fmt.Errorf("dbkit: SyncTable record %d: %v", i, err)
+		}
+		if _, dup := wanted[key]; dup {
+			return result, fmt.Errorf("dbkit: SyncTable record %d has a duplicate key %v", i, syncKeyArgs(record, keyColumns))
+		}
+		wanted[key] = record
+	}
+
+	existing, err := tx.Table(table).Find()
+	if err != nil {
+		return result, err
+	}
+	seen := make(map[string]bool, len(existing))
+	for i := range existing {
+		row := &existing[i]
+		key, err := syncKey(row, keyColumns)
+		if err != nil {
+			return result, fmt.Errorf("dbkit: SyncTable existing row: %v", err)
+		}
+		seen[key] = true
+		record, ok := wanted[key]
+		if !ok {
+			affected, err := tx.Delete(table, keyWhere, syncKeyArgs(row, keyColumns)...)
+			if err != nil {
+				return result, err
+			}
+			result.Deleted += affected
+			continue
+		}
+
+		changed := NewRecord()
+		for _, col := range record.Keys() {
+			if syncIsKeyColumn(col, keyColumns) {
+				continue
+			}
+			if !syncValueEqual(record.Get(col), row.Get(col)) {
+				changed.Set(col, record.Get(col))
+			}
+		}
+		if len(changed.Keys()) == 0 {
+			continue
+		}
+		affected, err := tx.Update(table, changed, keyWhere, syncKeyArgs(row, keyColumns)...)
+		if err != nil {
+			return result, err
+		}
+		result.Updated += affected
+	}
+
+	var inserts []*Record
+	for _, record := range records {
+		key, _ := syncKey(record, keyColumns)
+		if !seen[key] {
+			inserts = append(inserts, record)
+		}
+	}
+	if len(inserts) > 0 {
+		affected, err := tx.BatchInsertGrouped(table, inserts, DefaultBatchSize)
+		if err != nil {
+			return result, err
+		}
+		result.Inserted = affected
+	}
+	return result, nil
+}
+
+// syncKey 把键列的值拼接为比较用的字符串，数值类型不同（int 与 int64）时结果相同
+func syncKey(record *Record, keyColumns []string) (string, error) {
+	parts := make([]string, len(keyColumns))
+	for i, col := range keyColumns {
+		if !record.Has(col) {
+			return "", fmt.Errorf("key column %s is missing", col)
+		}
+		v := normalizeArg(record.Get(col))
+		switch val := v.(type) {
+		case nil:
+			return "", fmt.Errorf("key column %s is NULL", col)
+		case []byte:
+			parts[i] = string(val)
+		case time.Time:
+			parts[i] = val.UTC().Format(time.RFC3339Nano)
+		default:
+			parts[i] = fmt.Sprint(val)
+		}
+	}
+	return strings.Join(parts, "\x00"), nil
+}
+
+func syncKeyArgs(record *Record, keyColumns []string) []interface{} {
+	args := make([]interface{}, len(keyColumns))
+	for i, col := range keyColumns {
+		args[i] = record.Get(col)
+	}
+	return args
+}
+
+func syncIsKeyColumn(column string, keyColumns []string) bool {
+	for _, col := range keyColumns {
+		if strings.EqualFold(col, column) {
+			return true
+		}
+	}
+	return false
+}
+
+// syncValueEqual 比较 Record 中的值与数据库读出的值，兼容驱动返回的类型差异（int64/float64/[]byte/0、1 表示的布尔值）
+func syncValueEqual(a, b interface{}) bool {
+	a, b = normalizeArg(a), normalizeArg(b)
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	if ab, ok := a.([]byte); ok {
+		a = string(ab)
+	}
+	if bb, ok := b.([]byte); ok {
+		b = string(bb)
+	}
+	if _, ok := a.(time.Time); ok {
+		return syncTimeEqual(a, b)
+	}
+	if _, ok := b.(time.Time); ok {
+		return syncTimeEqual(a, b)
+	}
+	if _, ok := a.(bool); ok {
+		return syncBoolEqual(a, b)
+	}
+	if _, ok := b.(bool); ok {
+		return syncBoolEqual(a, b)
+	}
+	if syncIsNumber(a) || syncIsNumber(b) {
+		af, errA := toFloat64(a)
+		bf, errB := toFloat64(b)
+		if errA == nil && errB == nil {
+			return af == bf
+		}
+	}
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+func syncTimeEqual(a, b interface{}) bool {
+	at, errA := toTime(a)
+	bt, errB := toTime(b)
+	return errA == nil && errB == nil && at.Equal(bt)
+}
+
+func syncBoolEqual(a, b interface{}) bool {
+	ab, errA := toBool(a)
+	bb, errB := toBool(b)
+	return errA == nil && errB == nil && ab == bb
+}
+
+func syncIsNumber(v interface{}) bool {
+	switch v.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return true
+	}
+	return false
+}
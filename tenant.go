@@ -0,0 +1,270 @@
+package dbkit
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrTenantRequired is returned when tenant scope applies to a table but the context carries no tenant
+var ErrTenantRequired = errors.New("dbkit: tenant scope is enabled but no tenant was found in the context")
+
+type tenantContextKey struct{}
+
+// WithTenant returns a context carrying tenantID, pass it to DB.WithContext / QueryBuilder.WithContext
+func WithTenant(ctx context.Context, tenantID interface{}) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, tenantContextKey{}, tenantID)
+}
+
+// TenantFromContext returns the tenant set by WithTenant
+func TenantFromContext(ctx context.Context) (interface{}, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	tenant := ctx.Value(tenantContextKey{})
+	return tenant, tenant != nil
+}
+
+// EnableTenantScope scopes QueryBuilder queries on tables that have the given column to the tenant in the context.
+// 启用后：QueryBuilder 查询与 DB / Tx 的 Update、Delete、BatchUpdate、BatchDelete、Restore 等自动追加 column = 租户值，
+// Insert、Save、InsertIgnore、BatchInsert 系列与 BatchUpsertReturning 自动填充该列；
+// 只作用于包含该列的表（通过表结构判断并缓存），上下文中没有租户时返回 ErrTenantRequired，
+// 管理后台等全局查询使用 IgnoreTenantScope()。column 为空时关闭
+func EnableTenantScope(column string) {
+	db, err := defaultDB()
+	if err != nil {
+		return
+	}
+	db.EnableTenantScope(column)
+}
+
+// EnableTenantScope enables tenant scope for this database
+func (db *DB) EnableTenantScope(column string) *DB {
+	if db.lastErr != nil {
+		return db
+	}
+	if column != "" {
+		if err := validateIdentifier(column); err != nil {
+			db.lastErr = err
+			return db
+		}
+	}
+	db.dbMgr.mu.Lock()
+	defer db.dbMgr.mu.Unlock()
+	db.dbMgr.tenantColumn = column
+	return db
+}
+
+// WithContext sets the context used by this DB instance: statements and transactions derive from it,
+// and the tenant set by WithTenant is read from it
+func (db *DB) WithContext(ctx context.Context) *DB {
+	db.ctx = ctx
+	return db
+}
+
+// WithContext binds ctx to this query chain only, see DB.WithContext
+func (qb *QueryBuilder) WithContext(ctx context.Context) *QueryBuilder {
+	if qb.db != nil {
+		db := *qb.db
+		db.ctx = ctx
+		qb.db = &db
+	}
+	if qb.tx != nil {
+		qb.tx = &Tx{
+			tx:                  qb.tx.tx,
+			dbMgr:               qb.tx.dbMgr,
+			cacheRepositoryName: qb.tx.cacheRepositoryName,
+			cacheTTL:            qb.tx.cacheTTL,
+			timeout:             qb.tx.timeout,
+			cacheProvider:       qb.tx.cacheProvider,
+			countCacheTTL:       qb.tx.countCacheTTL,
+			ctx:                 ctx,
+			debug:               qb.tx.debug,
+			skipTenant:          qb.tx.skipTenant,
+		}
+	}
+	return qb
+}
+
+// IgnoreTenantScope disables tenant scope for this query chain, for admin or cross-tenant queries
+func (qb *QueryBuilder) IgnoreTenantScope() *QueryBuilder {
+	qb.ignoreTenantScope = true
+	qb.skipTenantOnWrite()
+	return qb
+}
+
+// skipTenantOnWrite 换用 skipTenant 的 DB / Tx 副本，builder 已处理租户条件，dbManager 的写入路径不再重复追加
+func (qb *QueryBuilder) skipTenantOnWrite() {
+	if qb.db != nil && !qb.db.skipTenant {
+		db := *qb.db
+		db.skipTenant = true
+		qb.db = &db
+	}
+	if qb.tx != nil && !qb.tx.skipTenant {
+		qb.tx = &Tx{
+			tx:                  qb.tx.tx,
+			dbMgr:               qb.tx.dbMgr,
+			cacheRepositoryName: qb.tx.cacheRepositoryName,
+			cacheTTL:            qb.tx.cacheTTL,
+			timeout:             qb.tx.timeout,
+			cacheProvider:       qb.tx.cacheProvider,
+			countCacheTTL:       qb.tx.countCacheTTL,
+			ctx:                 qb.tx.ctx,
+			debug:               qb.tx.debug,
+			skipTenant:          true,
+		}
+	}
+}
+
+// tenantExecutor 包装 executor，携带 DB / Tx 上下文中的租户，供 dbManager 的写入路径填充租户列、追加租户条件；
+// 只在启用租户隔离时添加，未携带时写入路径不做租户处理
+type tenantExecutor struct {
+	sqlExecutor
+	tenant    interface{}
+	hasTenant bool
+}
+
+// wrapTenant 启用租户隔离且 skip 为 false 时为 executor 加上租户包装
+func (mgr *dbManager) wrapTenant(ctx context.Context, skip bool, executor sqlExecutor) sqlExecutor {
+	if skip || mgr.tenantColumn == "" {
+		return executor
+	}
+	tenant, ok := TenantFromContext(ctx)
+	return &tenantExecutor{sqlExecutor: executor, tenant: tenant, hasTenant: ok}
+}
+
+// tenantOf 返回 executor 携带的租户包装，没有时返回 nil
+func tenantOf(executor sqlExecutor) *tenantExecutor {
+	if m, ok := executor.(*middlewareExecutor); ok {
+		executor = m.sqlExecutor
+	}
+	t, _ := executor.(*tenantExecutor)
+	return t
+}
+
+func (e *tenantExecutor) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	if execCtx, ok := e.sqlExecutor.(sqlExecutorContext); ok {
+		return execCtx.QueryContext(ctx, query, args...)
+	}
+	return e.sqlExecutor.Query(query, args...)
+}
+
+func (e *tenantExecutor) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	if execCtx, ok := e.sqlExecutor.(sqlExecutorContext); ok {
+		return execCtx.ExecContext(ctx, query, args...)
+	}
+	return e.sqlExecutor.Exec(query, args...)
+}
+
+func (e *tenantExecutor) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	if execCtx, ok := e.sqlExecutor.(sqlExecutorContext); ok {
+		return execCtx.QueryRowContext(ctx, query, args...)
+	}
+	return e.sqlExecutor.QueryRow(query, args...)
+}
+
+// tenantColumnOf 返回表的租户列：未启用租户隔离或表中没有该列时返回空
+func (mgr *dbManager) tenantColumnOf(table string) (string, error) {
+	column := mgr.tenantColumn
+	if column == "" || table == "" {
+		return "", nil
+	}
+	columns, err := mgr.joinTableColumns(table)
+	if err != nil {
+		return "", err
+	}
+	for _, col := range columns {
+		if strings.EqualFold(col, column) {
+			return column, nil
+		}
+	}
+	return "", nil
+}
+
+// applyTenant 按 executor 携带的租户为待插入的记录填充租户列；记录中已有不同的租户值时返回错误
+func (mgr *dbManager) applyTenant(executor sqlExecutor, table string, records ...*Record) error {
+	t := tenantOf(executor)
+	if t == nil {
+		return nil
+	}
+	column, err := mgr.tenantColumnOf(table)
+	if err != nil || column == "" {
+		return err
+	}
+	for _, record := range records {
+		if record == nil {
+			continue
+		}
+		if record.Has(column) && record.Get(column) != nil {
+			if t.hasTenant && fmt.Sprint(record.Get(column)) != fmt.Sprint(t.tenant) {
+				return fmt.Errorf("dbkit: %s %v of the record does not match tenant %v in the context", column, record.Get(column), t.tenant)
+			}
+			continue
+		}
+		if !t.hasTenant {
+			return fmt.Errorf("%w (table %s)", ErrTenantRequired, table)
+		}
+		record.Set(column, t.tenant)
+	}
+	return nil
+}
+
+// scopeTenant 按 executor 携带的租户为 UPDATE / DELETE 的条件追加 column = 租户值，原条件整体加括号
+func (mgr *dbManager) scopeTenant(executor sqlExecutor, table, where string, whereArgs []interface{}) (string, []interface{}, error) {
+	t := tenantOf(executor)
+	if t == nil {
+		return where, whereArgs, nil
+	}
+	column, err := mgr.tenantColumnOf(table)
+	if err != nil || column == "" {
+		return where, whereArgs, err
+	}
+	if !t.hasTenant {
+		return "", nil, fmt.Errorf("%w (table %s)", ErrTenantRequired, table)
+	}
+	condition := column + " = ?"
+	if where != "" {
+		condition = "(" + where + ") AND " + condition
+	}
+	return condition, append(append([]interface{}(nil), whereArgs...), t.tenant), nil
+}
+
+// tenantScoped 返回追加了租户条件的副本，存在 OR 条件时先整体加括号；
+// 未启用、表中没有租户列、IgnoreTenantScope 或已追加过时返回 qb 本身
+func (qb *QueryBuilder) tenantScoped() (*QueryBuilder, error) {
+	if qb.ignoreTenantScope || qb.tenantApplied || qb.rawSQL != "" || qb.subqueryTable != nil {
+		return qb, nil
+	}
+	mgr := qb.getDbManager()
+	if mgr == nil {
+		return qb, nil
+	}
+	column, err := mgr.tenantColumnOf(qb.table)
+	if err != nil || column == "" {
+		return qb, err
+	}
+
+	var ctx context.Context
+	if qb.tx != nil {
+		ctx = qb.tx.ctx
+	} else {
+		ctx = qb.db.ctx
+	}
+	tenant, ok := TenantFromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("%w (table %s)", ErrTenantRequired, qb.table)
+	}
+	if len(qb.joins) > 0 {
+		column = qb.table + "." + column
+	}
+
+	scoped := qb.andCondition(column+" = ?", tenant)
+	scoped.tenantApplied = true
+	scoped.skipTenantOnWrite()
+	return scoped, nil
+}
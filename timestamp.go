@@ -114,6 +114,16 @@ func HasTimestamps(table string) bool {
 	return db.HasTimestamps(table)
 }
 
+// ConfigTimestampsUTC makes auto-populated timestamps (created_at, updated_at, soft delete time)
+// on the default database use time.Now().UTC() instead of local time
+func ConfigTimestampsUTC(enabled bool) {
+	db, err := defaultDB()
+	if err != nil {
+		return
+	}
+	db.ConfigTimestampsUTC(enabled)
+}
+
 // --- DB Methods ---
 
 // ConfigTimestamps configures auto timestamps for a table using default field names
@@ -185,6 +195,19 @@ func (db *DB) ConfigUpdatedAt(table, field string) *DB {
 	return db
 }
 
+// ConfigTimestampsUTC makes auto-populated timestamps on this database use UTC.
+// 同时配置了 ConfigTimeZone 时，绑定前仍会转换到该时区（表示同一时刻），
+// 若希望 DATETIME 等不带时区的列中保存 UTC 墙上时间，请同时设置 ConfigTimeZone(time.UTC)
+func (db *DB) ConfigTimestampsUTC(enabled bool) *DB {
+	if db.lastErr != nil || db.dbMgr == nil {
+		return db
+	}
+	db.dbMgr.mu.Lock()
+	defer db.dbMgr.mu.Unlock()
+	db.dbMgr.timestampsUTC = enabled
+	return db
+}
+
 // RemoveTimestamps removes timestamp configuration for a table
 func (db *DB) RemoveTimestamps(table string) *DB {
 	if db.lastErr != nil || db.dbMgr == nil {
@@ -236,6 +259,17 @@ func (mgr *dbManager) hasTimestamps(table string) bool {
 	return mgr.timestamps.has(table)
 }
 
+// timestampNow 返回自动填充时间戳使用的当前时间
+func (mgr *dbManager) timestampNow() time.Time {
+	mgr.mu.RLock()
+	utc := mgr.timestampsUTC
+	mgr.mu.RUnlock()
+	if utc {
+		return time.Now().UTC()
+	}
+	return time.Now()
+}
+
 // applyCreatedAtTimestamp applies created_at timestamp to a record if configured
 func (mgr *dbManager) applyCreatedAtTimestamp(table string, record *Record, skipTimestamps bool) {
 	if skipTimestamps {
@@ -263,7 +297,19 @@ func (mgr *dbManager) applyCreatedAtTimestamp(table string, record *Record, skip
 	}
 
 	if shouldSet {
-		record.Set(config.CreatedAtField, time.Now())
+		record.Set(config.CreatedAtField, mgr.timestampNow())
+	}
+}
+
+// applyCreatedAtTimestamps applies created_at to every record of a batch insert, 与单条 Insert 的规则一致
+func (mgr *dbManager) applyCreatedAtTimestamps(table string, records []*Record) {
+	if mgr.getTimestampConfig(table) == nil {
+		return
+	}
+	for _, record := range records {
+		if record != nil {
+			mgr.applyCreatedAtTimestamp(table, record, false)
+		}
 	}
 }
 
@@ -277,5 +323,5 @@ func (mgr *dbManager) applyUpdatedAtTimestamp(table string, record *Record, skip
 		return
 	}
 	// Always update the updated_at field
-	record.Set(config.UpdatedAtField, time.Now())
+	record.Set(config.UpdatedAtField, mgr.timestampNow())
 }
@@ -0,0 +1,75 @@
+package dbkit
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ConfigTimeZone configures the time zone used by the named database.
+// time.Time 参数在绑定前转换到该时区，查询返回的时间值也按该时区解释，
+// 避免应用服务器与数据库所在时区不同导致的时间偏移。传入 nil 取消配置。
+func ConfigTimeZone(dbName string, loc *time.Location) error {
+	mgr := GetDatabase(dbName)
+	if mgr == nil {
+		return fmt.Errorf("dbkit: database '%s' not found", dbName)
+	}
+	mgr.setTimeLocation(loc)
+	return nil
+}
+
+// ConfigTimeZone configures the time zone for this database instance.
+func (db *DB) ConfigTimeZone(loc *time.Location) *DB {
+	if db.lastErr != nil {
+		return db
+	}
+	db.dbMgr.setTimeLocation(loc)
+	return db
+}
+
+func (mgr *dbManager) setTimeLocation(loc *time.Location) {
+	mgr.mu.Lock()
+	defer mgr.mu.Unlock()
+	mgr.timeLocation = loc
+}
+
+func (mgr *dbManager) getTimeLocation() *time.Location {
+	mgr.mu.RLock()
+	defer mgr.mu.RUnlock()
+	return mgr.timeLocation
+}
+
+// localizeArg 将 time.Time 参数转换到配置的时区，其他类型原样返回
+func (mgr *dbManager) localizeArg(arg interface{}) interface{} {
+	t, ok := arg.(time.Time)
+	if !ok {
+		return arg
+	}
+	if loc := mgr.getTimeLocation(); loc != nil {
+		return t.In(loc)
+	}
+	return t
+}
+
+// localizeScannedTime 按配置的时区解释数据库返回的时间值
+// 带时区的类型（TIMESTAMPTZ、DATETIMEOFFSET 等）表示绝对时间，只转换显示时区；
+// 不带时区的类型（DATETIME、TIMESTAMP）保存的是墙上时间，驱动通常按 UTC 返回，这里按配置时区重新解释
+func localizeScannedTime(val interface{}, dbType string, loc *time.Location) interface{} {
+	if loc == nil {
+		return val
+	}
+	t, ok := val.(time.Time)
+	if !ok {
+		return val
+	}
+	if isZonedTimeType(dbType) {
+		return t.In(loc)
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+}
+
+func isZonedTimeType(dbType string) bool {
+	return strings.Contains(dbType, "TIMESTAMPTZ") ||
+		strings.Contains(dbType, "TIME ZONE") ||
+		strings.Contains(dbType, "DATETIMEOFFSET")
+}
@@ -0,0 +1,174 @@
+package dbkit
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// topNRankColumn TopNPerGroup 在结果中追加的组内排名列
+const topNRankColumn = "group_rank"
+
+var topNOrderTermPattern = regexp.MustCompile(`(?i)^([A-Za-z_][A-Za-z0-9_]*)(?:\s+(ASC|DESC))?$`)
+
+// TopNPerGroup keeps only the first n rows of every partitionBy group, ranked by orderBy.
+// 当前查询（Select、Join、Where、GroupBy 等）作为内层数据源，外层追加组内排名列 group_rank 并过滤 group_rank <= n；
+// partitionBy 与 orderBy 引用内层结果的列名，内层结果的列名不能重复。
+// 调用前设置的 OrderBy、Limit、Offset 作用于外层结果，之后的 Where、OrderBy 等同样作用于外层（可引用 group_rank）。
+// MySQL 8+、MariaDB 10.2+、PostgreSQL、SQL Server、Oracle 和 SQLite 3.25+ 使用 ROW_NUMBER() 窗口函数；
+// 更早的 MySQL / SQLite 使用相关子查询计算排名，此时 orderBy 只能是 "列名 [ASC|DESC]" 的组合，
+// 排序值相同的行排名相同，需要严格 n 行时请在 orderBy 末尾加上唯一列
+func (qb *QueryBuilder) TopNPerGroup(partitionBy []string, orderBy string, n int) *QueryBuilder {
+	if qb.lastErr != nil {
+		return qb
+	}
+	if len(partitionBy) == 0 {
+		qb.lastErr = fmt.Errorf("dbkit: TopNPerGroup requires at least one partition column")
+		return qb
+	}
+	for _, col := range partitionBy {
+		if !selectBarePattern.MatchString(col) {
+			qb.lastErr = fmt.Errorf("dbkit: invalid TopNPerGroup partition column %q", col)
+			return qb
+		}
+	}
+	orderBy = strings.TrimSpace(orderBy)
+	if orderBy == "" {
+		qb.lastErr = fmt.Errorf("dbkit: TopNPerGroup requires an order")
+		return qb
+	}
+	if n <= 0 {
+		qb.lastErr = fmt.Errorf("dbkit: TopNPerGroup n must be greater than 0")
+		return qb
+	}
+	mgr := qb.getDbManager()
+	if mgr == nil {
+		qb.lastErr = fmt.Errorf("dbkit: database not initialized")
+		return qb
+	}
+
+	scoped, err := qb.tenantScoped()
+	if err != nil {
+		qb.lastErr = err
+		return qb
+	}
+	inner := *scoped
+	inner.orderBy = ""
+	inner.limit = 0
+	inner.offset = 0
+	innerSQL, innerArgs := inner.buildSelectSql()
+
+	var rankedSQL string
+	var rankedArgs []interface{}
+	if mgr.supportsWindowFunctions() {
+		rankedSQL = fmt.Sprintf("SELECT q.*, ROW_NUMBER() OVER (PARTITION BY %s ORDER BY %s) AS %s FROM (%s) q",
+			strings.Join(partitionBy, ", "), orderBy, topNRankColumn, innerSQL)
+		rankedArgs = innerArgs
+	} else {
+		ahead, err := topNAheadCondition(orderBy)
+		if err != nil {
+			qb.lastErr = err
+			return qb
+		}
+		sameGroup := make([]string, len(partitionBy))
+		for i, col := range partitionBy {
+			if mgr.config.Driver == MySQL {
+				sameGroup[i] = fmt.Sprintf("q2.%s <=> q.%s", col, col)
+			} else {
+				sameGroup[i] = fmt.Sprintf("q2.%s IS q.%s", col, col)
+			}
+		}
+		rankedSQL = fmt.Sprintf("SELECT q.*, (SELECT COUNT(*) FROM (%s) q2 WHERE %s AND (%s)) + 1 AS %s FROM (%s) q",
+			innerSQL, strings.Join(sameGroup, " AND "), ahead, topNRankColumn, innerSQL)
+		rankedArgs = append(append([]interface{}(nil), innerArgs...), innerArgs...)
+	}
+
+	*qb = QueryBuilder{
+		db:                  qb.db,
+		tx:                  qb.tx,
+		selectSql:           "*",
+		orderBy:             qb.orderBy,
+		limit:               qb.limit,
+		offset:              qb.offset,
+		cacheRepositoryName: qb.cacheRepositoryName,
+		cacheTTL:            qb.cacheTTL,
+		cacheProvider:       qb.cacheProvider,
+		timeout:             qb.timeout,
+		countCacheTTL:       qb.countCacheTTL,
+		with:                qb.with,
+	}
+	qb.setRawSQL(rankedSQL, rankedArgs)
+	return qb.Where(topNRankColumn+" <= ?", n)
+}
+
+// topNAheadCondition 把 "a DESC, b" 转换为 q2 行排在 q 行之前的条件
+func topNAheadCondition(orderBy string) (string, error) {
+	var equal []string
+	var ahead []string
+	for _, term := range splitSelectList(orderBy) {
+		m := topNOrderTermPattern.FindStringSubmatch(strings.TrimSpace(term))
+		if m == nil {
+			return "", fmt.Errorf("dbkit: TopNPerGroup order %q must be plain columns on this database version", orderBy)
+		}
+		op := "<"
+		if strings.EqualFold(m[2], "DESC") {
+			op = ">"
+		}
+		cond := append(append([]string(nil), equal...), fmt.Sprintf("q2.%s %s q.%s", m[1], op, m[1]))
+		ahead = append(ahead, "("+strings.Join(cond, " AND ")+")")
+		equal = append(equal, fmt.Sprintf("q2.%s = q.%s", m[1], m[1]))
+	}
+	return strings.Join(ahead, " OR "), nil
+}
+
+// supportsWindowFunctions 返回数据库是否支持窗口函数，只读取连接时的检测结果，未能检测时按支持处理
+func (mgr *dbManager) supportsWindowFunctions() bool {
+	mgr.mu.RLock()
+	defer mgr.mu.RUnlock()
+	return mgr.windowFunctions == nil || *mgr.windowFunctions
+}
+
+// detectWindowFunctions 在新建的连接池上检测是否支持窗口函数（MySQL 8+ / MariaDB 10.2+ / SQLite 3.25+），
+// 其他数据库及无法检测时返回 nil
+func detectWindowFunctions(driver DriverType, db *sql.DB) *bool {
+	versionSQL := "SELECT VERSION()"
+	switch driver {
+	case MySQL:
+	case SQLite3:
+		versionSQL = "SELECT sqlite_version()"
+	default:
+		return nil
+	}
+	var version string
+	if db.QueryRow(versionSQL).Scan(&version) != nil {
+		return nil
+	}
+	major, minor := parseMajorMinor(version)
+	var supported bool
+	switch {
+	case driver == SQLite3:
+		supported = major > 3 || (major == 3 && minor >= 25)
+	case strings.Contains(strings.ToLower(version), "mariadb"):
+		supported = major > 10 || (major == 10 && minor >= 2)
+	default:
+		supported = major >= 8
+	}
+	return &supported
+}
+
+// parseMajorMinor 解析 "8.0.34-log" 形式版本号的主、次版本
+func parseMajorMinor(version string) (int, int) {
+	parts := strings.SplitN(version, ".", 3)
+	major, _ := strconv.Atoi(strings.TrimSpace(parts[0]))
+	minor := 0
+	if len(parts) > 1 {
+		digits := parts[1]
+		if i := strings.IndexFunc(digits, func(r rune) bool { return r < '0' || r > '9' }); i >= 0 {
+			digits = digits[:i]
+		}
+		minor, _ = strconv.Atoi(digits)
+	}
+	return major, minor
+}
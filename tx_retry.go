@@ -0,0 +1,72 @@
+package dbkit
+
+import (
+	"errors"
+	"math/rand"
+	"time"
+)
+
+const (
+	txRetryBaseDelay = 20 * time.Millisecond // 首次重试前的等待时间
+	txRetryMaxDelay  = time.Second           // 单次等待上限
+)
+
+// TransactionWithRetry runs fn in a transaction on the default database and re-runs the whole
+// transaction when it fails with a deadlock or serialization failure, up to maxAttempts times.
+// fn 可能被执行多次，必须是幂等的：不要在闭包内修改外部状态、发送消息或调用外部服务，
+// 所有副作用都应只通过 tx 完成，或放到事务成功提交之后
+func TransactionWithRetry(maxAttempts int, fn func(*Tx) error) error {
+	db, err := defaultDB()
+	if err != nil {
+		return err
+	}
+	return db.TransactionWithRetry(maxAttempts, fn)
+}
+
+// TransactionWithRetry runs fn in a transaction, retrying with exponential backoff on
+// deadlocks and serialization failures (see IsRetryableTxError). maxAttempts <= 1 means no retry.
+func (db *DB) TransactionWithRetry(maxAttempts int, fn func(*Tx) error) error {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	var err error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		err = db.Transaction(fn)
+		if err == nil || !IsRetryableTxError(err) {
+			return err
+		}
+		if attempt == maxAttempts {
+			break
+		}
+		LogWarn("事务因死锁或序列化冲突回滚，准备重试", map[string]interface{}{
+			"attempt": attempt,
+			"error":   err.Error(),
+		})
+		time.Sleep(txRetryDelay(attempt))
+	}
+	return err
+}
+
+// txRetryDelay 指数退避并加入随机抖动，避免冲突的事务同时重试再次冲突
+func txRetryDelay(attempt int) time.Duration {
+	delay := txRetryBaseDelay << (attempt - 1)
+	if delay <= 0 || delay > txRetryMaxDelay {
+		delay = txRetryMaxDelay
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// IsRetryableTxError reports whether err is a deadlock or serialization failure
+// (MySQL 1213, PostgreSQL 40P01 / 40001, SQL Server 1205 / 3960, Oracle ORA-00060 / ORA-08177)
+// after which the transaction can be retried
+func IsRetryableTxError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var de *DBError
+	if !errors.As(err, &de) {
+		// 未经 dbkit 执行的错误（如事务提交失败）按错误类型推断数据库
+		de = newDBError("", err)
+	}
+	return de != nil && (de.IsDeadlock() || de.IsSerializationFailure())
+}
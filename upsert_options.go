@@ -0,0 +1,33 @@
+package dbkit
+
+import "strings"
+
+// UpsertOptions holds optional settings for Save and BatchUpsertReturning
+type UpsertOptions struct {
+	// ConflictTarget 原样作为 PostgreSQL / SQLite 的冲突目标，用于部分唯一索引，
+	// 如 "(email) WHERE deleted_at IS NULL"，可带 "ON CONFLICT" 前缀；不支持 ? 参数，不要拼接外部输入。
+	// 为空时 Save 使用 (主键)，BatchUpsertReturning 使用 (conflictColumns)；
+	// BatchUpsertReturning 的 conflictColumns 仍需传入，用于校验记录与匹配返回行
+	ConflictTarget string
+}
+
+// conflictTarget 返回 ON CONFLICT 之后的冲突目标
+func (o UpsertOptions) conflictTarget(conflictColumns []string) string {
+	target := strings.TrimSpace(o.ConflictTarget)
+	if target == "" {
+		return "(" + joinStrings(conflictColumns) + ")"
+	}
+	const prefix = "ON CONFLICT"
+	if len(target) >= len(prefix) && strings.EqualFold(target[:len(prefix)], prefix) {
+		target = strings.TrimSpace(target[len(prefix):])
+	}
+	return target
+}
+
+// upsertOptions 返回可选参数中的第一个，未传入时返回零值
+func upsertOptions(opts []UpsertOptions) UpsertOptions {
+	if len(opts) == 0 {
+		return UpsertOptions{}
+	}
+	return opts[0]
+}
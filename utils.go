@@ -46,3 +46,17 @@ func isNil(i interface{}) bool {
 	}
 	return false
 }
+
+// isEmptyValue reports whether a value should be treated as "not provided":
+// nil, typed nil, empty string, zero numbers, false, and empty slices/maps/arrays.
+func isEmptyValue(i interface{}) bool {
+	if isNil(i) {
+		return true
+	}
+	v := reflect.ValueOf(i)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Array, reflect.String:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
@@ -3,6 +3,7 @@ package dbkit
 import (
 	"fmt"
 	"regexp"
+	"strings"
 )
 
 // Pre-compiled regular expressions for better performance
@@ -11,6 +12,9 @@ var (
 	// Supported formats: table_name, schema.table_name
 	// Rules: starts with letter or underscore, followed by letters/digits/underscores
 	identifierPattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*(\.[a-zA-Z_][a-zA-Z0-9_]*)?$`)
+
+	// collationPattern matches collation names such as utf8mb4_zh_0900_as_cs, zh-x-icu, Chinese_PRC_CI_AS
+	collationPattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_\-.@]*$`)
 )
 
 const (
@@ -18,6 +22,217 @@ const (
 	maxIdentifierLength = 128
 )
 
+// 各方言单条语句允许的最大绑定参数数量（取官方文档的上限）
+var driverParamLimits = map[DriverType]int{
+	MySQL:      65535,
+	PostgreSQL: 65535,
+	SQLite3:    32766, // SQLite 3.32.0+ 的 SQLITE_MAX_VARIABLE_NUMBER 默认值
+	Oracle:     65535,
+	SQLServer:  2100,
+}
+
+// 各方言的显示名称，用于错误提示
+var driverDisplayNames = map[DriverType]string{
+	MySQL:      "MySQL",
+	PostgreSQL: "PostgreSQL",
+	SQLite3:    "SQLite",
+	Oracle:     "Oracle",
+	SQLServer:  "SQL Server",
+}
+
+// ParamLimitError 表示语句的绑定参数数量超过了数据库方言的上限
+// 调用方可以通过 errors.As 检测该错误并自行分批重试
+type ParamLimitError struct {
+	Driver DriverType
+	Count  int
+	Limit  int
+}
+
+func (e *ParamLimitError) Error() string {
+	name := driverDisplayNames[e.Driver]
+	if name == "" {
+		name = string(e.Driver)
+	}
+	return fmt.Sprintf("dbkit: query has %d parameters, exceeds %s limit of %d; reduce batch size", e.Count, name, e.Limit)
+}
+
+// paramLimit 返回当前数据库的参数上限，0 表示不检查
+// Config.MaxParams > 0 时覆盖方言默认值，< 0 时关闭检查
+func (mgr *dbManager) paramLimit() int {
+	if mgr.config == nil {
+		return 0
+	}
+	if mgr.config.MaxParams != 0 {
+		if mgr.config.MaxParams < 0 {
+			return 0
+		}
+		return mgr.config.MaxParams
+	}
+	return driverParamLimits[mgr.config.Driver]
+}
+
+// checkParamLimit 在执行前检查绑定参数数量，避免驱动返回难以理解的错误
+func (mgr *dbManager) checkParamLimit(count int) error {
+	limit := mgr.paramLimit()
+	if limit > 0 && count > limit {
+		return &ParamLimitError{Driver: mgr.config.Driver, Count: count, Limit: limit}
+	}
+	return nil
+}
+
+// PlaceholderPosition 记录 SQL 中一个占位符的样式及其字节偏移
+type PlaceholderPosition struct {
+	Style  string // "?", "$n", "@pn", ":n"
+	Text   string
+	Offset int
+}
+
+// PlaceholderError 表示 SQL 中混用了多种占位符样式（如 ? 与 $1）
+// dbkit 只会改写 ?，混用时改写结果必然错误，因此在发送给驱动前直接报错
+type PlaceholderError struct {
+	SQL    string
+	Tokens []PlaceholderPosition
+}
+
+func (e *PlaceholderError) Error() string {
+	parts := make([]string, len(e.Tokens))
+	for i, t := range e.Tokens {
+		parts[i] = fmt.Sprintf("'%s' at offset %d", t.Text, t.Offset)
+	}
+	return fmt.Sprintf("dbkit: mixed placeholder styles in query (%s); use '?' only, dbkit converts it for the target database: %s",
+		strings.Join(parts, ", "), e.SQL)
+}
+
+// checkPlaceholderStyle 检测用户 SQL 中是否混用 ? 与驱动原生占位符（$1 / @p1 / :1）
+// 忽略字符串、引号标识符和注释中的内容；只使用一种样式时不报错
+func checkPlaceholderStyle(querySQL string, driver DriverType) error {
+	if !strings.ContainsAny(querySQL, "$@:") {
+		return nil
+	}
+
+	tokens := scanPlaceholders(querySQL, driver)
+	styles := make(map[string]bool)
+	for _, t := range tokens {
+		styles[t.Style] = true
+	}
+	if len(styles) <= 1 {
+		return nil
+	}
+
+	// 每种样式只报告第一次出现的位置，避免错误信息过长
+	var report []PlaceholderPosition
+	seen := make(map[string]bool)
+	for _, t := range tokens {
+		if !seen[t.Style] {
+			seen[t.Style] = true
+			report = append(report, t)
+		}
+	}
+	return &PlaceholderError{SQL: querySQL, Tokens: report}
+}
+
+// scanPlaceholders 扫描 SQL 中字符串/注释之外的占位符，PostgreSQL 的 jsonb 运算符 ?、?|、?& 不计入
+func scanPlaceholders(s string, driver DriverType) []PlaceholderPosition {
+	var tokens []PlaceholderPosition
+	isIdent := func(c byte) bool {
+		return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+	}
+	digitsFrom := func(i int) int {
+		j := i
+		for j < len(s) && s[j] >= '0' && s[j] <= '9' {
+			j++
+		}
+		return j
+	}
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\':
+			i++
+		case c == '\'' || c == '"' || c == '`':
+			// 跳过字符串和引号标识符（'' 转义由下一次循环自然处理）
+			for i++; i < len(s) && s[i] != c; i++ {
+				if s[i] == '\\' {
+					i++
+				}
+			}
+		case c == '-' && i+1 < len(s) && s[i+1] == '-':
+			for i < len(s) && s[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(s) && s[i+1] == '*':
+			if end := strings.Index(s[i+2:], "*/"); end >= 0 {
+				i += end + 3
+			} else {
+				i = len(s)
+			}
+		case c == '?':
+			if driver == PostgreSQL && isJSONBOperator(s, i) {
+				if i+1 < len(s) && (s[i+1] == '|' || s[i+1] == '&') {
+					i++
+				}
+				continue
+			}
+			tokens = append(tokens, PlaceholderPosition{Style: "?", Text: "?", Offset: i})
+		case c == '$' && (i == 0 || (!isIdent(s[i-1]) && s[i-1] != '$')):
+			if j := digitsFrom(i + 1); j > i+1 {
+				tokens = append(tokens, PlaceholderPosition{Style: "$n", Text: s[i:j], Offset: i})
+				i = j - 1
+			}
+		case c == '@' && (i == 0 || (!isIdent(s[i-1]) && s[i-1] != '@')) && i+1 < len(s) && (s[i+1] == 'p' || s[i+1] == 'P'):
+			if j := digitsFrom(i + 2); j > i+2 && (j == len(s) || !isIdent(s[j])) {
+				tokens = append(tokens, PlaceholderPosition{Style: "@pn", Text: s[i:j], Offset: i})
+				i = j - 1
+			}
+		case c == ':' && (i == 0 || (!isIdent(s[i-1]) && s[i-1] != ':' && s[i-1] != ']')):
+			if j := digitsFrom(i + 1); j > i+1 {
+				tokens = append(tokens, PlaceholderPosition{Style: ":n", Text: s[i:j], Offset: i})
+				i = j - 1
+			}
+		}
+	}
+	return tokens
+}
+
+// placeholderKeywords 是可以直接出现在 ? 占位符前面的关键字，其后的 ? 不会是 jsonb 运算符
+var placeholderKeywords = map[string]bool{
+	"SELECT": true, "WHERE": true, "AND": true, "OR": true, "NOT": true, "ON": true, "IN": true, "IS": true,
+	"LIKE": true, "ILIKE": true, "TO": true, "BETWEEN": true, "CASE": true, "WHEN": true, "THEN": true, "ELSE": true,
+	"LIMIT": true, "OFFSET": true, "VALUES": true, "SET": true, "BY": true, "HAVING": true, "RETURNING": true,
+	"DISTINCT": true, "ANY": true, "ALL": true, "SOME": true, "ESCAPE": true, "FROM": true, "USING": true,
+	"INTERVAL": true, "FETCH": true, "NEXT": true, "FIRST": true, "UNION": true, "EXCEPT": true, "INTERSECT": true,
+	"DEFAULT": true, "EXISTS": true, "ARRAY": true,
+}
+
+// isJSONBOperator 判断 s[i] 处的 ? 是否为 PostgreSQL 的 jsonb 运算符（?、?|、?&）而不是占位符：
+// ?| 与 ?& 总是运算符（?|| 除外，那是占位符后接字符串拼接）；单独的 ? 前面是列名、右括号或字符串等操作数时为运算符，
+// 前面是比较符、逗号、左括号或 WHERE / AND / LIMIT 等关键字时为占位符
+func isJSONBOperator(s string, i int) bool {
+	if i+1 < len(s) && (s[i+1] == '|' || s[i+1] == '&') {
+		return i+2 >= len(s) || s[i+2] != s[i+1]
+	}
+	j := i - 1
+	for j >= 0 && (s[j] == ' ' || s[j] == '\t' || s[j] == '\n' || s[j] == '\r') {
+		j--
+	}
+	if j < 0 {
+		return false
+	}
+	switch c := s[j]; {
+	case c == ')' || c == ']' || c == '\'' || c == '"':
+		return true
+	case c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9'):
+		start := j
+		for start > 0 && (s[start-1] == '_' || s[start-1] == '.' || (s[start-1] >= 'a' && s[start-1] <= 'z') ||
+			(s[start-1] >= 'A' && s[start-1] <= 'Z') || (s[start-1] >= '0' && s[start-1] <= '9')) {
+			start--
+		}
+		return !placeholderKeywords[strings.ToUpper(s[start:j+1])]
+	}
+	return false
+}
+
 // ErrInvalidTableName represents an invalid table name error
 type ErrInvalidTableName struct {
 	Name   string
@@ -0,0 +1,80 @@
+package dbkit
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+)
+
+// WarmUp opens and pings n connections on the named database so that they sit in the idle pool
+// and the first queries do not pay the cost of establishing a connection.
+// n 超过 MaxIdle（或 MaxOpen）时按上限截断，多出的连接归还时会被连接池直接关闭
+func WarmUp(dbName string, n int) error {
+	mgr := GetDatabase(dbName)
+	if mgr == nil {
+		return fmt.Errorf("dbkit: database '%s' not found", dbName)
+	}
+	db, err := mgr.getDB()
+	if err != nil {
+		return err
+	}
+	return mgr.warmUpPool(db, n)
+}
+
+// WarmUp opens and pings n connections for this database instance
+func (db *DB) WarmUp(n int) error {
+	if db.lastErr != nil {
+		return db.lastErr
+	}
+	sdb, err := db.dbMgr.getDB()
+	if err != nil {
+		return err
+	}
+	return db.dbMgr.warmUpPool(sdb, n)
+}
+
+// warmUpPool 同时占用 n 个连接并逐个 Ping，全部完成后一起归还，使连接池中保留 n 个空闲连接
+func (mgr *dbManager) warmUpPool(db *sql.DB, n int) error {
+	if n > mgr.config.MaxIdle {
+		n = mgr.config.MaxIdle
+	}
+	if mgr.config.MaxOpen > 0 && n > mgr.config.MaxOpen {
+		n = mgr.config.MaxOpen
+	}
+	if n <= 0 {
+		return nil
+	}
+
+	ctx := context.Background()
+	conns := make([]*sql.Conn, n)
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			conn, err := db.Conn(ctx)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			conns[i] = conn
+			errs[i] = conn.PingContext(ctx)
+		}(i)
+	}
+	wg.Wait()
+
+	// 所有连接都建立后再归还，否则同一个连接会被反复取出，池中仍只有一个空闲连接
+	for _, conn := range conns {
+		if conn != nil {
+			conn.Close()
+		}
+	}
+	for _, err := range errs {
+		if err != nil {
+			return fmt.Errorf("dbkit: warm up database '%s' failed: %v", mgr.name, err)
+		}
+	}
+	return nil
+}